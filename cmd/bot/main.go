@@ -9,7 +9,6 @@ import (
 	"os/signal"
 	"runtime"
 	"syscall"
-	"time"
 
 	"support_bot/internal/app"
 	"support_bot/internal/config"
@@ -72,9 +71,9 @@ func main() {
 	signal.Notify(stop, syscall.SIGTERM, syscall.SIGINT)
 
 	<-stop
-	log.Info("receive stop signal", slog.Any("finish time", 10*time.Second))
+	log.Info("receive stop signal", slog.Any("finish time", cfg.Timeout.Shutdown))
 
-	sCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	sCtx, cancel := context.WithTimeout(context.Background(), cfg.Timeout.Shutdown)
 	defer cancel()
 
 	shutdownCtx := logger.AppendCtx(sCtx,
@@ -24,6 +24,7 @@ import (
 
 	"support_bot/internal/collector"
 	"support_bot/internal/collector/metabase"
+	"support_bot/internal/exporter"
 	"support_bot/internal/models"
 	"support_bot/internal/pkg/text"
 
@@ -265,6 +266,11 @@ type handler struct {
 	data map[string][]map[string]any
 }
 
+// templateData возвращает данные в том же виде, в каком их получают шаблоны в боте.
+func (h *handler) templateData() map[string]any {
+	return exporter.TemplateData(h.data, models.RunInfo{ScheduledAt: time.Now()})
+}
+
 func (h *handler) handleHTML(w http.ResponseWriter, r *http.Request) {
 	templ := r.PathValue("template")
 
@@ -283,7 +289,7 @@ func (h *handler) handleHTML(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 
-	err = t.ExecuteTemplate(w, templ, h.data)
+	err = t.ExecuteTemplate(w, templ, h.templateData())
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		fmt.Fprintf(w, "Error while execute template : %s", err.Error())
@@ -321,7 +327,7 @@ func (h *handler) handleTXT(w http.ResponseWriter, r *http.Request) {
 
 	var buf bytes.Buffer
 
-	err = t.ExecuteTemplate(&buf, templ, h.data)
+	err = t.ExecuteTemplate(&buf, templ, h.templateData())
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		fmt.Fprintf(w, "Error while execute template : %s", err.Error())
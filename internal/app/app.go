@@ -8,11 +8,13 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
 	"support_bot/internal/collector"
 	"support_bot/internal/collector/metabase"
 	"support_bot/internal/config"
+	"support_bot/internal/control"
 	"support_bot/internal/delivery/smb"
 	"support_bot/internal/delivery/smtp"
 	"support_bot/internal/delivery/telegram"
@@ -23,30 +25,45 @@ import (
 	"support_bot/internal/orchestrator"
 	"support_bot/internal/pkg/logger"
 	"support_bot/internal/postgres"
+	"support_bot/internal/queue"
 	"support_bot/internal/sheduler"
 	bot "support_bot/internal/tg_bot"
 	"support_bot/internal/tg_bot/handlers"
 	"support_bot/internal/tg_bot/middlewares"
 	"support_bot/internal/tg_bot/repository"
 	"support_bot/internal/tg_bot/service"
+	"support_bot/internal/tg_bot/state"
+	"support_bot/internal/trigger"
 
 	"golang.org/x/net/proxy"
 	"gopkg.in/telebot.v4"
 )
 
-const (
-	parallel          uint8 = 30
-	channelBufferSize uint8 = 15
-)
+// persistTimeout — время на сохранение необработанных событий при остановке.
+// Отсчитывается отдельно: к этому моменту таймаут остановки может уже истечь.
+const persistTimeout = 5 * time.Second
+
+// replayInterval — как часто лидер проверяет события, сохраненные
+// остановившимися экземплярами.
+const replayInterval = time.Minute
 
 type app struct {
 	ctx    context.Context
 	cancel context.CancelFunc
+	// intake — контекст источников новых событий: планировщика, Telegram, API.
+	// Отменяется первым при остановке, пайплайн при этом дорабатывает.
+	intake     context.Context
+	stopIntake context.CancelFunc
+
+	log      *slog.Logger
+	storage  *postgres.DB
+	pgNotify *postgres.Listener
+	elector  *postgres.Elector
+	cfg      *config.Config
+	report   *reportApp
 
-	log     *slog.Logger
-	storage *postgres.DB
-	cfg     *config.Config
-	report  *reportApp
+	// leading закрывается, когда экземпляр окончательно перестает претендовать на лидерство.
+	leading chan struct{}
 
 	tgBot *telegramBot
 	smb   *smb.SMB
@@ -55,28 +72,47 @@ type app struct {
 type reportApp struct {
 	ScheduleC    chan models.Event
 	EventC       chan models.Event
+	SpecialC     chan models.SpecialEventForLK
+	DeleteC      chan models.Event
+	Pending      *eventcreator.PendingRepository
 	Scheduler    *sheduler.Sheduler
 	Event        *eventcreator.EventCreator
 	Orchestrator *orchestrator.Orchestrator
 	Generator    *generator.Generator
 	Deleter      *generator.Deleter
+	Trigger      *trigger.Trigger
+	// Triggers и Control доставляют уведомления report_trigger и команды
+	// планировщику только на лидере.
+	Triggers *postgres.Subscription
+	Control  *postgres.Subscription
+	API      *trigger.Server
 }
 
 type telegramBot struct {
 	Bot    *telebot.Bot
 	Router *bot.Router
-	Shed   *sheduler.SheduleAPI
+
+	// webhook — обновления приходят на webhook каждого экземпляра,
+	// иначе их забирает long-polling только на лидере.
+	webhook bool
+
+	mu      sync.Mutex
+	polling bool
 }
 
 func New(ctx context.Context, cfg *config.Config) (*app, error) {
 	appCtx, cancelApp := context.WithCancel(ctx)
+	intakeCtx, stopIntake := context.WithCancel(appCtx)
 	log := slog.Default()
 
 	app := &app{
-		ctx:    appCtx,
-		cancel: cancelApp,
-		log:    log,
-		cfg:    cfg,
+		ctx:        appCtx,
+		cancel:     cancelApp,
+		intake:     intakeCtx,
+		stopIntake: stopIntake,
+		log:        log,
+		cfg:        cfg,
+		leading:    make(chan struct{}),
 	}
 
 	if err := app.init(appCtx); err != nil {
@@ -95,9 +131,55 @@ func New(ctx context.Context, cfg *config.Config) (*app, error) {
 }
 
 func (a *app) Start(_ context.Context) error {
-	a.tgBot.start()
+	a.pgNotify.Start(a.ctx)
+
+	if err := a.report.start(a.ctx); err != nil {
+		close(a.leading)
+
+		return err
+	}
+
+	if a.tgBot.webhook {
+		a.tgBot.start()
+	}
+
+	go func() {
+		defer close(a.leading)
+
+		if a.elector == nil {
+			a.lead(a.intake)
+
+			return
+		}
+
+		a.elector.Run(a.intake, a.lead)
+	}()
+
+	return nil
+}
+
+// lead запускает задачи, которые должны выполняться только на одном экземпляре,
+// и останавливает их при отмене ctx.
+func (a *app) lead(ctx context.Context) {
+	a.log.InfoContext(ctx, "starting leader duties")
+
+	if !a.tgBot.webhook {
+		a.tgBot.start()
+	}
+
+	if err := a.report.lead(ctx); err != nil {
+		a.log.ErrorContext(ctx, "unable to start leader duties", slog.Any("error", err))
+	}
 
-	return a.report.start(a.ctx)
+	go a.report.replayLoop(ctx)
+
+	<-ctx.Done()
+
+	if !a.tgBot.webhook {
+		a.tgBot.stop()
+	}
+
+	a.log.Info("leader duties stopped")
 }
 
 func (a *app) GracefulShutdown(ctx context.Context) {
@@ -113,17 +195,29 @@ func (a *app) GracefulShutdown(ctx context.Context) {
 	log.InfoContext(ctx, "successfully stop")
 }
 
+// close останавливает приложение: сначала источники событий, затем пайплайн
+// с дожиданием выполняющихся отчетов до отмены ctx, затем подключения.
 func (a *app) close(ctx context.Context) error {
-	a.cancel()
+	a.stopIntake()
+
+	if a.report != nil {
+		select {
+		case <-a.leading:
+		case <-ctx.Done():
+			a.log.WarnContext(ctx, "leader duties did not stop in time")
+		}
+	}
 
 	if a.tgBot != nil {
 		a.tgBot.stop()
 	}
 
 	if a.report != nil {
-		a.report.stop(ctx)
+		a.report.drain(ctx)
 	}
 
+	a.cancel()
+
 	var err error
 
 	if a.smb != nil {
@@ -137,37 +231,226 @@ func (a *app) close(ctx context.Context) error {
 	return err
 }
 
+// start запускает части пайплайна, которые работают на каждом экземпляре.
 func (r *reportApp) start(ctx context.Context) error {
-	err := r.Scheduler.Start(ctx)
+	err := r.Event.Start(ctx)
 	if err != nil {
 		return err
 	}
 
-	err = r.Event.Start(ctx)
+	r.Generator.Start(ctx)
+	r.Orchestrator.Start(ctx)
+
+	if r.API != nil {
+		r.API.Start()
+	}
+
+	return nil
+}
+
+// lead запускает части пайплайна, которые работают только на лидере.
+func (r *reportApp) lead(ctx context.Context) error {
+	r.Control.Resume()
+	r.Triggers.Resume()
+
+	go func() {
+		<-ctx.Done()
+		r.Control.Pause()
+		r.Triggers.Pause()
+	}()
+
+	err := r.Scheduler.Start(ctx)
 	if err != nil {
 		return err
 	}
 
-	r.Generator.Start(ctx)
+	r.Event.Dispatch(ctx)
 	r.Deleter.Start(ctx)
-	r.Orchestrator.Start(ctx)
+	r.Trigger.Listen(ctx, r.Triggers.C())
 
 	return nil
 }
 
-func (r *reportApp) stop(_ context.Context) {
+// drain останавливает пайплайн. Новые события к этому моменту уже не поступают:
+// выполняющиеся отчеты дорабатываются до отмены ctx, а события, оставшиеся
+// в каналах, сохраняются для повторной отправки при следующем запуске.
+func (r *reportApp) drain(ctx context.Context) {
 	r.Scheduler.Stop()
+
+	if r.API != nil {
+		if err := r.API.Stop(ctx); err != nil {
+			slog.WarnContext(ctx, "unable to stop api server", slog.Any("error", err))
+		}
+	}
+
+	if err := r.Generator.Drain(ctx); err != nil {
+		slog.WarnContext(ctx, "generator drain incomplete", slog.Any("error", err))
+	}
+
+	r.Orchestrator.Stop(ctx)
+
+	r.persist()
+}
+
+func (r *reportApp) persist() {
+	ctx, cancel := context.WithTimeout(context.Background(), persistTimeout)
+	defer cancel()
+
+	var pending []eventcreator.Pending
+
+	for _, ev := range drainChan(r.ScheduleC) {
+		pending = append(pending, eventcreator.Pending{Stage: eventcreator.StageSchedule, Event: ev})
+	}
+
+	for _, ev := range drainChan(r.EventC) {
+		pending = append(pending, eventcreator.Pending{Stage: eventcreator.StageReport, Event: ev})
+	}
+
+	for _, ev := range drainChan(r.SpecialC) {
+		pending = append(pending, eventcreator.Pending{Stage: eventcreator.StageSpecial, Special: ev})
+	}
+
+	for _, ev := range drainChan(r.DeleteC) {
+		pending = append(pending, eventcreator.Pending{Stage: eventcreator.StageDelete, Event: ev})
+	}
+
+	if len(pending) == 0 {
+		return
+	}
+
+	if err := r.Pending.Save(ctx, pending...); err != nil {
+		slog.ErrorContext(ctx, "unable to save pending events", slog.Any("error", err))
+
+		return
+	}
+
+	slog.InfoContext(ctx, "pending events saved", slog.Any("count", len(pending)))
+}
+
+func (r *reportApp) replayLoop(ctx context.Context) {
+	ticker := time.NewTicker(replayInterval)
+	defer ticker.Stop()
+
+	for {
+		r.replay(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// replay отправляет в пайплайн события, сохраненные при прошлой остановке.
+// Если лидерство потеряно раньше, неотправленные события сохраняются снова.
+func (r *reportApp) replay(ctx context.Context) {
+	events, err := r.Pending.Take(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "unable to load pending events", slog.Any("error", err))
+
+		return
+	}
+
+	if len(events) == 0 {
+		return
+	}
+
+	slog.InfoContext(ctx, "replaying pending events", slog.Any("count", len(events)))
+
+	for i, p := range events {
+		var sent bool
+
+		switch p.Stage {
+		case eventcreator.StageSchedule:
+			sent = send(ctx, r.ScheduleC, p.Event)
+		case eventcreator.StageReport:
+			sent = send(ctx, r.EventC, p.Event)
+		case eventcreator.StageSpecial:
+			sent = send(ctx, r.SpecialC, p.Special)
+		case eventcreator.StageDelete:
+			sent = send(ctx, r.DeleteC, p.Event)
+		default:
+			slog.WarnContext(ctx, "unknown pending event stage", slog.Any("stage", p.Stage))
+
+			continue
+		}
+
+		if sent {
+			continue
+		}
+
+		saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), persistTimeout)
+		if err := r.Pending.Save(saveCtx, events[i:]...); err != nil {
+			slog.ErrorContext(saveCtx, "unable to save pending events", slog.Any("error", err))
+		}
+
+		cancel()
+
+		return
+	}
+}
+
+func send[T any](ctx context.Context, c chan<- T, v T) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case c <- v:
+		return true
+	}
+}
+
+func drainChan[T any](c <-chan T) []T {
+	var out []T
+
+	for {
+		select {
+		case v := <-c:
+			out = append(out, v)
+		default:
+			return out
+		}
+	}
 }
 
 func (b *telegramBot) start() {
-	slog.Info("starting bot polling")
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.polling {
+		return
+	}
+
+	if b.webhook {
+		slog.Info("starting bot webhook")
+	} else {
+		slog.Info("starting bot polling")
+
+		// getUpdates не работает, пока зарегистрирован webhook, например после смены bot.mode.
+		if err := b.Bot.RemoveWebhook(); err != nil {
+			slog.Error("unable to remove webhook", slog.Any("error", err))
+		}
+	}
+
+	b.polling = true
 
 	go b.Bot.Start()
 }
 
+// stop останавливает polling. Bot.Stop блокируется, если polling не запущен,
+// поэтому состояние отслеживается отдельно.
 func (b *telegramBot) stop() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.polling {
+		return
+	}
+
 	slog.Info("stop bot polling")
 
+	b.polling = false
+
 	b.Bot.Stop()
 }
 
@@ -188,24 +471,33 @@ func (a *app) init(ctx context.Context) error {
 	}
 
 	a.storage = rdb
+	a.pgNotify = postgres.NewListener(rdb.GetConn(), log)
+
+	if cfg.Leader.Enabled {
+		a.elector = postgres.NewElector(
+			rdb.GetConn(),
+			cfg.Leader.LockID,
+			cfg.Leader.RetryInterval,
+			cfg.Leader.CheckInterval,
+			log,
+		)
+	}
 
 	tgBot, err := newTelegramClient(
 		cfg.Bot.TelegramToken,
 		cfg.Bot.Proxy,
 		cfg.Bot.ApiProxy,
-		cfg.Bot.BotPoll,
+		newPoller(cfg),
 		log,
 	)
 	if err != nil {
 		return err
 	}
 
-	shdAPI := make(chan sheduler.SheduleAPIEvent, 5)
-
 	mb := metabase.New(cfg.MetabaseDomain)
-	clct := collector.NewCollector(parallel, mb, log)
+	clct := collector.NewCollector(cfg.Pipeline.CollectorParallel, mb, log)
 
-	tg := telegram.NewChatAdaptor(tgBot, log)
+	tg := telegram.NewChatAdaptor(tgBot, cfg.Bot.RateLimit, cfg.Bot.TextMaxParts, log)
 	smtpS := smtp.New(cfg.SMTP, log)
 
 	var smbS *smb.SMB
@@ -221,18 +513,40 @@ func (a *app) init(ctx context.Context) error {
 
 	a.smb = smbS
 
+	channelBufferSize := cfg.Pipeline.ChannelBuffer
+
 	sheduleEvents := make(chan models.Event, channelBufferSize)
 	eventChan := make(chan models.Event, channelBufferSize)
 	delChan := make(chan models.Event, channelBufferSize)
-	reportChan := make(chan models.Report, channelBufferSize)
 	specialEventChan := make(chan models.SpecialEventForLK, channelBufferSize)
 
+	controls := control.NewRepository(rdb.GetConn(), log)
+
 	shdLoader := sheduler.NewSheduleRepo(rdb.GetConn(), log)
-	shd := sheduler.NewSheduler(shdLoader, log, sheduleEvents, shdAPI)
+	shdControl := a.pgNotify.Subscribe(sheduler.ControlChannel)
+	shd := sheduler.NewSheduler(
+		shdLoader,
+		shdLoader,
+		controls,
+		log,
+		sheduleEvents,
+		shdControl.C(),
+		a.pgNotify.Listen(postgres.ChannelCrons),
+		cfg.Schedule.PollInterval,
+		cfg.Schedule.CatchUpWindow,
+	)
 
 	evRepository := eventcreator.NewRepository(rdb.GetConn(), log)
-	evC := eventcreator.New(sheduleEvents, eventChan, log, evRepository)
+	evC := eventcreator.New(
+		sheduleEvents,
+		eventChan,
+		log,
+		evRepository,
+		a.pgNotify.Listen(postgres.ChannelReports),
+		cfg.Pipeline.EventTimeout,
+	)
 	evAPI := eventcreator.NewEventAPI(eventChan, specialEventChan)
+	trg := trigger.New(evAPI, trigger.NewRepository(rdb.GetConn(), log), log)
 
 	eval, err := evaluator.NewEvaluator()
 	if err != nil {
@@ -243,41 +557,115 @@ func (a *app) init(ctx context.Context) error {
 
 	delRepo := generator.NewResultRepository(rdb.GetConn(), log)
 
-	deleter := generator.NewDeleter(delChan, tg, *delRepo, log)
-	gen := generator.New(reportChan, clct, *snd, *delRepo, eval, 4, log)
+	deleter := generator.NewDeleter(
+		delChan,
+		tg,
+		*delRepo,
+		cfg.Retention.SweepInterval,
+		cfg.Retention.GiveUpAfter,
+		log,
+	)
+	jobs := queue.New(
+		rdb.GetConn(),
+		cfg.Queue.VisibilityTimeout,
+		cfg.Queue.MaxAttempts,
+		cfg.Queue.RetryDelay,
+		cfg.Queue.Retention,
+		log,
+	)
+	gen := generator.New(
+		jobs,
+		a.pgNotify.Listen(postgres.ChannelJobs),
+		eventChan,
+		clct,
+		*snd,
+		*delRepo,
+		eval,
+		postgres.NewLocker(rdb.GetConn(), log),
+		generator.Options{
+			LightWorkers:  cfg.Pipeline.LightWorkers,
+			HeavyWorkers:  cfg.Pipeline.HeavyWorkers,
+			ReportTimeout: cfg.Pipeline.ReportTimeout,
+			PollInterval:  cfg.Queue.PollInterval,
+			Heartbeat:     cfg.Queue.VisibilityTimeout / 3,
+			Policy:        models.ConcurrencyPolicy(cfg.Concurrency.Policy),
+			BusyDelay:     cfg.Concurrency.BusyDelay,
+		},
+		log,
+	)
 
 	orchRepo := orchestrator.NewRepository(rdb.GetConn(), log)
-	orch := orchestrator.New(eventChan, specialEventChan, reportChan, delChan, orchRepo, log)
+	orch := orchestrator.New(
+		eventChan,
+		specialEventChan,
+		jobs,
+		delChan,
+		orchRepo,
+		controls,
+		a.pgNotify.Listen(postgres.ChannelReports),
+		log,
+	)
 	report := &reportApp{
 		ScheduleC:    sheduleEvents,
 		EventC:       eventChan,
+		SpecialC:     specialEventChan,
+		DeleteC:      delChan,
+		Pending:      eventcreator.NewPendingRepository(rdb.GetConn(), log),
 		Scheduler:    shd,
 		Event:        evC,
 		Orchestrator: orch,
 		Generator:    gen,
 		Deleter:      deleter,
+		Trigger:      trg,
+		Triggers:     a.pgNotify.Subscribe(postgres.ChannelTrigger),
+		Control:      shdControl,
+	}
+
+	if cfg.API.Enabled {
+		report.API = trigger.NewServer(cfg.API.Address, cfg.API.Token, trg, log)
+	}
+
+	var sessions state.Store[handlers.Session] = state.NewPostgres[handlers.Session](rdb.GetConn())
+	if cfg.Bot.StateStore == "memory" {
+		sessions = state.NewMemory[handlers.Session]()
 	}
 
-	state := handlers.NewState(cfg.Bot.CleanUpTime)
+	botState := handlers.NewState(sessions, cfg.Bot.CleanUpTime, log)
 
 	chatRepo := repository.NewChatRepository(rdb.GetConn(), log)
 	userRepo := repository.NewUserRepository(rdb.GetConn(), log)
 	reportRepo := repository.NewReportRepository(rdb.GetConn(), log)
 
 	notify := service.NewNotify(tg, userRepo, log)
+	audit := service.NewAudit(repository.NewAuditRepository(rdb.GetConn(), log), log)
 
-	chatService := service.NewChat(chatRepo, notify, log)
-	userService := service.NewUser(userRepo, log)
+	chatService := service.NewChat(chatRepo, notify, audit, log)
+	userService := service.NewUser(userRepo, audit, log)
 
-	shed := sheduler.NewSheduleAPI(shdAPI)
-	reportService := service.NewReportService(shed, evAPI, reportRepo, log)
+	shed := sheduler.NewSheduleAPI(shdLoader)
+	reportService := service.NewReportService(shed, evAPI, reportRepo, controls, audit, log)
+	reportEditor := service.NewReportEditor(
+		repository.NewReportEditorRepository(rdb.GetConn(), log),
+		eval,
+		audit,
+		log,
+	)
+	registration := service.NewRegistration(
+		repository.NewRegistrationRepository(rdb.GetConn(), log),
+		userRepo,
+		notify,
+		audit,
+		log,
+	)
 
 	adminHandler := handlers.NewAdminHandler(
 		tgBot,
 		userService,
 		chatService,
 		reportService,
-		state,
+		reportEditor,
+		registration,
+		botState,
 	)
 
 	userHandler := handlers.NewUserHandler(
@@ -285,20 +673,46 @@ func (a *app) init(ctx context.Context) error {
 		chatService,
 		userService,
 		reportService,
-		state,
+		registration,
+		botState,
 	)
 
-	textHandler := handlers.NewTextHandler(adminHandler, userHandler, state)
+	textHandler := handlers.NewTextHandler(adminHandler, userHandler, botState)
+
+	subscriptionHandler := handlers.NewSubscriptionHandler(
+		tgBot,
+		service.NewSubscription(repository.NewSubscriptionRepository(rdb.GetConn(), log), audit, log),
+	)
+
+	inviteHandler := handlers.NewInviteHandler(
+		tgBot,
+		service.NewInvite(
+			repository.NewInviteRepository(rdb.GetConn(), log),
+			cfg.Bot.InviteTTL,
+			audit,
+			log,
+		),
+		botState,
+	)
 
 	mw := middlewares.NewMw(userService)
 
-	router := bot.NewRouter(tgBot, adminHandler, userHandler, textHandler, mw)
+	router := bot.NewRouter(
+		tgBot,
+		adminHandler,
+		userHandler,
+		textHandler,
+		subscriptionHandler,
+		inviteHandler,
+		handlers.NewAuditHandler(audit),
+		mw,
+	)
 
 	router.Setup()
 	tgBotUser := &telegramBot{
-		Bot:    tgBot,
-		Router: router,
-		Shed:   shed,
+		Bot:     tgBot,
+		Router:  router,
+		webhook: cfg.Bot.Mode == "webhook",
 	}
 
 	a.report = report
@@ -350,7 +764,7 @@ func newTelegramClient(
 	token string,
 	proxy string,
 	apiProxy string,
-	poll time.Duration,
+	poller telebot.Poller,
 	log *slog.Logger,
 ) (*telebot.Bot, error) {
 	client := &http.Client{}
@@ -368,7 +782,7 @@ func newTelegramClient(
 
 	pref := telebot.Settings{
 		Token:  token,
-		Poller: &telebot.LongPoller{Timeout: poll},
+		Poller: poller,
 		Client: client,
 	}
 
@@ -387,3 +801,25 @@ func newTelegramClient(
 
 	return b, nil
 }
+
+// newPoller возвращает источник обновлений Telegram по bot.mode.
+func newPoller(cfg *config.Config) telebot.Poller {
+	if cfg.Bot.Mode != "webhook" {
+		return &telebot.LongPoller{Timeout: cfg.Bot.BotPoll}
+	}
+
+	wh := cfg.Bot.Webhook
+
+	poller := &telebot.Webhook{
+		Listen:         wh.Listen,
+		MaxConnections: wh.MaxConnections,
+		SecretToken:    wh.SecretToken,
+		Endpoint:       &telebot.WebhookEndpoint{PublicURL: wh.PublicURL},
+	}
+
+	if wh.TLSCert != "" {
+		poller.TLS = &telebot.WebhookTLS{Key: wh.TLSKey, Cert: wh.TLSCert}
+	}
+
+	return poller
+}
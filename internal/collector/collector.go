@@ -13,7 +13,7 @@ import (
 const defaultParallelCollectors = 32
 
 type DataFetcher interface {
-	Fetch(ctx context.Context, uuid string) ([]map[string]any, error)
+	Fetch(ctx context.Context, uuid string, params []models.CardParam) ([]map[string]any, error)
 }
 
 type Collector struct {
@@ -108,7 +108,7 @@ func (c *Collector) collect(
 			defer wg.Done()
 			defer func() { <-c.parallel }()
 
-			data, err := c.mb.Fetch(ctx, crd.CardUUID)
+			data, err := c.mb.Fetch(ctx, crd.CardUUID, crd.Params)
 			if err != nil {
 				c.log.ErrorContext(
 					ctx,
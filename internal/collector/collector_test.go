@@ -31,7 +31,7 @@ func TestCollect(t *testing.T) {
 
 		card := models.Card{Title: "card1", CardUUID: "uuid1"}
 
-		df.On("Fetch", ctx, "uuid1").Return([]map[string]any{
+		df.On("Fetch", ctx, "uuid1", []models.CardParam(nil)).Return([]map[string]any{
 			{"field": "value1"},
 		}, nil)
 
@@ -62,7 +62,7 @@ func TestCollect(t *testing.T) {
 
 		for _, card := range cards {
 			uuid := card.CardUUID
-			df.On("Fetch", ctx, uuid).Run(func(_ mock.Arguments) {
+			df.On("Fetch", ctx, uuid, []models.CardParam(nil)).Run(func(_ mock.Arguments) {
 				time.Sleep(1 * time.Second)
 			}).Return([]map[string]any{{"field": "value_" + uuid}}, nil)
 		}
@@ -98,11 +98,11 @@ func TestCollect(t *testing.T) {
 			{Title: "card2", CardUUID: "uuid2"},
 		}
 
-		df.On("Fetch", ctx, "uuid1").Run(func(_ mock.Arguments) {
+		df.On("Fetch", ctx, "uuid1", []models.CardParam(nil)).Run(func(_ mock.Arguments) {
 			time.Sleep(1 * time.Second)
 		}).Return([]map[string]any{{"field": "value_" + "uuid1"}}, nil)
 
-		df.On("Fetch", ctx, "uuid2").Run(func(_ mock.Arguments) {
+		df.On("Fetch", ctx, "uuid2", []models.CardParam(nil)).Run(func(_ mock.Arguments) {
 			time.Sleep(1 * time.Second)
 		}).Return(nil, errors.New("some error"))
 
@@ -138,7 +138,7 @@ func TestCollect(t *testing.T) {
 		}
 
 		for _, card := range append(cards1, cards2...) {
-			df.On("Fetch", ctx, card.CardUUID).Run(func(_ mock.Arguments) {
+			df.On("Fetch", ctx, card.CardUUID, []models.CardParam(nil)).Run(func(_ mock.Arguments) {
 				time.Sleep(200 * time.Millisecond) // симуляция долгой работы
 			}).Return([]map[string]any{{"field": "value_" + card.CardUUID}}, nil)
 		}
@@ -217,7 +217,7 @@ func TestCollect(t *testing.T) {
 
 		for _, card := range cards {
 			uuid := card.CardUUID
-			df.On("Fetch", ctx, uuid).Run(func(_ mock.Arguments) {
+			df.On("Fetch", ctx, uuid, []models.CardParam(nil)).Run(func(_ mock.Arguments) {
 				mu.Lock()
 
 				currentRunning++
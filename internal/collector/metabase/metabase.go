@@ -4,29 +4,54 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/netscrawler/metabase-public-api"
+	"support_bot/internal/models"
 )
 
 type Metabase struct {
 	client *metabase.Client
+
+	baseURL string
+	http    *http.Client
 }
 
 func New(baseURL string) *Metabase {
 	rt := newRetractileRoundTripper(http.DefaultTransport)
 	client := http.Client{Transport: rt, Timeout: 5 * time.Minute}
 
-	return &Metabase{client: metabase.NewClient(baseURL, &client)}
+	return &Metabase{
+		client:  metabase.NewClient(baseURL, &client),
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    &client,
+	}
 }
 
-func (m *Metabase) Fetch(ctx context.Context, cardUUID string) ([]map[string]any, error) {
+func (m *Metabase) Fetch(
+	ctx context.Context,
+	cardUUID string,
+	params []models.CardParam,
+) ([]map[string]any, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, fmt.Errorf("metabase query context : %w", err)
 	}
 
-	data, err := m.client.CardQuery(ctx, cardUUID, metabase.FormatJSON, nil)
+	var (
+		data []byte
+		err  error
+	)
+
+	if len(params) == 0 {
+		data, err = m.client.CardQuery(ctx, cardUUID, metabase.FormatJSON, nil)
+	} else {
+		data, err = m.queryWithParams(ctx, cardUUID, params)
+	}
+
 	if err != nil {
 		return nil, fmt.Errorf("metabase card query : %w", err)
 	}
@@ -40,3 +65,45 @@ func (m *Metabase) Fetch(ctx context.Context, cardUUID string) ([]map[string]any
 
 	return result, nil
 }
+
+// queryWithParams выполняет запрос публичной карточки с параметрами:
+// GET /api/public/card/:uuid/query/json?parameters=[...].
+func (m *Metabase) queryWithParams(
+	ctx context.Context,
+	cardUUID string,
+	params []models.CardParam,
+) ([]byte, error) {
+	p, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("marshal parameters: %w", err)
+	}
+
+	u := fmt.Sprintf(
+		"%s/api/public/card/%s/query/json?parameters=%s",
+		m.baseURL,
+		url.PathEscape(cardUUID),
+		url.QueryEscape(string(p)),
+	)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := m.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, body)
+	}
+
+	return body, nil
+}
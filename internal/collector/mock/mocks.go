@@ -8,6 +8,7 @@ import (
 	"context"
 
 	mock "github.com/stretchr/testify/mock"
+	"support_bot/internal/models"
 )
 
 // NewMockDataFetcher creates a new instance of MockDataFetcher. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
@@ -38,8 +39,8 @@ func (_m *MockDataFetcher) EXPECT() *MockDataFetcher_Expecter {
 }
 
 // Fetch provides a mock function for the type MockDataFetcher
-func (_mock *MockDataFetcher) Fetch(ctx context.Context, uuid string) ([]map[string]any, error) {
-	ret := _mock.Called(ctx, uuid)
+func (_mock *MockDataFetcher) Fetch(ctx context.Context, uuid string, params []models.CardParam) ([]map[string]any, error) {
+	ret := _mock.Called(ctx, uuid, params)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Fetch")
@@ -47,18 +48,18 @@ func (_mock *MockDataFetcher) Fetch(ctx context.Context, uuid string) ([]map[str
 
 	var r0 []map[string]any
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) ([]map[string]any, error)); ok {
-		return returnFunc(ctx, uuid)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, []models.CardParam) ([]map[string]any, error)); ok {
+		return returnFunc(ctx, uuid, params)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) []map[string]any); ok {
-		r0 = returnFunc(ctx, uuid)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, []models.CardParam) []map[string]any); ok {
+		r0 = returnFunc(ctx, uuid, params)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]map[string]any)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = returnFunc(ctx, uuid)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, []models.CardParam) error); ok {
+		r1 = returnFunc(ctx, uuid, params)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -73,11 +74,12 @@ type MockDataFetcher_Fetch_Call struct {
 // Fetch is a helper method to define mock.On call
 //   - ctx context.Context
 //   - uuid string
-func (_e *MockDataFetcher_Expecter) Fetch(ctx interface{}, uuid interface{}) *MockDataFetcher_Fetch_Call {
-	return &MockDataFetcher_Fetch_Call{Call: _e.mock.On("Fetch", ctx, uuid)}
+//   - params []models.CardParam
+func (_e *MockDataFetcher_Expecter) Fetch(ctx interface{}, uuid interface{}, params interface{}) *MockDataFetcher_Fetch_Call {
+	return &MockDataFetcher_Fetch_Call{Call: _e.mock.On("Fetch", ctx, uuid, params)}
 }
 
-func (_c *MockDataFetcher_Fetch_Call) Run(run func(ctx context.Context, uuid string)) *MockDataFetcher_Fetch_Call {
+func (_c *MockDataFetcher_Fetch_Call) Run(run func(ctx context.Context, uuid string, params []models.CardParam)) *MockDataFetcher_Fetch_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -87,9 +89,14 @@ func (_c *MockDataFetcher_Fetch_Call) Run(run func(ctx context.Context, uuid str
 		if args[1] != nil {
 			arg1 = args[1].(string)
 		}
+		var arg2 []models.CardParam
+		if args[2] != nil {
+			arg2 = args[2].([]models.CardParam)
+		}
 		run(
 			arg0,
 			arg1,
+			arg2,
 		)
 	})
 	return _c
@@ -100,7 +107,7 @@ func (_c *MockDataFetcher_Fetch_Call) Return(stringToVs []map[string]any, err er
 	return _c
 }
 
-func (_c *MockDataFetcher_Fetch_Call) RunAndReturn(run func(ctx context.Context, uuid string) ([]map[string]any, error)) *MockDataFetcher_Fetch_Call {
+func (_c *MockDataFetcher_Fetch_Call) RunAndReturn(run func(ctx context.Context, uuid string, params []models.CardParam) ([]map[string]any, error)) *MockDataFetcher_Fetch_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -3,11 +3,15 @@ package config
 import (
 	"fmt"
 	"log/slog"
+	"net/url"
 	"os"
+	"regexp"
 	"time"
 
 	"support_bot/internal/delivery/smb"
 	"support_bot/internal/delivery/smtp"
+	"support_bot/internal/delivery/telegram"
+	"support_bot/internal/models"
 	"support_bot/internal/pkg/logger"
 	"support_bot/internal/postgres"
 
@@ -21,22 +25,89 @@ type Config struct {
 	Database       postgres.Config  `yaml:"database"        comment:"Настройки подключения к Postgres"`
 	Bot            bot              `yaml:"bot"             comment:"\nНастройки Telegram-бота.\nИспользуется для приема команд и отправки уведомлений."`
 	Timeout        timeout          `yaml:"timeout"         comment:"Настройка таймаутов"`
+	Schedule       schedule         `yaml:"schedule"        comment:"Настройки планировщика рассылок"`
+	Leader         leader           `yaml:"leader"          comment:"Выбор лидера при запуске нескольких экземпляров бота.\nТолько лидер запускает планировщик, удаление сообщений и long-polling Telegram."`
+	Pipeline       pipeline         `yaml:"pipeline"        comment:"Производительность пайплайна генерации отчетов"`
+	Queue          queue            `yaml:"queue"           comment:"Очередь задач на генерацию отчетов в PostgreSQL"`
+	Concurrency    concurrency      `yaml:"concurrency"     comment:"Одновременные запуски одного отчета"`
+	Retention      retention        `yaml:"retention"       comment:"Удаление отправленных сообщений Telegram по политикам хранения получателей"`
+	API            api              `yaml:"api"             comment:"HTTP API для запуска отчетов внешними системами"`
 	SMB            smb.Config       `yaml:"smb"             comment:"Настройки подключения к SMB (Samba) файловой шаре.\nИспользуется для чтения и/или записи файлов на сетевой ресурс.\nПоддерживается аутентификация по логину/паролю."`
 	SMTP           smtp.Config      `yaml:"smtp"            comment:"Настройки SMTP-сервера.\nИспользуется для отправки email-уведомлений и отчетов.\nПоддерживается аутентификация по логину и паролю."`
 }
 
 type bot struct {
-	TelegramToken string        `env:"TELEGRAM_TOKEN"            yaml:"telegram_token" comment:"Телеграмм токен бота полученый от @BotFather\nОбязателен для запуска бота."`
-	CleanUpTime   time.Duration `env:"TELEGRAM_CLEAN_UP_TIME"    yaml:"clean_up_time"  comment:"CleanUpTime — интервал очистки временных данных бота\n(кэш, состояния диалогов, временные сообщения и т.п.)." env-default:"10m"`
-	BotPoll       time.Duration `env:"TELEGRAM_BOT_POLL_TIMEOUT" yaml:"bot_poll"       comment:"BotPoll — интервал long-polling запросов к Telegram API."                                                     env-default:"30s"`
-	Proxy         string        `env:"PROXY"                     yaml:"proxy"`
-	ApiProxy      string        `                                yaml:"api_proxy"                                                                                                                                               end:"API_PROXY"`
+	TelegramToken string             `env:"TELEGRAM_TOKEN"            yaml:"telegram_token" comment:"Телеграмм токен бота полученый от @BotFather\nОбязателен для запуска бота."`
+	CleanUpTime   time.Duration      `env:"TELEGRAM_CLEAN_UP_TIME"    yaml:"clean_up_time"  comment:"CleanUpTime — интервал очистки временных данных бота\n(кэш, состояния диалогов, временные сообщения и т.п.)." env-default:"10m"`
+	Mode          string             `env:"TELEGRAM_MODE"             yaml:"mode"           comment:"Mode — способ получения обновлений: polling (long-polling на лидере)\nили webhook (Telegram присылает обновления на bot.webhook, каждый экземпляр принимает их)." env-default:"polling"`
+	BotPoll       time.Duration      `env:"TELEGRAM_BOT_POLL_TIMEOUT" yaml:"bot_poll"       comment:"BotPoll — интервал long-polling запросов к Telegram API."                                                     env-default:"30s"`
+	InviteTTL     time.Duration      `env:"TELEGRAM_INVITE_TTL"       yaml:"invite_ttl"     comment:"InviteTTL — срок действия ссылки-приглашения в бота."                                                         env-default:"72h"`
+	StateStore    string             `env:"TELEGRAM_STATE_STORE"      yaml:"state_store"    comment:"StateStore — где хранить состояние диалогов: postgres (переживает перезапуск,\nобщее для всех экземпляров) или memory." env-default:"postgres"`
+	TextMaxParts  int                `env:"TELEGRAM_TEXT_MAX_PARTS"   yaml:"text_max_parts" comment:"TextMaxParts — на сколько сообщений можно разбить длинный текст;\nесли частей больше, текст отправляется файлом .txt. 0 — всегда делить на сообщения." env-default:"5"`
+	Proxy         string             `env:"PROXY"                     yaml:"proxy"`
+	ApiProxy      string             `                                yaml:"api_proxy"                                                                                                                                               end:"API_PROXY"`
+	Webhook       webhook            `                                yaml:"webhook"        comment:"Webhook — настройки приема обновлений в режиме webhook."`
+	RateLimit     telegram.RateLimit `                                yaml:"rate_limit"     comment:"RateLimit — лимиты отправки сообщений в Telegram."`
+}
+
+type webhook struct {
+	Listen         string `env:"TELEGRAM_WEBHOOK_LISTEN"          env-default:":8443" yaml:"listen"          comment:"Listen — адрес, на котором бот принимает обновления от Telegram."`
+	PublicURL      string `env:"TELEGRAM_WEBHOOK_PUBLIC_URL"                          yaml:"public_url"      comment:"PublicURL — внешний https-адрес, который регистрируется в Telegram\n(например, адрес ingress). Обязателен в режиме webhook."`
+	SecretToken    string `env:"TELEGRAM_WEBHOOK_SECRET_TOKEN"                        yaml:"secret_token"    comment:"SecretToken — секрет, который Telegram передает в заголовке X-Telegram-Bot-Api-Secret-Token.\nЗапросы без него отбрасываются. 1-256 символов A-Z, a-z, 0-9, _ и -. Обязателен в режиме webhook."`
+	TLSCert        string `env:"TELEGRAM_WEBHOOK_TLS_CERT"                            yaml:"tls_cert"        comment:"TLSCert и TLSKey — сертификат и ключ, если бот сам завершает TLS.\nПусто — слушать HTTP (TLS завершается на ingress)."`
+	TLSKey         string `env:"TELEGRAM_WEBHOOK_TLS_KEY"                             yaml:"tls_key"`
+	MaxConnections int    `env:"TELEGRAM_WEBHOOK_MAX_CONNECTIONS" env-default:"40"    yaml:"max_connections" comment:"MaxConnections — сколько одновременных запросов Telegram отправляет на webhook (1-100)."`
 }
 
 type timeout struct {
 	Shutdown time.Duration `env:"SHUTDOWN_TIMEOUT" env-default:"5s" yaml:"shutdown" comment:"Shutdown — максимальное время на корректное завершение приложения.\nЗа это время должны завершиться все активные операции.\nЕсли указать слишком маленький период не все процеесы могут завершится корректно"`
 }
 
+type schedule struct {
+	PollInterval  time.Duration `env:"SCHEDULE_POLL_INTERVAL"   env-default:"5m" yaml:"poll_interval"   comment:"PollInterval — интервал перечитывания расписаний из базы на случай,\nесли уведомление LISTEN/NOTIFY об изменении не дошло.\n0 — отключить опрос."`
+	CatchUpWindow time.Duration `env:"SCHEDULE_CATCH_UP_WINDOW" env-default:"1h" yaml:"catch_up_window" comment:"CatchUpWindow — окно догоняющих запусков после простоя.\nЕсли запуск был пропущен не раньше чем CatchUpWindow назад, он выполняется при старте.\n0 — не догонять пропущенные запуски."`
+}
+
+type leader struct {
+	Enabled       bool          `env:"LEADER_ENABLED"        env-default:"false"   yaml:"enabled"        comment:"Enabled — включает выбор лидера через advisory lock PostgreSQL.\nЕсли false, экземпляр всегда считает себя лидером."`
+	LockID        int64         `env:"LEADER_LOCK_ID"        env-default:"7305451" yaml:"lock_id"        comment:"LockID — ключ advisory lock, одинаковый для всех экземпляров."`
+	RetryInterval time.Duration `env:"LEADER_RETRY_INTERVAL" env-default:"10s"     yaml:"retry_interval" comment:"RetryInterval — как часто ведомый экземпляр пытается стать лидером."`
+	CheckInterval time.Duration `env:"LEADER_CHECK_INTERVAL" env-default:"5s"      yaml:"check_interval" comment:"CheckInterval — как часто лидер проверяет, что блокировка все еще удерживается."`
+}
+
+type pipeline struct {
+	CollectorParallel uint8         `env:"PIPELINE_COLLECTOR_PARALLEL" env-default:"30"  yaml:"collector_parallel" comment:"CollectorParallel — сколько карточек Metabase загружается параллельно."`
+	ChannelBuffer     uint8         `env:"PIPELINE_CHANNEL_BUFFER"     env-default:"15"  yaml:"channel_buffer"     comment:"ChannelBuffer — размер буферов каналов событий между модулями."`
+	EventTimeout      time.Duration `env:"PIPELINE_EVENT_TIMEOUT"      env-default:"15s" yaml:"event_timeout"      comment:"EventTimeout — время на обработку события расписания EventCreator."`
+	ReportTimeout     time.Duration `env:"PIPELINE_REPORT_TIMEOUT"     env-default:"5m"  yaml:"report_timeout"     comment:"ReportTimeout — время на генерацию одного отчета.\nМожно переопределить для отчета в reports.timeout_sec."`
+	LightWorkers      uint8         `env:"PIPELINE_LIGHT_WORKERS"      env-default:"4"   yaml:"light_workers"      comment:"LightWorkers — обработчики отчетов без pdf/png (оповещения, текст, таблицы)."`
+	HeavyWorkers      uint8         `env:"PIPELINE_HEAVY_WORKERS"      env-default:"2"   yaml:"heavy_workers"      comment:"HeavyWorkers — обработчики отчетов с pdf/png экспортом."`
+}
+
+type queue struct {
+	VisibilityTimeout time.Duration `env:"QUEUE_VISIBILITY_TIMEOUT" env-default:"10m"  yaml:"visibility_timeout" comment:"VisibilityTimeout — время, на которое задача скрывается от других обработчиков.\nПока отчет генерируется, видимость продлевается; если экземпляр упал,\nзадача снова становится доступной после истечения таймаута."`
+	MaxAttempts       int           `env:"QUEUE_MAX_ATTEMPTS"       env-default:"3"    yaml:"max_attempts"       comment:"MaxAttempts — сколько раз пытаться сгенерировать отчет, прежде чем пометить задачу проваленной."`
+	RetryDelay        time.Duration `env:"QUEUE_RETRY_DELAY"        env-default:"1m"   yaml:"retry_delay"        comment:"RetryDelay — задержка перед повторной попыткой после ошибки."`
+	PollInterval      time.Duration `env:"QUEUE_POLL_INTERVAL"      env-default:"10s"  yaml:"poll_interval"      comment:"PollInterval — интервал опроса очереди на случай, если уведомление о новой задаче не дошло."`
+	Retention         time.Duration `env:"QUEUE_RETENTION"          env-default:"168h" yaml:"retention"          comment:"Retention — сколько хранить завершенные задачи.\n0 — не удалять."`
+}
+
+type concurrency struct {
+	Policy    string        `env:"CONCURRENCY_POLICY"     env-default:"skip" yaml:"policy"     comment:"Policy — что делать, если отчет уже генерируется для тех же получателей:\nskip — пропустить новый запуск, queue — дождаться текущего, cancel — отменить текущий.\nМожно переопределить для отчета в reports.concurrency_policy."`
+	BusyDelay time.Duration `env:"CONCURRENCY_BUSY_DELAY" env-default:"30s"  yaml:"busy_delay" comment:"BusyDelay — через сколько повторить запуск, ожидающий окончания текущего (policy queue)."`
+}
+
+type retention struct {
+	SweepInterval time.Duration `env:"RETENTION_SWEEP_INTERVAL" env-default:"5m"  yaml:"sweep_interval" comment:"SweepInterval — как часто удалять сообщения, срок хранения которых истек."`
+	GiveUpAfter   time.Duration `env:"RETENTION_GIVE_UP_AFTER"  env-default:"72h" yaml:"give_up_after"  comment:"GiveUpAfter — сколько с момента отправки пытаться удалить сообщение.\nTelegram не дает боту удалять сообщения старше 48 часов."`
+}
+
+type api struct {
+	Enabled bool   `env:"API_ENABLED" env-default:"false" yaml:"enabled" comment:"Enabled — включает HTTP API запуска отчетов."`
+	Address string `env:"API_ADDRESS" env-default:":8080" yaml:"address" comment:"Address — адрес, на котором слушает HTTP API."`
+	Token   string `env:"API_TOKEN"                       yaml:"token"   comment:"Token — Bearer-токен для авторизации запросов.\nОбязателен, если API включен."`
+}
+
 // Load загружает конфигурацию из файла или из переменных окружения.
 func Load() (*Config, error) {
 	var cfg Config
@@ -66,9 +137,106 @@ func Load() (*Config, error) {
 
 func (c Config) Validate() error {
 	// TODO: add full config validation.
+	if _, err := models.ParseConcurrencyPolicy(c.Concurrency.Policy); err != nil {
+		return fmt.Errorf("concurrency.policy: %w", err)
+	}
+
+	if c.Retention.SweepInterval <= 0 {
+		return fmt.Errorf("retention.sweep_interval: must be positive")
+	}
+
+	if err := c.validateConns(); err != nil {
+		return err
+	}
+
+	if c.API.Enabled && c.API.Token == "" {
+		return fmt.Errorf("api.token is required when api is enabled")
+	}
+
+	if err := c.Bot.validate(); err != nil {
+		return err
+	}
+
+	if c.Bot.StateStore != "postgres" && c.Bot.StateStore != "memory" {
+		return fmt.Errorf("bot.state_store: unknown store %q", c.Bot.StateStore)
+	}
+
 	return c.Log.Validate()
 }
 
+// connHeadroom — соединения для запросов бота, планировщика и самих обработчиков,
+// пока их соединения заняты блокировками отчетов.
+const connHeadroom = 4
+
+// validateConns проверяет, что пулу хватит соединений: каждый выполняющийся отчет
+// держит свое соединение с advisory lock, еще по одному — Listener и выбор лидера.
+func (c Config) validateConns() error {
+	if c.Database.MaxConns <= 0 {
+		return nil
+	}
+
+	need := int(c.Pipeline.LightWorkers) + int(c.Pipeline.HeavyWorkers) + 1 + connHeadroom
+	if c.Leader.Enabled {
+		need++
+	}
+
+	if c.Database.MaxConns < need {
+		return fmt.Errorf(
+			"database.max_conns: %d is too small for %d light and %d heavy workers, at least %d is required",
+			c.Database.MaxConns,
+			c.Pipeline.LightWorkers,
+			c.Pipeline.HeavyWorkers,
+			need,
+		)
+	}
+
+	return nil
+}
+
+var webhookSecret = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
+
+func (b bot) validate() error {
+	if b.TextMaxParts < 0 {
+		return fmt.Errorf("bot.text_max_parts: must not be negative")
+	}
+
+	rl := b.RateLimit
+	if rl.Global <= 0 || rl.PerChat <= 0 || rl.PerGroup <= 0 {
+		return fmt.Errorf("bot.rate_limit: global, per_chat and per_group must be positive")
+	}
+
+	if rl.MaxRetries < 0 {
+		return fmt.Errorf("bot.rate_limit.max_retries: must not be negative")
+	}
+
+	switch b.Mode {
+	case "polling":
+		return nil
+	case "webhook":
+	default:
+		return fmt.Errorf("bot.mode: unknown mode %q", b.Mode)
+	}
+
+	u, err := url.Parse(b.Webhook.PublicURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("bot.webhook.public_url: https url is required in webhook mode")
+	}
+
+	if !webhookSecret.MatchString(b.Webhook.SecretToken) {
+		return fmt.Errorf("bot.webhook.secret_token: 1-256 characters A-Z, a-z, 0-9, _ and - are required in webhook mode")
+	}
+
+	if (b.Webhook.TLSCert == "") != (b.Webhook.TLSKey == "") {
+		return fmt.Errorf("bot.webhook: tls_cert and tls_key must be set together")
+	}
+
+	if b.Webhook.MaxConnections < 1 || b.Webhook.MaxConnections > 100 {
+		return fmt.Errorf("bot.webhook.max_connections: must be between 1 and 100")
+	}
+
+	return nil
+}
+
 var Path string
 
 // Приоритет: 1) аргумент командной строки, 2) переменная окружения, 3) значение по умолчанию.
@@ -95,6 +263,8 @@ type safeConfig Config
 func (c Config) LogValue() slog.Value {
 	c.Database.Password = "***"
 	c.Bot.TelegramToken = "***"
+	c.Bot.Webhook.SecretToken = "***"
+	c.API.Token = "***"
 	c.Database.DSN = "postgres://***"
 	c.SMB.Password = "***"
 	c.SMTP.Password = "***"
@@ -5,6 +5,7 @@ import (
 
 	"support_bot/internal/delivery/smb"
 	"support_bot/internal/delivery/smtp"
+	"support_bot/internal/delivery/telegram"
 	"support_bot/internal/pkg/logger"
 	"support_bot/internal/postgres"
 )
@@ -25,7 +26,7 @@ func Default() *Config {
 			Password:        "postgres",
 			Name:            "database_name",
 			SSL:             "disable",
-			MaxConns:        10,
+			MaxConns:        20,
 			MaxIdleConns:    5,
 			MaxConnLifeTime: 30 * time.Minute,
 			MaxConnIdleTime: 2 * time.Minute,
@@ -34,11 +35,63 @@ func Default() *Config {
 		Bot: bot{
 			TelegramToken: "telegram_bot_token",
 			CleanUpTime:   10 * time.Minute,
+			Mode:          "polling",
 			BotPoll:       30 * time.Second,
+			InviteTTL:     72 * time.Hour,
+			StateStore:    "postgres",
+			TextMaxParts:  5,
+			Webhook: webhook{
+				Listen:         ":8443",
+				MaxConnections: 40,
+			},
+			RateLimit: telegram.RateLimit{
+				Global:     30,
+				PerChat:    1,
+				PerGroup:   20,
+				MaxRetries: 5,
+			},
 		},
 		Timeout: timeout{
 			Shutdown: 5 * time.Second,
 		},
+		Schedule: schedule{
+			PollInterval:  5 * time.Minute,
+			CatchUpWindow: time.Hour,
+		},
+		Leader: leader{
+			Enabled:       false,
+			LockID:        7305451,
+			RetryInterval: 10 * time.Second,
+			CheckInterval: 5 * time.Second,
+		},
+		Pipeline: pipeline{
+			CollectorParallel: 30,
+			ChannelBuffer:     15,
+			EventTimeout:      15 * time.Second,
+			ReportTimeout:     5 * time.Minute,
+			LightWorkers:      4,
+			HeavyWorkers:      2,
+		},
+		Queue: queue{
+			VisibilityTimeout: 10 * time.Minute,
+			MaxAttempts:       3,
+			RetryDelay:        time.Minute,
+			PollInterval:      10 * time.Second,
+			Retention:         7 * 24 * time.Hour,
+		},
+		Concurrency: concurrency{
+			Policy:    "skip",
+			BusyDelay: 30 * time.Second,
+		},
+		Retention: retention{
+			SweepInterval: 5 * time.Minute,
+			GiveUpAfter:   72 * time.Hour,
+		},
+		API: api{
+			Enabled: false,
+			Address: ":8080",
+			Token:   "change-me",
+		},
 		SMB: smb.Config{
 			Address:  "localhost:542",
 			User:     "user",
@@ -0,0 +1,185 @@
+// Package control хранит ручное управление запусками расписаний и отчетов:
+// паузу до даты и пропуск следующего запуска.
+package control
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"support_bot/internal/errorz"
+	"support_bot/internal/models"
+)
+
+type Repository struct {
+	db *sqlx.DB
+
+	log *slog.Logger
+}
+
+func NewRepository(db *sqlx.DB, log *slog.Logger) *Repository {
+	l := log.With(slog.Any("module", "control_repository"))
+
+	return &Repository{
+		db:  db,
+		log: l,
+	}
+}
+
+// Admit решает, выполнять ли запуск target name, запланированный на at.
+// Флаг пропуска следующего запуска сбрасывается атомарно, поэтому при
+// одновременных запусках пропускается только один из них. Запуски во время
+// паузы флаг не расходуют.
+func (r *Repository) Admit(
+	ctx context.Context,
+	target models.ControlTarget,
+	name string,
+	at time.Time,
+) (models.Admission, error) {
+	const query = `with consumed as (
+    update run_controls
+    set skip_next = false, updated_at = now()
+    where target = $1 and name = $2
+      and skip_next
+      and (paused_until is null or paused_until <= $3)
+    returning 1
+)
+select case
+    when exists(select 1 from run_controls where target = $1 and name = $2 and paused_until > $3)
+        then 'paused'
+    when exists(select 1 from consumed)
+        then 'skipped'
+    else ''
+end;`
+
+	var adm models.Admission
+
+	if err := r.db.GetContext(ctx, &adm, query, target, name, at); err != nil {
+		return models.AdmitRun, fmt.Errorf("admit run: %w", err)
+	}
+
+	return adm, nil
+}
+
+// Pause приостанавливает запуски target name до until.
+func (r *Repository) Pause(
+	ctx context.Context,
+	target models.ControlTarget,
+	name string,
+	until time.Time,
+	by string,
+) error {
+	const query = `insert into run_controls(target, name, paused_until, updated_by)
+values ($1, $2, $3, $4)
+on conflict (target, name) do update
+    set paused_until = excluded.paused_until,
+        updated_by   = excluded.updated_by,
+        updated_at   = now();`
+
+	if err := r.exists(ctx, target, name); err != nil {
+		return err
+	}
+
+	if _, err := r.db.ExecContext(ctx, query, target, name, until, by); err != nil {
+		return fmt.Errorf("pause %s %s: %w", target, name, err)
+	}
+
+	return nil
+}
+
+// SkipNext пропускает ближайший запуск target name.
+func (r *Repository) SkipNext(
+	ctx context.Context,
+	target models.ControlTarget,
+	name string,
+	by string,
+) error {
+	const query = `insert into run_controls(target, name, skip_next, updated_by)
+values ($1, $2, true, $3)
+on conflict (target, name) do update
+    set skip_next  = true,
+        updated_by = excluded.updated_by,
+        updated_at = now();`
+
+	if err := r.exists(ctx, target, name); err != nil {
+		return err
+	}
+
+	if _, err := r.db.ExecContext(ctx, query, target, name, by); err != nil {
+		return fmt.Errorf("skip next %s %s: %w", target, name, err)
+	}
+
+	return nil
+}
+
+// Resume снимает паузу и флаг пропуска.
+func (r *Repository) Resume(ctx context.Context, target models.ControlTarget, name string) error {
+	const query = `delete from run_controls where target = $1 and name = $2;`
+
+	if _, err := r.db.ExecContext(ctx, query, target, name); err != nil {
+		return fmt.Errorf("resume %s %s: %w", target, name, err)
+	}
+
+	return nil
+}
+
+// Get возвращает состояние target name. Если управление не задано, возвращает пустое состояние.
+func (r *Repository) Get(
+	ctx context.Context,
+	target models.ControlTarget,
+	name string,
+) (models.RunControl, error) {
+	const query = `select target, name, paused_until, skip_next, updated_by, updated_at
+from run_controls
+where target = $1 and name = $2;`
+
+	var c []models.RunControl
+
+	if err := r.db.SelectContext(ctx, &c, query, target, name); err != nil {
+		return models.RunControl{}, fmt.Errorf("get run control: %w", err)
+	}
+
+	if len(c) == 0 {
+		return models.RunControl{Target: target, Name: name}, nil
+	}
+
+	return c[0], nil
+}
+
+// List возвращает действующие паузы и пропуски.
+func (r *Repository) List(ctx context.Context) ([]models.RunControl, error) {
+	const query = `select target, name, paused_until, skip_next, updated_by, updated_at
+from run_controls
+where skip_next or paused_until > now()
+order by target, name;`
+
+	var c []models.RunControl
+
+	if err := r.db.SelectContext(ctx, &c, query); err != nil {
+		return nil, fmt.Errorf("list run controls: %w", err)
+	}
+
+	return c, nil
+}
+
+func (r *Repository) exists(ctx context.Context, target models.ControlTarget, name string) error {
+	const query = `select case $1
+    when 'cron' then exists(select 1 from crons where name = $2)
+    when 'report' then exists(select 1 from reports where name = $2)
+    else false
+end;`
+
+	var ok bool
+
+	if err := r.db.GetContext(ctx, &ok, query, target, name); err != nil {
+		return fmt.Errorf("check %s exists: %w", target, err)
+	}
+
+	if !ok {
+		return fmt.Errorf("%s %q: %w", target, name, errorz.ErrNotFound)
+	}
+
+	return nil
+}
@@ -0,0 +1,8 @@
+package telegram
+
+type RateLimit struct {
+	Global     float64 `env:"TELEGRAM_RATE_GLOBAL"      env-default:"30" yaml:"global"      comment:"Global — сколько сообщений в секунду бот отправляет во все чаты вместе."`
+	PerChat    float64 `env:"TELEGRAM_RATE_PER_CHAT"    env-default:"1"  yaml:"per_chat"    comment:"PerChat — сколько сообщений в секунду бот отправляет в один личный чат."`
+	PerGroup   float64 `env:"TELEGRAM_RATE_PER_GROUP"   env-default:"20" yaml:"per_group"   comment:"PerGroup — сколько сообщений в минуту бот отправляет в одну группу или канал."`
+	MaxRetries int     `env:"TELEGRAM_RATE_MAX_RETRIES" env-default:"5"  yaml:"max_retries" comment:"MaxRetries — сколько раз повторить отправку после ответа 429 (retry_after)."`
+}
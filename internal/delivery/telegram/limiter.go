@@ -0,0 +1,147 @@
+package telegram
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// idleBucketTTL — через сколько простоя забывается лимит чата.
+const idleBucketTTL = 10 * time.Minute
+
+// bucket — token bucket: rate токенов в секунду, не больше burst.
+// Токены могут уйти в минус: это очередь отправок, которые уже получили свое время.
+type bucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	// blockedUntil — до этого момента Telegram запретил отправку (retry_after).
+	blockedUntil time.Time
+}
+
+func newBucket(rate, burst float64, now time.Time) *bucket {
+	return &bucket{rate: rate, burst: burst, tokens: burst, last: now}
+}
+
+// reserve забирает n токенов и возвращает момент, когда их можно использовать.
+func (b *bucket) reserve(now time.Time, n float64) time.Time {
+	if now.After(b.last) {
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+	}
+
+	b.tokens -= n
+
+	at := now
+	if b.tokens < 0 {
+		at = now.Add(time.Duration(-b.tokens / b.rate * float64(time.Second)))
+	}
+
+	if at.Before(b.blockedUntil) {
+		at = b.blockedUntil
+	}
+
+	return at
+}
+
+// idle сообщает, что лимит чата восстановлен и его можно забыть.
+func (b *bucket) idle(now time.Time) bool {
+	return now.Sub(b.last) > idleBucketTTL && now.After(b.blockedUntil)
+}
+
+// Limiter ограничивает отправку сообщений общим лимитом бота и лимитом каждого чата.
+// Отправки, превысившие лимит, ждут своей очереди в Wait.
+type Limiter struct {
+	mu     sync.Mutex
+	global *bucket
+	chats  map[int64]*bucket
+	cfg    RateLimit
+	sweep  time.Time
+	now    func() time.Time
+}
+
+func NewLimiter(cfg RateLimit) *Limiter {
+	now := time.Now()
+
+	return &Limiter{
+		global: newBucket(cfg.Global, cfg.Global, now),
+		chats:  make(map[int64]*bucket),
+		cfg:    cfg,
+		sweep:  now,
+		now:    time.Now,
+	}
+}
+
+// chat возвращает лимит чата. Отрицательные ID — группы и каналы.
+func (l *Limiter) chat(chatID int64, now time.Time) *bucket {
+	if b, ok := l.chats[chatID]; ok {
+		return b
+	}
+
+	b := newBucket(l.cfg.PerChat, 1, now)
+	if chatID < 0 {
+		b = newBucket(l.cfg.PerGroup/60, l.cfg.PerGroup, now)
+	}
+
+	l.chats[chatID] = b
+
+	return b
+}
+
+// reserve резервирует n сообщений в чат и возвращает, когда их можно отправить.
+func (l *Limiter) reserve(chatID int64, n int) time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+
+	if now.Sub(l.sweep) > idleBucketTTL {
+		for id, b := range l.chats {
+			if b.idle(now) {
+				delete(l.chats, id)
+			}
+		}
+
+		l.sweep = now
+	}
+
+	at := l.chat(chatID, now).reserve(now, float64(n))
+
+	if g := l.global.reserve(now, float64(n)); g.After(at) {
+		at = g
+	}
+
+	return at
+}
+
+// Wait ждет, пока в чат можно отправить n сообщений.
+func (l *Limiter) Wait(ctx context.Context, chatID int64, n int) error {
+	d := time.Until(l.reserve(chatID, n))
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Block запрещает отправку в чат на d, как требует retry_after из ответа 429.
+func (l *Limiter) Block(chatID int64, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+
+	b := l.chat(chatID, now)
+	if until := now.Add(d); until.After(b.blockedUntil) {
+		b.blockedUntil = until
+	}
+}
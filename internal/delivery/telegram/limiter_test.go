@@ -0,0 +1,46 @@
+package telegram
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter_Reserve(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	l := NewLimiter(RateLimit{Global: 30, PerChat: 1, PerGroup: 20})
+	l.now = func() time.Time { return now }
+
+	// Личный чат: одно сообщение сразу, следующие — раз в секунду.
+	assert.Equal(t, now, l.reserve(1, 1))
+	assert.Equal(t, now.Add(time.Second), l.reserve(1, 1))
+	assert.Equal(t, now.Add(2*time.Second), l.reserve(1, 1))
+
+	// Другой чат не ждет первого.
+	assert.Equal(t, now, l.reserve(2, 1))
+
+	// Группа: 20 сообщений сразу, дальше по 3 секунды.
+	assert.Equal(t, now, l.reserve(-100, 20))
+	assert.Equal(t, now.Add(3*time.Second), l.reserve(-100, 1))
+
+	// retry_after переносит отправку в чат.
+	l.Block(2, 30*time.Second)
+	assert.Equal(t, now.Add(30*time.Second), l.reserve(2, 1))
+}
+
+func TestLimiter_Global(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	l := NewLimiter(RateLimit{Global: 2, PerChat: 1, PerGroup: 20})
+	l.now = func() time.Time { return now }
+
+	assert.Equal(t, now, l.reserve(1, 1))
+	assert.Equal(t, now, l.reserve(2, 1))
+	assert.Equal(t, now.Add(500*time.Millisecond), l.reserve(3, 1))
+}
@@ -0,0 +1,188 @@
+package telegram
+
+import (
+	"html"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// htmlToken — неделимая часть HTML-текста: тег, сущность (&amp;) или один символ.
+type htmlToken struct {
+	text string
+	// tag — имя тега, пусто для текста.
+	tag     string
+	closing bool
+	// size — сколько символов токен занимает в сообщении после разбора HTML.
+	size int
+}
+
+func tokenizeHTML(s string) []htmlToken {
+	tokens := make([]htmlToken, 0, len(s))
+
+	for i := 0; i < len(s); {
+		switch s[i] {
+		case '<':
+			if end := strings.IndexByte(s[i:], '>'); end > 0 {
+				raw := s[i : i+end+1]
+				name := strings.TrimPrefix(raw[1:len(raw)-1], "/")
+
+				if n := strings.IndexAny(name, " \t\n"); n >= 0 {
+					name = name[:n]
+				}
+
+				tokens = append(tokens, htmlToken{
+					text:    raw,
+					tag:     strings.ToLower(name),
+					closing: strings.HasPrefix(raw, "</"),
+				})
+				i += end + 1
+
+				continue
+			}
+		case '&':
+			if end := strings.IndexByte(s[i:], ';'); end > 1 && end <= 10 {
+				raw := s[i : i+end+1]
+				tokens = append(tokens, htmlToken{text: raw, size: utf16Len(html.UnescapeString(raw))})
+				i += end + 1
+
+				continue
+			}
+		}
+
+		_, n := utf8.DecodeRuneInString(s[i:])
+		tokens = append(tokens, htmlToken{text: s[i : i+n], size: utf16Len(s[i : i+n])})
+		i += n
+	}
+
+	return tokens
+}
+
+// utf16Len считает длину так же, как Telegram: в кодовых единицах UTF-16.
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16.RuneLen(r)
+	}
+
+	return n
+}
+
+// breakPriority возвращает приоритет разрыва после пробельного токена i:
+// 2 — конец абзаца, 1 — конец строки, 0 — пробел, -1 — здесь разрывать нельзя.
+func breakPriority(tokens []htmlToken, i int) int {
+	switch tokens[i].text {
+	case "\n":
+		if i > 0 && tokens[i-1].text == "\n" {
+			return 2
+		}
+
+		return 1
+	case " ", "\t":
+		return 0
+	default:
+		return -1
+	}
+}
+
+// cutHTML ищет конец части, начинающейся с токена start, видимая длина которой не больше limit.
+// Возвращает границу части end и индекс начала следующей next: пробел, по которому
+// прошел разрыв, в сообщения не попадает.
+func cutHTML(tokens []htmlToken, start, limit int) (end, next int) {
+	var (
+		size   int
+		breaks = [3]int{-1, -1, -1}
+		sizes  [3]int
+	)
+
+	for i := start; i < len(tokens); i++ {
+		if p := breakPriority(tokens, i); p >= 0 {
+			breaks[p], sizes[p] = i, size
+		}
+
+		if size+tokens[i].size > limit {
+			// Абзац лучше строки, строка лучше пробела, но не ценой слишком короткой части.
+			for p := 2; p >= 0; p-- {
+				if breaks[p] > start && sizes[p] >= limit/2 {
+					return breaks[p], breaks[p] + 1
+				}
+			}
+
+			best := max(breaks[0], breaks[1], breaks[2])
+			if best > start {
+				return best, best + 1
+			}
+
+			return max(i, start+1), max(i, start+1)
+		}
+
+		size += tokens[i].size
+	}
+
+	return len(tokens), len(tokens)
+}
+
+// SplitHTML делит текст с HTML-разметкой Telegram на части не длиннее limit видимых символов.
+// Разрыв ищется по абзацам, строкам и пробелам и никогда не попадает внутрь тега или сущности.
+// Теги, открытые на границе, закрываются в конце части и открываются заново в начале следующей.
+func SplitHTML(s string, limit int) []string {
+	tokens := tokenizeHTML(s)
+
+	var (
+		parts []string
+		stack []htmlToken
+	)
+
+	for start := 0; start < len(tokens); {
+		end, next := cutHTML(tokens, start, limit)
+
+		var b strings.Builder
+
+		for _, t := range stack {
+			b.WriteString(t.text)
+		}
+
+		for _, t := range tokens[start:end] {
+			b.WriteString(t.text)
+
+			switch {
+			case t.tag == "":
+			case !t.closing:
+				stack = append(stack, t)
+			default:
+				for j := len(stack) - 1; j >= 0; j-- {
+					if stack[j].tag == t.tag {
+						stack = append(stack[:j], stack[j+1:]...)
+
+						break
+					}
+				}
+			}
+		}
+
+		for j := len(stack) - 1; j >= 0; j-- {
+			b.WriteString("</" + stack[j].tag + ">")
+		}
+
+		if part := b.String(); strings.TrimSpace(plainText(part)) != "" {
+			parts = append(parts, part)
+		}
+
+		start = next
+	}
+
+	return parts
+}
+
+// plainText убирает из HTML-разметки теги и раскрывает сущности.
+func plainText(s string) string {
+	var b strings.Builder
+
+	for _, t := range tokenizeHTML(s) {
+		if t.tag == "" {
+			b.WriteString(t.text)
+		}
+	}
+
+	return html.UnescapeString(b.String())
+}
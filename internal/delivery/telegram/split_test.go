@@ -0,0 +1,87 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitHTML(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		in    string
+		limit int
+		want  []string
+	}{
+		{
+			name:  "fits",
+			in:    "<b>итог</b>: 10",
+			limit: 20,
+			want:  []string{"<b>итог</b>: 10"},
+		},
+		{
+			name:  "paragraph",
+			in:    "первый абзац\n\nвторой абзац",
+			limit: 20,
+			want:  []string{"первый абзац\n", "второй абзац"},
+		},
+		{
+			name:  "tags reopened",
+			in:    "<b>раз два три</b>",
+			limit: 8,
+			want:  []string{"<b>раз два</b>", "<b>три</b>"},
+		},
+		{
+			name:  "nested tags",
+			in:    `<a href="https://example.com"><i>один два</i></a>`,
+			limit: 5,
+			want: []string{
+				`<a href="https://example.com"><i>один</i></a>`,
+				`<a href="https://example.com"><i>два</i></a>`,
+			},
+		},
+		{
+			name:  "entity counts as one",
+			in:    "a &amp; b c",
+			limit: 5,
+			want:  []string{"a &amp; b", "c"},
+		},
+		{
+			name:  "hard cut",
+			in:    "абвгде",
+			limit: 4,
+			want:  []string{"абвг", "де"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, SplitHTML(tt.in, tt.limit))
+		})
+	}
+}
+
+func TestSplitHTML_Limit(t *testing.T) {
+	t.Parallel()
+
+	in := strings.Repeat("<b>строка</b> отчета &lt;42&gt;\n", 500)
+
+	parts := SplitHTML(in, maxMessageLength)
+	assert.Greater(t, len(parts), 1)
+
+	var joined strings.Builder
+
+	for _, p := range parts {
+		assert.LessOrEqual(t, utf16Len(plainText(p)), maxMessageLength)
+		assert.Equal(t, strings.Count(p, "<b>"), strings.Count(p, "</b>"))
+
+		joined.WriteString(plainText(p))
+	}
+
+	assert.Equal(t, strings.Count(plainText(in), "строка"), strings.Count(joined.String(), "строка"))
+}
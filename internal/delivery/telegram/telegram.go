@@ -2,27 +2,79 @@
 package telegram
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"html"
 	"log/slog"
 	"strconv"
+	"time"
 
 	"gopkg.in/telebot.v4"
 	models2 "support_bot/internal/models"
 )
 
+const (
+	// maxMessageLength — предел Telegram на длину текста одного сообщения.
+	maxMessageLength = 4096
+	// partNumberReserve — место под номер части в конце разбитого сообщения.
+	partNumberReserve = 16
+	// maxCaptionLength — предел Telegram на длину подписи к файлу.
+	maxCaptionLength = 1024
+	// maxAlbumSize — предел Telegram на число файлов в одном альбоме.
+	maxAlbumSize = 10
+	// textFileName — имя файла, которым отправляется слишком длинный текст.
+	textFileName = "report.txt"
+)
+
 type ChatAdaptor struct {
-	bot *telebot.Bot
-	log *slog.Logger
+	bot          *telebot.Bot
+	limiter      *Limiter
+	maxRetries   int
+	maxTextParts int
+	log          *slog.Logger
 }
 
-func NewChatAdaptor(bot *telebot.Bot, log *slog.Logger) *ChatAdaptor {
+// NewChatAdaptor создает отправителя в Telegram. Текст длиннее maxTextParts сообщений
+// отправляется файлом, 0 — всегда делить на сообщения.
+func NewChatAdaptor(bot *telebot.Bot, limits RateLimit, maxTextParts int, log *slog.Logger) *ChatAdaptor {
 	l := log.With(slog.Any("module", "telegram_sender"))
 
 	return &ChatAdaptor{
-		bot: bot,
-		log: l,
+		bot:          bot,
+		limiter:      NewLimiter(limits),
+		maxRetries:   limits.MaxRetries,
+		maxTextParts: maxTextParts,
+		log:          l,
+	}
+}
+
+// send выполняет запрос к Telegram, отправляющий n сообщений в чат, с учетом лимитов.
+// На ответ 429 ждет retry_after и повторяет запрос, не больше maxRetries раз.
+func (ca *ChatAdaptor) send(ctx context.Context, chatID int64, n int, fn func() error) error {
+	for attempt := 0; ; attempt++ {
+		if err := ca.limiter.Wait(ctx, chatID, n); err != nil {
+			return fmt.Errorf("wait for telegram rate limit: %w", err)
+		}
+
+		err := fn()
+
+		var flood telebot.FloodError
+		if !errors.As(err, &flood) || attempt >= ca.maxRetries {
+			return err
+		}
+
+		retryAfter := time.Duration(flood.RetryAfter) * time.Second
+		ca.limiter.Block(chatID, retryAfter)
+
+		ca.log.WarnContext(
+			ctx,
+			"telegram flood wait",
+			slog.Any("chat", chatID),
+			slog.Any("retry_after", retryAfter),
+			slog.Any("attempt", attempt+1),
+		)
 	}
 }
 
@@ -30,7 +82,19 @@ func (ca *ChatAdaptor) SendText(
 	ctx context.Context,
 	chat models2.TgChat,
 	msg string,
-) (*models2.TgMessage, error) {
+) ([]models2.TgMessage, error) {
+	return ca.SendTextWithMarkup(ctx, chat, msg, nil)
+}
+
+// SendTextWithMarkup отправляет текст с инлайн-клавиатурой markup.
+// Длинный текст делится на пронумерованные сообщения, клавиатура прикрепляется к последнему.
+// Если частей больше maxTextParts, текст без разметки отправляется файлом.
+func (ca *ChatAdaptor) SendTextWithMarkup(
+	ctx context.Context,
+	chat models2.TgChat,
+	msg string,
+	markup *telebot.ReplyMarkup,
+) ([]models2.TgMessage, error) {
 	l := ca.log.With(
 		slog.Group(
 			"recipient",
@@ -39,28 +103,164 @@ func (ca *ChatAdaptor) SendText(
 
 	l.InfoContext(ctx, "Start sending text message")
 
-	p := telebot.ModeHTML
+	parts := SplitHTML(msg, maxMessageLength-partNumberReserve)
+	if len(parts) == 0 {
+		return nil, errors.New("error send text message: empty text")
+	}
+
+	if ca.maxTextParts > 0 && len(parts) > ca.maxTextParts {
+		l.InfoContext(ctx, "Text is too long, sending as document", slog.Any("parts", len(parts)))
+
+		caption := fmt.Sprintf("Текст не поместился в %d сообщений и отправлен файлом.", ca.maxTextParts)
+		doc := models2.Data{Data: bytes.NewBufferString(plainText(msg)), Name: textFileName}
+
+		tgMsg, err := ca.sendDocument(ctx, chat, doc, caption, markup)
+		if err != nil {
+			return nil, fmt.Errorf("error send text message: %w", err)
+		}
+
+		return []models2.TgMessage{*models2.NewFromTelebot(tgMsg)}, nil
+	}
+
 	c := &telebot.Chat{ID: chat.ChatID}
-	o := &telebot.SendOptions{
-		ParseMode: p,
-		ThreadID:  chat.ThreadID,
+
+	var retMsg []models2.TgMessage
+
+	for k, part := range parts {
+		o := &telebot.SendOptions{
+			ParseMode: telebot.ModeHTML,
+			ThreadID:  chat.ThreadID,
+		}
+
+		if len(parts) > 1 {
+			part += fmt.Sprintf("\n\n<i>(%d/%d)</i>", k+1, len(parts))
+		}
+
+		if k == len(parts)-1 {
+			o.ReplyMarkup = markup
+		}
+
+		var tgMsg *telebot.Message
+
+		err := ca.send(ctx, chat.ChatID, 1, func() (err error) {
+			tgMsg, err = ca.bot.Send(c, part, o)
+
+			return err
+		})
+		if err != nil {
+			// Уже отправленные части возвращаются, чтобы их можно было удалить.
+			return retMsg, fmt.Errorf("error send text message part %d/%d: %w", k+1, len(parts), err)
+		}
+
+		retMsg = append(retMsg, *models2.NewFromTelebot(tgMsg))
 	}
 
-	tgMsg, err := ca.bot.Send(c, msg, o)
-	if err != nil {
-		return nil, fmt.Errorf("error send text message: %w", err)
+	return retMsg, nil
+}
+
+// albumChunks делит файлы на альбомы не больше maxAlbumSize поровну,
+// чтобы в последнем не остался один файл.
+func albumChunks(imgs []models2.Data) [][]models2.Data {
+	n := (len(imgs) + maxAlbumSize - 1) / maxAlbumSize
+	chunks := make([][]models2.Data, 0, n)
+
+	for k := range n {
+		chunks = append(chunks, imgs[k*len(imgs)/n:(k+1)*len(imgs)/n])
+	}
+
+	return chunks
+}
+
+// caption проверяет, что подпись помещается в лимит Telegram. Длинная подпись
+// отправляется отдельным сообщением перед файлами, а файлы уходят без нее.
+func (ca *ChatAdaptor) caption(
+	ctx context.Context,
+	chat models2.TgChat,
+	text string,
+) (string, []models2.TgMessage, error) {
+	if len(SplitHTML(text, maxCaptionLength)) <= 1 {
+		return text, nil, nil
+	}
+
+	msg, err := ca.SendText(ctx, chat, text)
+
+	return "", msg, err
+}
+
+// sendAlbums отправляет файлы альбомами не больше maxAlbumSize, подпись получает первый файл.
+// Один файл отправляется отдельным сообщением: альбом в Telegram — от двух файлов.
+func (ca *ChatAdaptor) sendAlbums(
+	ctx context.Context,
+	chat models2.TgChat,
+	files []models2.Data,
+	caption string,
+	item func(f models2.Data, caption string) telebot.Inputtable,
+) ([]models2.TgMessage, error) {
+	c := &telebot.Chat{ID: chat.ChatID}
+	o := &telebot.SendOptions{ThreadID: chat.ThreadID, ParseMode: telebot.ModeHTML}
+
+	var retErr error
+
+	var retMsg []models2.TgMessage
+
+	for k, chunk := range albumChunks(files) {
+		var tgMsg []telebot.Message
+
+		err := ca.send(ctx, chat.ChatID, len(chunk), func() (err error) {
+			// Альбом собирается заново для каждой попытки: предыдущая уже прочитала файлы.
+			album := make(telebot.Album, 0, len(chunk))
+
+			for j, f := range chunk {
+				var cp string
+				if k == 0 && j == 0 {
+					cp = caption
+				}
+
+				album = append(album, item(f, cp))
+			}
+
+			if len(album) == 1 {
+				var m *telebot.Message
+
+				m, err = ca.bot.Send(c, album[0], o)
+				if err == nil {
+					tgMsg = []telebot.Message{*m}
+				}
+
+				return err
+			}
+
+			tgMsg, err = ca.bot.SendAlbum(c, album, o)
+
+			return err
+		})
+		if err != nil {
+			ca.log.ErrorContext(
+				ctx,
+				"Error send album",
+				slog.Any("chat", chat.ChatID),
+				slog.Any("error", err),
+				slog.Any("files", len(chunk)),
+			)
+			retErr = errors.Join(retErr, err)
+
+			continue
+		}
+
+		retMsg = append(retMsg, models2.NewMsgFromTelebotMany(tgMsg)...)
 	}
 
-	return models2.NewFromTelebot(tgMsg), nil
+	return retMsg, retErr
 }
 
+// SendMedia отправляет изображения альбомами. opts.Caption — подпись к первому изображению,
+// у остальных подписью служит имя файла.
 func (ca *ChatAdaptor) SendMedia(
 	ctx context.Context,
 	chat models2.TgChat,
 	imgs []models2.Data,
+	opts models2.TgMediaOptions,
 ) ([]models2.TgMessage, error) {
-	var album telebot.Album
-
 	l := ca.log.With(
 		slog.Group(
 			"recipient",
@@ -69,34 +269,34 @@ func (ca *ChatAdaptor) SendMedia(
 
 	l.InfoContext(ctx, "Start sending media")
 
-	c := &telebot.Chat{ID: chat.ChatID}
-	o := &telebot.SendOptions{ThreadID: chat.ThreadID}
+	caption, retMsg, retErr := ca.caption(ctx, chat, opts.Caption)
 
-	for _, i := range imgs {
-		photo := &telebot.Photo{
-			File:    telebot.FromReader(i.Data),
-			Caption: i.Name,
+	msg, err := ca.sendAlbums(ctx, chat, imgs, caption, func(f models2.Data, caption string) telebot.Inputtable {
+		if caption == "" {
+			caption = html.EscapeString(f.Name)
 		}
 
-		album = append(album, photo)
-	}
-
-	tgMsg, err := ca.bot.SendAlbum(c, album, o)
-	if err != nil {
-		l.ErrorContext(ctx, "Error send media", slog.Any("error", err))
+		return &telebot.Photo{
+			File:    telebot.FromReader(bytes.NewReader(f.Data.Bytes())),
+			Caption: caption,
+		}
+	})
 
-		return nil, err
+	retMsg, retErr = append(retMsg, msg...), errors.Join(retErr, err)
+	if retErr == nil {
+		l.InfoContext(ctx, "Successfully send media")
 	}
 
-	l.InfoContext(ctx, "Successfully send media")
-
-	return models2.NewMsgFromTelebotMany(tgMsg), nil
+	return retMsg, retErr
 }
 
+// SendDocument отправляет документы по одному или, с opts.Group, альбомами.
+// opts.Caption — подпись к первому документу.
 func (ca *ChatAdaptor) SendDocument(
 	ctx context.Context,
 	chat models2.TgChat,
 	doc []models2.Data,
+	opts models2.TgMediaOptions,
 ) ([]models2.TgMessage, error) {
 	l := ca.log.With(
 		slog.Group(
@@ -106,27 +306,33 @@ func (ca *ChatAdaptor) SendDocument(
 
 	l.InfoContext(ctx, "Start sending document")
 
-	o := &telebot.SendOptions{ThreadID: chat.ThreadID}
-	c := &telebot.Chat{ID: chat.ChatID}
+	caption, retMsg, retErr := ca.caption(ctx, chat, opts.Caption)
 
-	var retErr error
+	if opts.Group {
+		msg, err := ca.sendAlbums(ctx, chat, doc, caption, func(f models2.Data, caption string) telebot.Inputtable {
+			return &telebot.Document{
+				File:     telebot.FromReader(bytes.NewReader(f.Data.Bytes())),
+				FileName: f.Name,
+				Caption:  caption,
+			}
+		})
 
-	var retMsg []models2.TgMessage
+		return append(retMsg, msg...), errors.Join(retErr, err)
+	}
 
-	for _, f := range doc {
-		doc, name := f.Data, f.Name
-		tgDoc := &telebot.Document{
-			File:     telebot.FromReader(doc),
-			FileName: name,
+	for k, f := range doc {
+		var cp string
+		if k == 0 {
+			cp = caption
 		}
 
-		tgMsg, err := ca.bot.Send(c, tgDoc, o)
+		tgMsg, err := ca.sendDocument(ctx, chat, f, cp, nil)
 		if err != nil {
 			l.ErrorContext(
 				ctx,
 				"Error send document",
 				slog.Any("error", err),
-				slog.Any("document_name", tgDoc.FileName),
+				slog.Any("document_name", f.Name),
 			)
 			retErr = errors.Join(retErr, err)
 
@@ -135,15 +341,136 @@ func (ca *ChatAdaptor) SendDocument(
 
 		retMsg = append(retMsg, *models2.NewFromTelebot(tgMsg))
 
-		l.InfoContext(ctx, "Successfully send document", slog.Any("document_name", tgDoc.FileName))
+		l.InfoContext(ctx, "Successfully send document", slog.Any("document_name", f.Name))
 	}
 
 	return retMsg, retErr
 }
 
+func (ca *ChatAdaptor) sendDocument(
+	ctx context.Context,
+	chat models2.TgChat,
+	f models2.Data,
+	caption string,
+	markup *telebot.ReplyMarkup,
+) (*telebot.Message, error) {
+	c := &telebot.Chat{ID: chat.ChatID}
+	o := &telebot.SendOptions{ThreadID: chat.ThreadID, ReplyMarkup: markup, ParseMode: telebot.ModeHTML}
+	tgDoc := &telebot.Document{FileName: f.Name, Caption: caption}
+
+	var tgMsg *telebot.Message
+
+	err := ca.send(ctx, chat.ChatID, 1, func() (err error) {
+		tgDoc.File = telebot.FromReader(bytes.NewReader(f.Data.Bytes()))
+		tgMsg, err = ca.bot.Send(c, tgDoc, o)
+
+		return err
+	})
+
+	return tgMsg, err
+}
+
+// edit выполняет запрос на изменение сообщения msg. Ответ «message is not modified»
+// означает, что содержимое уже совпадает, и ошибкой не считается.
+func (ca *ChatAdaptor) edit(ctx context.Context, msg models2.TgMessage, fn func(telebot.Editable) error) error {
+	stored := telebot.StoredMessage{
+		MessageID: strconv.Itoa(msg.MessageID),
+		ChatID:    msg.ChatID,
+	}
+
+	err := ca.send(ctx, msg.ChatID, 1, func() error { return fn(stored) })
+	if errors.Is(err, telebot.ErrMessageNotModified) || errors.Is(err, telebot.ErrSameMessageContent) {
+		return nil
+	}
+
+	return err
+}
+
+// EditText заменяет текст сообщения. Текст должен помещаться в одно сообщение.
+func (ca *ChatAdaptor) EditText(ctx context.Context, msg models2.TgMessage, text string) error {
+	if len(SplitHTML(text, maxMessageLength)) > 1 {
+		return fmt.Errorf("error edit text message: text does not fit one message")
+	}
+
+	err := ca.edit(ctx, msg, func(m telebot.Editable) error {
+		_, err := ca.bot.Edit(m, text, &telebot.SendOptions{ParseMode: telebot.ModeHTML})
+
+		return err
+	})
+	if err != nil {
+		ca.log.WarnContext(ctx, "Error edit text message", slog.Any("chat", msg.ChatID), slog.Any("error", err))
+
+		return fmt.Errorf("error edit text message: %w", err)
+	}
+
+	return nil
+}
+
+// EditMedia заменяет файл и подпись сообщения. Подпись должна помещаться в лимит Telegram.
+func (ca *ChatAdaptor) EditMedia(ctx context.Context, msg models2.TgMessage, data models2.Data, caption string) error {
+	if len(SplitHTML(caption, maxCaptionLength)) > 1 {
+		return fmt.Errorf("error edit media message: caption does not fit")
+	}
+
+	if caption == "" && data.IsImage() {
+		caption = html.EscapeString(data.Name)
+	}
+
+	err := ca.edit(ctx, msg, func(m telebot.Editable) error {
+		file := telebot.FromReader(bytes.NewReader(data.Data.Bytes()))
+
+		var media telebot.Inputtable = &telebot.Document{File: file, FileName: data.Name, Caption: caption}
+		if data.IsImage() {
+			media = &telebot.Photo{File: file, Caption: caption}
+		}
+
+		_, err := ca.bot.EditMedia(m, media, &telebot.SendOptions{ParseMode: telebot.ModeHTML})
+
+		return err
+	})
+	if err != nil {
+		ca.log.WarnContext(ctx, "Error edit media message", slog.Any("chat", msg.ChatID), slog.Any("error", err))
+
+		return fmt.Errorf("error edit media message: %w", err)
+	}
+
+	return nil
+}
+
+// Pin закрепляет сообщение без уведомления участников чата.
+func (ca *ChatAdaptor) Pin(ctx context.Context, msg models2.TgMessage) error {
+	err := ca.edit(ctx, msg, func(m telebot.Editable) error {
+		return ca.bot.Pin(m, telebot.Silent)
+	})
+	if err != nil {
+		ca.log.WarnContext(ctx, "Error pin message", slog.Any("chat", msg.ChatID), slog.Any("error", err))
+	}
+
+	return err
+}
+
+func (ca *ChatAdaptor) Unpin(ctx context.Context, msg models2.TgMessage) error {
+	err := ca.send(ctx, msg.ChatID, 1, func() error {
+		return ca.bot.Unpin(&telebot.Chat{ID: msg.ChatID}, msg.MessageID)
+	})
+	if err != nil {
+		ca.log.WarnContext(ctx, "Error unpin message", slog.Any("chat", msg.ChatID), slog.Any("error", err))
+	}
+
+	return err
+}
+
+// DeleteMsg удаляет сообщение. Уже удаленное сообщение ошибкой не считается.
 func (ca *ChatAdaptor) DeleteMsg(message models2.TgMessage) error {
-	return ca.bot.Delete(telebot.StoredMessage{
-		MessageID: strconv.Itoa(message.MessageID),
-		ChatID:    message.ChatID,
+	err := ca.send(context.Background(), message.ChatID, 1, func() error {
+		return ca.bot.Delete(telebot.StoredMessage{
+			MessageID: strconv.Itoa(message.MessageID),
+			ChatID:    message.ChatID,
+		})
 	})
+	if errors.Is(err, telebot.ErrNotFoundToDelete) {
+		return nil
+	}
+
+	return err
 }
@@ -62,6 +62,25 @@ func (e *evaluator) Evaluate(
 	}
 }
 
+// Validate проверяет, что выражение компилируется и возвращает bool.
+func (e *evaluator) Validate(expr string) error {
+	if expr == AlwaysTrueExpr || expr == AlwaysFalseExpr {
+		return nil
+	}
+
+	ast, iss := e.env.Compile(expr)
+	if iss != nil && iss.Err() != nil {
+		return fmt.Errorf("invalid expr: (%w)", iss.Err())
+	}
+
+	out := ast.OutputType()
+	if !out.IsExactType(cel.BoolType) && !out.IsExactType(cel.DynType) {
+		return fmt.Errorf("expr returns %s, expected bool", out)
+	}
+
+	return nil
+}
+
 func (e *evaluator) eval(
 	ctx context.Context,
 	expr string,
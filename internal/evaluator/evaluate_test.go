@@ -88,3 +88,15 @@ func TestEvaluator_Evaluate(t *testing.T) {
 		assert.False(t, ok)
 	})
 }
+
+func TestEvaluator_Validate(t *testing.T) {
+	t.Parallel()
+
+	eval, err := evaluator.NewEvaluator()
+	require.NoError(t, err)
+
+	require.NoError(t, eval.Validate(evaluator.AlwaysTrueExpr))
+	require.NoError(t, eval.Validate(`report["sheet1"].size() > 0`))
+	require.Error(t, eval.Validate(`report["sheet1"].size()`))
+	require.Error(t, eval.Validate(`report[`))
+}
@@ -22,11 +22,13 @@ func (api *EventAPI) ProduceSpecialEvent(
 	ctx context.Context,
 	name string,
 	recipient models2.Recipient,
+	run models2.RunInfo,
 ) {
 	ev := models2.SpecialEventForLK{
 		Event: models2.Event{
 			Name: name,
 			Type: models2.EventTypeGenReportForTG,
+			Run:  run,
 		},
 		Recipient: recipient,
 	}
@@ -40,6 +42,22 @@ func (api *EventAPI) ProduceSpecialEvent(
 	}()
 }
 
+// Trigger отправляет событие запуска отчета name и ждет, пока его примет Orchestrator.
+func (api *EventAPI) Trigger(ctx context.Context, name string, run models2.RunInfo) error {
+	ev := models2.Event{
+		Name: name,
+		Type: models2.EventTypeGenReport,
+		Run:  run,
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case api.OutC <- ev:
+		return nil
+	}
+}
+
 func (api *EventAPI) produceGenEvent(ctx context.Context, name string) {
 	ev := models2.Event{
 		Name: name,
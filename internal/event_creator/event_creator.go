@@ -15,6 +15,8 @@ type event struct {
 	CronName string `db:"cron_name"`
 }
 
+const defaultEventTimeout = 15 * time.Second
+
 type EventProvider interface {
 	Load(ctx context.Context) ([]event, error)
 	LoadByName(ctx context.Context, name string) ([]event, error)
@@ -23,8 +25,14 @@ type EventProvider interface {
 type EventCreator struct {
 	mu    sync.RWMutex
 	cache map[string][]models.Event
-	InC   chan models.Event
-	OutC  chan models.Event
+	// gen увеличивается при каждом сбросе кэша, чтобы загрузка,
+	// начатая до сброса, не положила в кэш устаревшие данные.
+	gen uint64
+
+	InC     chan models.Event
+	OutC    chan models.Event
+	changes <-chan string
+	timeout time.Duration
 
 	log *slog.Logger
 	ep  EventProvider
@@ -35,27 +43,41 @@ func New(
 	out chan models.Event,
 	log *slog.Logger,
 	ep EventProvider,
+	changes <-chan string,
+	timeout time.Duration,
 ) *EventCreator {
 	l := log.With(slog.Any("module", "event_creator"))
 
+	if timeout <= 0 {
+		timeout = defaultEventTimeout
+	}
+
 	return &EventCreator{
-		cache: make(map[string][]models.Event),
-		InC:   input,
-		OutC:  out,
-		log:   l,
-		ep:    ep,
-		mu:    sync.RWMutex{},
+		cache:   make(map[string][]models.Event),
+		InC:     input,
+		OutC:    out,
+		changes: changes,
+		timeout: timeout,
+		log:     l,
+		ep:      ep,
+		mu:      sync.RWMutex{},
 	}
 }
 
 func (e *EventCreator) Start(ctx context.Context) error {
-	e.cleaner(ctx)
+	e.invalidator(ctx)
 
 	err := e.heat(ctx)
 	if err != nil {
 		return err
 	}
 
+	return nil
+}
+
+// Dispatch запускает обработку событий расписаний. При нескольких экземплярах
+// вызывается только на лидере и останавливается при отмене ctx.
+func (e *EventCreator) Dispatch(ctx context.Context) {
 	go func() {
 		for {
 			select {
@@ -70,7 +92,8 @@ func (e *EventCreator) Start(ctx context.Context) error {
 					return
 				}
 
-				gCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+				// Начатое событие дорабатывается и при остановке, чтобы не потерять часть отчетов.
+				gCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), e.timeout)
 
 				switch ev.Type {
 				case models.EventTypeGenReport:
@@ -86,16 +109,37 @@ func (e *EventCreator) Start(ctx context.Context) error {
 			}
 		}
 	}()
-
-	return nil
 }
 
-func (e *EventCreator) reLoad() {
+// Flush сбрасывает кэш целиком.
+func (e *EventCreator) Flush() {
 	e.mu.Lock()
 	clear(e.cache)
+	e.gen++
 	e.mu.Unlock()
 }
 
+// Invalidate сбрасывает записи кэша, затронутые изменением.
+func (e *EventCreator) Invalidate(ctx context.Context, c models.Change) {
+	if c.IsFull() || c.Table == "reports" {
+		e.Flush()
+		e.log.DebugContext(ctx, "cache flushed", slog.Any("change", c))
+
+		return
+	}
+
+	if c.Cron == "" {
+		return
+	}
+
+	e.mu.Lock()
+	delete(e.cache, c.Cron)
+	e.gen++
+	e.mu.Unlock()
+
+	e.log.DebugContext(ctx, "cache invalidated", slog.Any("cron", c.Cron))
+}
+
 func (e *EventCreator) createGenReportEvent(ctx context.Context, ev models.Event) {
 	events, err := e.getByCronName(ctx, ev.Name)
 	if err != nil {
@@ -105,6 +149,8 @@ func (e *EventCreator) createGenReportEvent(ctx context.Context, ev models.Event
 	}
 
 	for _, en := range events {
+		en.Run = ev.Run
+
 		select {
 		case <-ctx.Done():
 			e.log.InfoContext(ctx, "context cancelled")
@@ -136,6 +182,8 @@ func (e *EventCreator) getByCronName(ctx context.Context, name string) ([]models
 		return ev, nil
 	}
 
+	gen := e.gen
+
 	e.mu.RUnlock()
 
 	events, err := e.ep.LoadByName(ctx, name)
@@ -143,46 +191,47 @@ func (e *EventCreator) getByCronName(ctx context.Context, name string) ([]models
 		return nil, err
 	}
 
-	e.mu.Lock()
-	defer e.mu.Unlock()
-
-	var names []models.Event
+	names := make([]models.Event, 0, len(events))
 
 	for _, ev := range events {
 		names = append(names, models.Event{
 			Name: ev.Name,
 			Type: models.EventTypeGenReport,
 		})
+	}
 
-		events, ok := e.cache[ev.CronName]
-		if !ok {
-			e.cache[ev.CronName] = []models.Event{{Name: name, Type: models.EventTypeGenReport}}
-
-			continue
-		}
+	e.mu.Lock()
+	defer e.mu.Unlock()
 
-		e.cache[ev.CronName] = append(
-			events,
-			models.Event{Name: name, Type: models.EventTypeGenReport},
-		)
+	if gen == e.gen {
+		e.cache[name] = names
 	}
 
 	return names, nil
 }
 
-func (e *EventCreator) cleaner(ctx context.Context) {
-	tick := time.NewTicker(5 * time.Minute)
+func (e *EventCreator) invalidator(ctx context.Context) {
+	if e.changes == nil {
+		return
+	}
 
 	go func() {
-		defer tick.Stop()
-
 		for {
 			select {
 			case <-ctx.Done():
 				return
-			case <-tick.C:
-				e.reLoad()
-				e.log.DebugContext(ctx, "cache cleaned")
+			case payload := <-e.changes:
+				c, err := models.ParseChange(payload)
+				if err != nil {
+					e.log.WarnContext(
+						ctx,
+						"unable to parse change, flushing cache",
+						slog.Any("payload", payload),
+						slog.Any("error", err),
+					)
+				}
+
+				e.Invalidate(ctx, c)
 			}
 		}
 	}()
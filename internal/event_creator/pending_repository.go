@@ -0,0 +1,139 @@
+package eventcreator
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/jmoiron/sqlx"
+	"support_bot/internal/models"
+)
+
+// Этапы пайплайна, на которых событие было прервано остановкой экземпляра.
+const (
+	StageSchedule = "schedule"
+	StageReport   = "report"
+	StageSpecial  = "special"
+	StageDelete   = "delete"
+)
+
+// Pending — событие, сохраненное при остановке для повторной отправки.
+type Pending struct {
+	Stage   string
+	Event   models.Event
+	Special models.SpecialEventForLK
+}
+
+// specialPayload разворачивает SpecialEventForLK: у встроенных Event и Recipient
+// совпадает поле Name, и при прямом маршалинге оно теряется.
+type specialPayload struct {
+	Event     models.Event
+	Recipient models.Recipient
+}
+
+type pendingRow struct {
+	ID      int64           `db:"id"`
+	Stage   string          `db:"stage"`
+	Payload json.RawMessage `db:"payload"`
+}
+
+type PendingRepository struct {
+	db *sqlx.DB
+
+	log *slog.Logger
+}
+
+func NewPendingRepository(db *sqlx.DB, log *slog.Logger) *PendingRepository {
+	l := log.With("module", "pending_event_repository")
+
+	return &PendingRepository{
+		db:  db,
+		log: l,
+	}
+}
+
+func (r *PendingRepository) Save(ctx context.Context, events ...Pending) error {
+	const query = `insert into pending_events(stage, payload) values ($1, $2);`
+
+	var saveErr error
+
+	for _, p := range events {
+		var (
+			payload []byte
+			err     error
+		)
+
+		if p.Stage == StageSpecial {
+			payload, err = json.Marshal(specialPayload{
+				Event:     p.Special.Event,
+				Recipient: p.Special.Recipient,
+			})
+		} else {
+			payload, err = json.Marshal(p.Event)
+		}
+
+		if err != nil {
+			saveErr = errors.Join(saveErr, fmt.Errorf("marshal pending event: %w", err))
+
+			continue
+		}
+
+		if _, err := r.db.ExecContext(ctx, query, p.Stage, payload); err != nil {
+			saveErr = errors.Join(saveErr, fmt.Errorf("save pending event: %w", err))
+		}
+	}
+
+	return saveErr
+}
+
+// Take забирает и удаляет все сохраненные события.
+func (r *PendingRepository) Take(ctx context.Context) ([]Pending, error) {
+	const query = `delete from pending_events returning id, stage, payload;`
+
+	var rows []pendingRow
+
+	if err := r.db.SelectContext(ctx, &rows, query); err != nil {
+		return nil, fmt.Errorf("take pending events: %w", err)
+	}
+
+	// delete ... returning не гарантирует порядок.
+	slices.SortFunc(rows, func(a, b pendingRow) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	events := make([]Pending, 0, len(rows))
+
+	for _, row := range rows {
+		p := Pending{Stage: row.Stage}
+
+		var err error
+
+		if row.Stage == StageSpecial {
+			var sp specialPayload
+
+			err = json.Unmarshal(row.Payload, &sp)
+			p.Special = models.SpecialEventForLK{Event: sp.Event, Recipient: sp.Recipient}
+		} else {
+			err = json.Unmarshal(row.Payload, &p.Event)
+		}
+
+		if err != nil {
+			r.log.ErrorContext(
+				ctx,
+				"unable to parse pending event, dropped",
+				slog.Any("id", row.ID),
+				slog.Any("error", err),
+			)
+
+			continue
+		}
+
+		events = append(events, p)
+	}
+
+	return events, nil
+}
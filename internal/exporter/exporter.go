@@ -12,9 +12,13 @@ import (
 	models2 "support_bot/internal/models"
 )
 
+// RunKey — ключ, под которым в text/html шаблонах доступна информация о запуске.
+const RunKey = "run"
+
 func Export(
 	data map[string][]map[string]any,
 	exp models2.Export,
+	run models2.RunInfo,
 ) ([]models2.Data, error) {
 	switch exp.Format {
 	case models2.ReportFormatCsv:
@@ -39,7 +43,7 @@ func Export(
 
 		return r, nil
 	case models2.ReportFormatHTML:
-		r, err := html.New(data, exp.Template.TemplateText, *exp.FileName).Export()
+		r, err := html.New(TemplateData(data, run), exp.Template.TemplateText, *exp.FileName).Export()
 		if err != nil {
 			return nil, err
 		}
@@ -47,7 +51,7 @@ func Export(
 		return []models2.Data{*r}, nil
 
 	case models2.ReportFormatPdf:
-		rh, err := html.New(data, exp.Template.TemplateText, *exp.FileName).Export()
+		rh, err := html.New(TemplateData(data, run), exp.Template.TemplateText, *exp.FileName).Export()
 		if err != nil {
 			return nil, err
 		}
@@ -59,7 +63,7 @@ func Export(
 
 		return []models2.Data{*r}, nil
 	case models2.ReportFormatText:
-		r, err := text.New(data, exp.Template.TemplateText).Export()
+		r, err := text.New(TemplateData(data, run), exp.Template.TemplateText).Export()
 		if err != nil {
 			return nil, err
 		}
@@ -69,3 +73,17 @@ func Export(
 		return nil, fmt.Errorf("undefined format: %s", exp.Format)
 	}
 }
+
+// TemplateData добавляет к данным карточек информацию о запуске.
+// Табличные экспортеры получают данные без изменений, чтобы не появлялся лишний лист.
+func TemplateData(data map[string][]map[string]any, run models2.RunInfo) map[string]any {
+	td := make(map[string]any, len(data)+1)
+
+	for k, v := range data {
+		td[k] = v
+	}
+
+	td[RunKey] = run
+
+	return td
+}
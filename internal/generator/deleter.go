@@ -14,6 +14,8 @@ type tgMsgDeleter interface {
 	DeleteMsg(msg models2.TgMessage) error
 }
 
+// Deleter удаляет отправленные в Telegram сообщения по политикам хранения получателей.
+// Проход выполняется раз в interval и сразу по событию удаления из расписания.
 type Deleter struct {
 	tgDel tgMsgDeleter
 
@@ -21,6 +23,11 @@ type Deleter struct {
 
 	evC chan models2.Event
 
+	interval time.Duration
+	// giveUp — после этого срока с отправки сообщение, которое не удалось удалить,
+	// перестает отслеживаться: Telegram не дает боту удалять старые сообщения.
+	giveUp time.Duration
+
 	log *slog.Logger
 }
 
@@ -28,20 +35,29 @@ func NewDeleter(
 	evC chan models2.Event,
 	tgDel tgMsgDeleter,
 	repo SentMsgRepository,
+	interval time.Duration,
+	giveUp time.Duration,
 	log *slog.Logger,
 ) *Deleter {
 	l := log.With(slog.Any("module", "deleter"))
 
 	return &Deleter{
-		tgDel: tgDel,
-		repo:  repo,
-		log:   l,
-		evC:   evC,
+		tgDel:    tgDel,
+		repo:     repo,
+		log:      l,
+		evC:      evC,
+		interval: interval,
+		giveUp:   giveUp,
 	}
 }
 
 func (d *Deleter) Start(ctx context.Context) {
 	go func() {
+		ticker := time.NewTicker(d.interval)
+		defer ticker.Stop()
+
+		d.sweep(ctx)
+
 		for {
 			select {
 			case <-ctx.Done():
@@ -52,6 +68,8 @@ func (d *Deleter) Start(ctx context.Context) {
 				)
 
 				return
+			case <-ticker.C:
+				d.sweep(ctx)
 			case e, ok := <-d.evC:
 				if !ok {
 					d.log.InfoContext(ctx, "event chan closed")
@@ -65,16 +83,14 @@ func (d *Deleter) Start(ctx context.Context) {
 					d.log.ErrorContext(ctx, "unexpected event type", slog.Any("event", e))
 				}
 
-				d.delete(ctx)
+				d.sweep(ctx)
 			}
 		}
 	}()
 }
 
-func (d *Deleter) delete(ctx context.Context) {
-	d.markLastMsgAsDeletedWithoutDelete(ctx)
+func (d *Deleter) sweep(ctx context.Context) {
 	d.clearDeletedMessages(ctx)
-	d.log.InfoContext(ctx, "start deleting messages")
 
 	msg, u, err := d.repo.loadMsgToDelete(ctx)
 	if err != nil {
@@ -92,12 +108,18 @@ func (d *Deleter) delete(ctx context.Context) {
 		}
 	}()
 
+	if len(msg) == 0 {
+		return
+	}
+
+	d.log.InfoContext(ctx, "start deleting messages", slog.Any("count", len(msg)))
+
 	for _, m := range msg {
 		err := d.tgDel.DeleteMsg(m)
 		if err != nil {
 			d.log.ErrorContext(ctx, "failed to delete messages", slog.Any("err", err))
 
-			if time.Since(m.Time) < 72*time.Hour {
+			if time.Since(m.Time) < d.giveUp {
 				continue
 			}
 		}
@@ -117,23 +139,14 @@ func (d *Deleter) delete(ctx context.Context) {
 }
 
 func (d *Deleter) clearDeletedMessages(ctx context.Context) {
-	d.log.InfoContext(ctx, "begin clear deleted messages")
-
 	removed, err := d.repo.removeDeletedMessages(ctx)
 	if err != nil {
 		d.log.ErrorContext(ctx, "failed to clear deleted messages", slog.Any("err", err))
-	}
-
-	d.log.InfoContext(ctx, "end clear deleted messages", slog.Any("removed", removed))
-}
-
-func (d *Deleter) markLastMsgAsDeletedWithoutDelete(ctx context.Context) {
-	d.log.InfoContext(ctx, "begin mark last msg as deleted")
 
-	err := d.repo.markEndOfDayMsgDeleted(ctx)
-	if err != nil {
-		d.log.ErrorContext(ctx, "mark last messages as deleted error", slog.Any("error", err))
+		return
 	}
 
-	d.log.InfoContext(ctx, "end mark last msg as deleted")
+	if removed > 0 {
+		d.log.DebugContext(ctx, "deleted messages cleared", slog.Any("removed", removed))
+	}
 }
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"sync"
 	"time"
 
 	"support_bot/internal/collector"
@@ -25,8 +26,75 @@ type Evaluator interface {
 	) (bool, error)
 }
 
+// JobQueue — очередь задач на генерацию отчетов.
+type JobQueue interface {
+	Dequeue(ctx context.Context, heavy bool) (*models.Job, error)
+	Extend(ctx context.Context, id int64) (bool, error)
+	Complete(ctx context.Context, id int64) error
+	Fail(ctx context.Context, job *models.Job, cause error) error
+	Skip(ctx context.Context, id int64, reason string) error
+	Defer(ctx context.Context, id int64, delay time.Duration) error
+	Cancel(ctx context.Context, lockKey string, by int64) (int64, error)
+	Reap(ctx context.Context) error
+}
+
+// Locker — межпроцессная блокировка запусков отчета.
+type Locker interface {
+	TryLock(ctx context.Context, key string) (release func(), ok bool, err error)
+}
+
+const (
+	ackTimeout           = 10 * time.Second
+	defaultPollInterval  = 10 * time.Second
+	defaultReportTimeout = 5 * time.Minute
+	// minDeferDelay не дает задаче, отложенной из-за идущего запуска, возвращаться в очередь без паузы.
+	minDeferDelay = time.Second
+)
+
+// Options — настройки обработки задач.
+type Options struct {
+	// LightWorkers и HeavyWorkers — размеры пулов для легких (text, csv, ...)
+	// и тяжелых (pdf, png) отчетов, чтобы медленный PDF не задерживал оповещения.
+	LightWorkers uint8
+	HeavyWorkers uint8
+	// ReportTimeout — время на генерацию отчета, если в отчете не задано свое.
+	ReportTimeout time.Duration
+	// PollInterval — интервал опроса очереди.
+	PollInterval time.Duration
+	// Heartbeat — интервал продления видимости задачи.
+	Heartbeat time.Duration
+	// Policy — политика одновременных запусков для отчетов, у которых она не задана.
+	Policy models.ConcurrencyPolicy
+	// BusyDelay — через сколько повторить задачу, отложенную из-за уже идущего запуска.
+	BusyDelay time.Duration
+}
+
+// errJobCancelled — причина отмены контекста задачи, которую вытеснил более новый запуск.
+var errJobCancelled = errors.New("report job cancelled")
+
 type Generator struct {
-	c chan models.Report
+	queue JobQueue
+	// wake получает уведомления о новых задачах, чтобы не ждать очередного опроса.
+	// Payload — heavy или light, по нему будится нужный пул.
+	wake      <-chan string
+	wakeLight chan struct{}
+	wakeHeavy chan struct{}
+	// chained получает события запуска зависимых отчетов.
+	chained chan<- models.Event
+
+	opts Options
+
+	locker Locker
+
+	mu sync.Mutex
+	// running — отмена выполняющихся на этом экземпляре задач по ключу блокировки.
+	running map[string]context.CancelCauseFunc
+
+	// stopping закрывается в Drain: обработчики перестают брать новые задачи.
+	stopping chan struct{}
+	// abort прерывает выполняющиеся задачи, если Drain не дождался их завершения.
+	abort   context.CancelFunc
+	workers sync.WaitGroup
 
 	clct Collector
 
@@ -34,70 +102,393 @@ type Generator struct {
 
 	snd models.SenderProvider
 
-	numWorkers uint8
-
 	sentMsgRepo SentMsgRepository
 
 	log *slog.Logger
 }
 
 func New(
-	c chan models.Report,
+	queue JobQueue,
+	wake <-chan string,
+	chained chan<- models.Event,
 	clct Collector,
 	snd models.SenderProvider,
 	sendRepo SentMsgRepository,
 	eval Evaluator,
-	workers uint8,
+	locker Locker,
+	opts Options,
 	log *slog.Logger,
 ) *Generator {
 	l := log.With(slog.Any("module", "generator"))
 
-	if workers == 0 {
-		workers = 1
+	if opts.LightWorkers == 0 {
+		opts.LightWorkers = 1
+	}
+
+	if opts.HeavyWorkers == 0 {
+		opts.HeavyWorkers = 1
+	}
+
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultPollInterval
+	}
+
+	if opts.ReportTimeout <= 0 {
+		opts.ReportTimeout = defaultReportTimeout
 	}
 
 	return &Generator{
-		c:           c,
+		queue:       queue,
+		wake:        wake,
+		wakeLight:   make(chan struct{}, 1),
+		wakeHeavy:   make(chan struct{}, 1),
+		chained:     chained,
+		opts:        opts,
+		locker:      locker,
+		running:     make(map[string]context.CancelCauseFunc),
+		stopping:    make(chan struct{}),
+		abort:       func() {},
 		clct:        clct,
 		eval:        eval,
 		snd:         snd,
 		log:         l,
-		numWorkers:  workers,
 		sentMsgRepo: sendRepo,
 	}
 }
 
 func (g *Generator) Start(ctx context.Context) {
-	for i := range g.numWorkers {
-		go g.worker(ctx, g.c, i)
+	jobCtx, abort := context.WithCancel(ctx)
+	g.abort = abort
+
+	for i := range g.opts.LightWorkers {
+		g.workers.Go(func() { g.worker(jobCtx, i, false) })
+	}
+
+	for i := range g.opts.HeavyWorkers {
+		g.workers.Go(func() { g.worker(jobCtx, i, true) })
 	}
+
+	go g.waker(ctx)
+	go g.reaper(ctx)
 }
 
-func (g *Generator) worker(ctx context.Context, jobs <-chan models.Report, id uint8) {
-	g.log.DebugContext(ctx, fmt.Sprintf("start worker %d", id))
+// Drain перестает брать новые задачи и ждет завершения выполняющихся до отмены ctx.
+// Задачи, не завершенные к этому моменту, прерываются и возвращаются в очередь.
+func (g *Generator) Drain(ctx context.Context) error {
+	close(g.stopping)
+
+	done := make(chan struct{})
+
+	go func() {
+		g.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		g.log.InfoContext(ctx, "all report jobs finished")
+
+		return nil
+	case <-ctx.Done():
+	}
+
+	g.abort()
+
+	// Прерванные задачи возвращаются в очередь без ожидания ctx, это быстрые запросы.
+	select {
+	case <-done:
+	case <-time.After(ackTimeout):
+	}
+
+	return fmt.Errorf("report jobs interrupted: %w", ctx.Err())
+}
 
+// waker будит пул, для которого появилась задача. Пустой payload будит оба пула.
+func (g *Generator) waker(ctx context.Context) {
 	for {
+		select {
+		case <-ctx.Done():
+			return
+		case payload := <-g.wake:
+			if payload != "heavy" {
+				notify(g.wakeLight)
+			}
+
+			if payload != "light" {
+				notify(g.wakeHeavy)
+			}
+		}
+	}
+}
+
+func notify(c chan struct{}) {
+	select {
+	case c <- struct{}{}:
+	default:
+	}
+}
+
+func (g *Generator) worker(ctx context.Context, id uint8, heavy bool) {
+	g.log.DebugContext(ctx, fmt.Sprintf("start worker %d", id), slog.Any("heavy", heavy))
+
+	wake := g.wakeLight
+	if heavy {
+		wake = g.wakeHeavy
+	}
+
+	ticker := time.NewTicker(g.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-g.stopping:
+			return
+		default:
+		}
+
+		job, err := g.queue.Dequeue(ctx, heavy)
+		if err != nil {
+			g.log.ErrorContext(ctx, "error dequeue report job", slog.Any("error", err))
+		}
+
+		if job != nil {
+			// Задач может быть больше, чем свободных обработчиков: будим соседа.
+			notify(wake)
+			g.process(ctx, job)
+
+			continue
+		}
+
 		select {
 		case <-ctx.Done():
 			g.log.DebugContext(ctx, "context cancelled")
 
 			return
-		case j, ok := <-jobs:
-			if !ok {
-				g.log.DebugContext(ctx, "jobs chan closed")
+		case <-g.stopping:
+			return
+		case <-wake:
+		case <-ticker.C:
+		}
+	}
+}
 
-				return
-			}
+// process генерирует отчет по задаче и подтверждает ее. Пока отчет генерируется,
+// видимость задачи продлевается. Если экземпляр упадет, задачу заберет другой
+// после истечения видимости.
+func (g *Generator) process(ctx context.Context, job *models.Job) {
+	rCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	timeout := job.Report.Timeout
+	if timeout <= 0 {
+		timeout = g.opts.ReportTimeout
+	}
+
+	tCtx, tCancel := context.WithTimeout(rCtx, timeout)
+	defer tCancel()
+
+	key := job.Report.LockKey()
+
+	rvCtx := logger.AppendCtx(
+		tCtx,
+		slog.Any("report_name", job.Report.Name),
+		slog.Any("job_id", job.ID),
+		slog.Any("attempt", job.Attempts),
+	)
+
+	release, ok, err := g.acquire(rvCtx, key, cancel)
+	if err != nil {
+		g.log.ErrorContext(rvCtx, "unable to acquire report lock", slog.Any("error", err))
+		g.deferJob(rvCtx, job, max(g.opts.BusyDelay, minDeferDelay))
+
+		return
+	}
+
+	if !ok {
+		g.busy(rvCtx, job, key)
+
+		return
+	}
+
+	defer release()
+
+	done := make(chan struct{})
+	go g.keepAlive(rvCtx, job.ID, cancel, done)
+
+	err = g.createReport(rvCtx, job.Report)
+
+	close(done)
+
+	if errors.Is(context.Cause(rCtx), errJobCancelled) {
+		g.log.WarnContext(rvCtx, "report job cancelled by newer run")
+
+		return
+	}
 
-			rCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
-			rvCtx := logger.AppendCtx(rCtx, slog.Any("report_name", j.Name))
+	if ctx.Err() != nil {
+		// Экземпляр останавливается: задача сразу возвращается в очередь.
+		g.log.WarnContext(rvCtx, "report job interrupted, released", slog.Any("error", err))
+		g.deferJob(rvCtx, job, 0)
 
-			err := g.createReport(rvCtx, j)
+		return
+	}
+
+	ackCtx, ackCancel := context.WithTimeout(context.WithoutCancel(rvCtx), ackTimeout)
+	defer ackCancel()
+
+	if err != nil {
+		g.log.ErrorContext(rvCtx, "error create report", slog.Any("error", err))
+
+		if err := g.queue.Fail(ackCtx, job, err); err != nil {
+			g.log.ErrorContext(ackCtx, "unable to fail report job", slog.Any("error", err))
+		}
+
+		return
+	}
+
+	if err := g.queue.Complete(ackCtx, job.ID); err != nil {
+		g.log.ErrorContext(ackCtx, "unable to complete report job", slog.Any("error", err))
+	}
+}
+
+// acquire берет блокировку запуска сначала внутри процесса, затем в базе.
+func (g *Generator) acquire(
+	ctx context.Context,
+	key string,
+	cancel context.CancelCauseFunc,
+) (func(), bool, error) {
+	g.mu.Lock()
+
+	if _, ok := g.running[key]; ok {
+		g.mu.Unlock()
+
+		return nil, false, nil
+	}
+
+	g.running[key] = cancel
+	g.mu.Unlock()
+
+	forget := func() {
+		g.mu.Lock()
+		delete(g.running, key)
+		g.mu.Unlock()
+	}
+
+	release, ok, err := g.locker.TryLock(ctx, key)
+	if err != nil || !ok {
+		forget()
+
+		return nil, false, err
+	}
+
+	return func() {
+		release()
+		forget()
+	}, true, nil
+}
+
+// busy применяет политику одновременных запусков к задаче, отчет которой уже генерируется.
+func (g *Generator) busy(ctx context.Context, job *models.Job, key string) {
+	policy := job.Report.Concurrency
+	if policy == "" {
+		policy = g.opts.Policy
+	}
+
+	ackCtx, ackCancel := context.WithTimeout(context.WithoutCancel(ctx), ackTimeout)
+	defer ackCancel()
+
+	g.log.InfoContext(ctx, "report is already running", slog.Any("policy", policy))
+
+	switch policy {
+	case models.ConcurrencyCancel:
+		g.mu.Lock()
+		if cancel, ok := g.running[key]; ok {
+			cancel(errJobCancelled)
+		}
+		g.mu.Unlock()
+
+		n, err := g.queue.Cancel(ackCtx, key, job.ID)
+		if err != nil {
+			g.log.ErrorContext(ctx, "unable to cancel running report job", slog.Any("error", err))
+		} else {
+			g.log.InfoContext(ctx, "running report jobs cancelled", slog.Any("count", n))
+		}
+
+		// Отмененная задача снимет блокировку при следующем продлении видимости.
+		delay := g.opts.Heartbeat
+		if delay <= 0 {
+			delay = g.opts.BusyDelay
+		}
+
+		g.deferJob(ctx, job, max(delay, minDeferDelay))
+	case models.ConcurrencyQueue:
+		g.deferJob(ctx, job, max(g.opts.BusyDelay, minDeferDelay))
+	default:
+		if err := g.queue.Skip(ackCtx, job.ID, "report is already running"); err != nil {
+			g.log.ErrorContext(ctx, "unable to skip report job", slog.Any("error", err))
+		}
+	}
+}
+
+func (g *Generator) deferJob(ctx context.Context, job *models.Job, delay time.Duration) {
+	ackCtx, ackCancel := context.WithTimeout(context.WithoutCancel(ctx), ackTimeout)
+	defer ackCancel()
+
+	if err := g.queue.Defer(ackCtx, job.ID, delay); err != nil {
+		g.log.ErrorContext(ctx, "unable to defer report job", slog.Any("error", err))
+	}
+}
+
+// keepAlive продлевает видимость задачи и отменяет ее генерацию,
+// если задачу отменил более новый запуск.
+func (g *Generator) keepAlive(
+	ctx context.Context,
+	id int64,
+	cancel context.CancelCauseFunc,
+	done <-chan struct{},
+) {
+	if g.opts.Heartbeat <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(g.opts.Heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-done:
+			return
+		case <-ticker.C:
+			running, err := g.queue.Extend(ctx, id)
 			if err != nil {
-				g.log.ErrorContext(rvCtx, "error create report", slog.Any("error", err))
+				g.log.WarnContext(ctx, "unable to extend report job", slog.Any("error", err))
+
+				continue
+			}
+
+			if !running {
+				cancel(errJobCancelled)
+
+				return
 			}
+		}
+	}
+}
+
+func (g *Generator) reaper(ctx context.Context) {
+	ticker := time.NewTicker(g.opts.PollInterval)
+	defer ticker.Stop()
 
-			cancel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := g.queue.Reap(ctx); err != nil {
+				g.log.WarnContext(ctx, "unable to reap report jobs", slog.Any("error", err))
+			}
 		}
 	}
 }
@@ -106,7 +497,7 @@ func (g *Generator) createReport(ctx context.Context, report models.Report) erro
 	l := g.log
 	l.DebugContext(ctx, "start generating report", slog.Any("report", report))
 
-	data, err := g.clct.Collect(ctx, report.Queries...)
+	data, err := g.clct.Collect(ctx, report.QueriesWithParams()...)
 	if err != nil && !errors.Is(err, collector.ErrEmtyCard) {
 		l.ErrorContext(ctx, "error while collect data", slog.Any("error", err))
 
@@ -129,7 +520,7 @@ func (g *Generator) createReport(ctx context.Context, report models.Report) erro
 	res := make([]models.Data, 0, len(report.Exports))
 
 	for _, e := range report.Exports {
-		r, err := exporter.Export(data, e)
+		r, err := exporter.Export(data, e, report.Run)
 		if err != nil {
 			l.ErrorContext(
 				ctx,
@@ -150,23 +541,66 @@ func (g *Generator) createReport(ctx context.Context, report models.Report) erro
 		return fmt.Errorf("empty targets list")
 	}
 
-	msg := models.NewMessage(report.Name, res, report.Recipients...)
+	msg := models.NewMessage(report.Name, report.TgLayout, res, report.Recipients...)
 
-	resMsg, err := msg.Send(ctx, g.snd)
-	if err != nil {
-		l.ErrorContext(ctx, "error while send message", slog.Any("error", err))
+	if msg.EditsInPlace() {
+		msg.Live, err = g.sentMsgRepo.loadLiveMsgs(ctx, report.Name)
+		if err != nil {
+			l.WarnContext(ctx, "live messages load failed, sending new ones", slog.Any("error", err))
+		}
 	}
 
-	if len(resMsg) == 0 {
-		l.InfoContext(ctx, "report generated")
+	resMsg, sendErr := msg.Send(ctx, g.snd)
+	if sendErr != nil {
+		l.ErrorContext(ctx, "error while send message", slog.Any("error", sendErr))
+	} else {
+		g.runDependents(ctx, report, data)
+	}
 
-		return nil
+	// Уже отправленные сообщения сохраняются и при ошибке, чтобы их удалила политика хранения.
+	if len(resMsg) > 0 {
+		if err := g.sentMsgRepo.saveTgMsg(ctx, msg.ReportName, resMsg); err != nil {
+			l.WarnContext(ctx, "result msg save failed", slog.Any("error", err))
+		}
 	}
 
-	err = g.sentMsgRepo.saveTgMsg(ctx, msg.ReportName, resMsg)
-	if err != nil {
-		l.WarnContext(ctx, "result msg save failed", slog.Any("error", err))
+	if sendErr != nil {
+		return fmt.Errorf("send report (%d messages sent): %w", len(resMsg), sendErr)
 	}
 
+	l.InfoContext(ctx, "report generated")
+
 	return nil
 }
+
+// runDependents запускает отчеты, зависящие от успешно отправленного report.
+func (g *Generator) runDependents(
+	ctx context.Context,
+	report models.Report,
+	data map[string][]map[string]any,
+) {
+	for _, d := range report.Dependents {
+		run, ok := report.Run.Chained(report.Name, d, data)
+		if !ok {
+			g.log.WarnContext(
+				ctx,
+				"dependency cycle detected, skip dependent",
+				slog.Any("dependent", d.Name),
+				slog.Any("chain", report.Run.Chain),
+			)
+
+			continue
+		}
+
+		ev := models.Event{Name: d.Name, Type: models.EventTypeGenReport, Run: run}
+
+		select {
+		case <-ctx.Done():
+			g.log.WarnContext(ctx, "context cancelled, dependent not started", slog.Any("dependent", d.Name))
+
+			return
+		case g.chained <- ev:
+			g.log.InfoContext(ctx, "dependent report triggered", slog.Any("dependent", d.Name))
+		}
+	}
+}
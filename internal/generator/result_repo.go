@@ -30,15 +30,37 @@ func (rr *SentMsgRepository) saveTgMsg(
 	reportName string,
 	msgs []models.TgMessage,
 ) error {
-	const query = `insert into sent_messages(chat_id, thread_id, message_id, title, sent_at, report_name) values ($1, $2, $3, $4, $5, $6);`
+	const query = `insert into sent_messages(chat_id, thread_id, message_id, title, sent_at, report_name, live, delete_at, keep_last, batch)
+values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);`
+
+	const batchQuery = `select nextval('sent_messages_batch_seq');`
+
+	// Новое обновляемое сообщение заменяет предыдущее: старое больше не обновляется,
+	// но остается в чате, пока его не удалит политика хранения получателя.
+	const retireQuery = `update sent_messages set live = false
+where live and not deleted and report_name = $1 and chat_id = $2 and thread_id = $3;`
 
 	if err := ctx.Err(); err != nil {
 		return fmt.Errorf("chat repository create: %w", err)
 	}
 
+	// Все сообщения одной отправки получают общий номер: keep_last считает отправки, а не сообщения.
+	var batch int64
+	if err := rr.db.GetContext(ctx, &batch, batchQuery); err != nil {
+		return fmt.Errorf("next sent messages batch: %w", err)
+	}
+
 	var saveErr error
 
 	for _, msg := range msgs {
+		if msg.Live {
+			if _, err := rr.db.ExecContext(ctx, retireQuery, reportName, msg.ChatID, msg.ThreadID); err != nil {
+				saveErr = errors.Join(saveErr, err)
+
+				continue
+			}
+		}
+
 		_, err := rr.db.ExecContext(
 			ctx,
 			query,
@@ -48,6 +70,10 @@ func (rr *SentMsgRepository) saveTgMsg(
 			msg.Title,
 			msg.Time,
 			reportName,
+			msg.Live,
+			msg.DeleteAt,
+			msg.KeepLast,
+			batch,
 		)
 		if err != nil {
 			saveErr = errors.Join(saveErr, err)
@@ -57,11 +83,49 @@ func (rr *SentMsgRepository) saveTgMsg(
 	return saveErr
 }
 
+// loadLiveMsgs возвращает сообщения отчета, которые обновляются на месте, по чатам и топикам.
+func (rr *SentMsgRepository) loadLiveMsgs(
+	ctx context.Context,
+	reportName string,
+) (map[models.TgChat]models.TgMessage, error) {
+	const query = `select id, chat_id, thread_id, message_id, title, sent_at, deleted, live from sent_messages
+where live and not deleted and report_name = $1;`
+
+	var msgs []models.TgMessage
+
+	if err := rr.db.SelectContext(ctx, &msgs, query, reportName); err != nil {
+		return nil, fmt.Errorf("load live messages: %w", err)
+	}
+
+	live := make(map[models.TgChat]models.TgMessage, len(msgs))
+	for _, msg := range msgs {
+		live[models.TgChat{ChatID: msg.ChatID, ThreadID: msg.ThreadID}] = msg
+	}
+
+	return live, nil
+}
+
+// loadMsgToDelete блокирует и возвращает сообщения, которые пора удалить по политике хранения:
+// с наступившим delete_at и не вошедшие в keep_last последних отправок отчета в чате.
 func (rr *SentMsgRepository) loadMsgToDelete(
 	ctx context.Context,
 ) ([]models.TgMessage, uow.UOW, error) {
-	const query = `select id, chat_id, thread_id, message_id, title, sent_at, deleted from sent_messages where deleted = False AND sent_at >= CURRENT_DATE - INTERVAL '1 day'
-	AND sent_at < CURRENT_DATE for update skip locked;`
+	const query = `select id, chat_id, thread_id, message_id, title, sent_at, deleted, live
+from sent_messages
+where not deleted
+  and not live
+  and (delete_at <= now()
+    or id in (
+      select id
+      from (
+        select id, keep_last,
+               dense_rank() over (partition by report_name, chat_id, thread_id order by batch desc) as pos
+        from sent_messages
+        where not deleted and not live and keep_last is not null
+      ) ranked
+      where pos > keep_last
+    ))
+for update skip locked;`
 
 	var msgs []models.TgMessage
 
@@ -71,6 +135,8 @@ func (rr *SentMsgRepository) loadMsgToDelete(
 	}
 
 	if err := tx.SelectContext(ctx, &msgs, query); err != nil {
+		_ = tx.Rollback()
+
 		return nil, nil, err
 	}
 
@@ -112,36 +178,3 @@ func (rr *SentMsgRepository) removeDeletedMessages(ctx context.Context) (int64,
 
 	return removed, tx.Commit()
 }
-
-func (rr *SentMsgRepository) markEndOfDayMsgDeleted(ctx context.Context) error {
-	const query = `UPDATE sent_messages sm
-SET deleted = TRUE
-FROM (
-    SELECT DISTINCT ON (report_name, chat_id)
-        id
-    FROM sent_messages
-    WHERE deleted = FALSE
-      AND sent_at >= CURRENT_DATE - INTERVAL '1 day'
-      AND sent_at < CURRENT_DATE
-    ORDER BY report_name, chat_id, sent_at DESC, id DESC
-) last_msgs
-WHERE sm.id = last_msgs.id;`
-
-	tx, err := rr.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
-	if err != nil {
-		rr.log.ErrorContext(ctx, "begin tx failed, continue without tx", err)
-
-		_, err = rr.db.ExecContext(ctx, query)
-		if err != nil {
-			return err
-		}
-	}
-	defer tx.Rollback()
-
-	_, err = tx.ExecContext(ctx, query)
-	if err != nil {
-		return err
-	}
-
-	return tx.Commit()
-}
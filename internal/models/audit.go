@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// AuditAction — действие администратора или пользователя в боте.
+type AuditAction string
+
+const (
+	AuditUserAdd    AuditAction = "user.add"
+	AuditUserDelete AuditAction = "user.delete"
+
+	AuditChatAdd    AuditAction = "chat.add"
+	AuditChatRemove AuditAction = "chat.remove"
+
+	AuditScheduleStart AuditAction = "schedule.start"
+	AuditScheduleStop  AuditAction = "schedule.stop"
+	AuditCacheFlush    AuditAction = "cache.flush"
+
+	AuditControlPause  AuditAction = "control.pause"
+	AuditControlSkip   AuditAction = "control.skip"
+	AuditControlResume AuditAction = "control.resume"
+	AuditControlRun    AuditAction = "control.run"
+
+	AuditReportCreate AuditAction = "report.create"
+	AuditReportUpdate AuditAction = "report.update"
+
+	AuditRegistrationApprove AuditAction = "registration.approve"
+	AuditRegistrationDeny    AuditAction = "registration.deny"
+
+	AuditInviteCreate AuditAction = "invite.create"
+	AuditInviteRevoke AuditAction = "invite.revoke"
+
+	AuditSubscribe   AuditAction = "subscription.add"
+	AuditUnsubscribe AuditAction = "subscription.remove"
+)
+
+// AuditEntry — запись журнала действий. Before и After — JSON объекта до и после изменения.
+type AuditEntry struct {
+	ID        int64       `db:"id"`
+	Actor     string      `db:"actor"`
+	Action    AuditAction `db:"action"`
+	Target    string      `db:"target"`
+	Before    *string     `db:"before"`
+	After     *string     `db:"after"`
+	CreatedAt time.Time   `db:"created_at"`
+}
+
+type LoadAuditRPL struct {
+	PageCount   int
+	CurrentPage int
+	Entries     []AuditEntry
+}
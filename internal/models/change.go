@@ -0,0 +1,35 @@
+package models
+
+import "encoding/json"
+
+// Change описывает изменение строки в таблице, полученное через LISTEN/NOTIFY.
+// Name — имя затронутой сущности (отчета или расписания), Cron — имя расписания
+// для изменений в связях отчетов с расписаниями.
+type Change struct {
+	Table  string `json:"table"`
+	Action string `json:"action"`
+	Name   string `json:"name"`
+	Cron   string `json:"cron"`
+}
+
+// ParseChange разбирает payload уведомления. Пустой payload отправляется
+// слушателем после переподключения, когда часть уведомлений могла потеряться.
+func ParseChange(payload string) (Change, error) {
+	var c Change
+
+	if payload == "" {
+		return c, nil
+	}
+
+	if err := json.Unmarshal([]byte(payload), &c); err != nil {
+		return Change{}, err
+	}
+
+	return c, nil
+}
+
+// IsFull сообщает, что по уведомлению нельзя определить затронутую сущность
+// и нужно перечитать все целиком.
+func (c Change) IsFull() bool {
+	return c.Name == "" && c.Cron == ""
+}
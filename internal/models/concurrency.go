@@ -0,0 +1,49 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ConcurrencyPolicy определяет, что делать с запуском отчета,
+// если такой же отчет уже генерируется.
+type ConcurrencyPolicy string
+
+const (
+	// ConcurrencySkip — пропустить новый запуск.
+	ConcurrencySkip ConcurrencyPolicy = "skip"
+	// ConcurrencyQueue — дождаться окончания текущего запуска.
+	ConcurrencyQueue ConcurrencyPolicy = "queue"
+	// ConcurrencyCancel — отменить текущий запуск и выполнить новый.
+	ConcurrencyCancel ConcurrencyPolicy = "cancel"
+)
+
+func ParseConcurrencyPolicy(s string) (ConcurrencyPolicy, error) {
+	switch p := ConcurrencyPolicy(s); p {
+	case ConcurrencySkip, ConcurrencyQueue, ConcurrencyCancel:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown concurrency policy %q", s)
+	}
+}
+
+// LockKey — ключ блокировки запуска: одинаковый для запусков одного отчета
+// на тот же набор получателей. Ручной запуск в личный чат не конфликтует с рассылкой.
+func (r Report) LockKey() string {
+	rcpts := make([]string, 0, len(r.Recipients))
+
+	for _, rc := range r.Recipients {
+		id := rc.Name
+		if rc.Chat != nil {
+			id = strconv.FormatInt(rc.Chat.ChatID, 10)
+		}
+
+		rcpts = append(rcpts, string(rc.Type)+":"+id)
+	}
+
+	slices.Sort(rcpts)
+
+	return r.Name + "|" + strings.Join(rcpts, ",")
+}
@@ -0,0 +1,36 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"support_bot/internal/models"
+)
+
+func TestParseConcurrencyPolicy(t *testing.T) {
+	t.Parallel()
+
+	p, err := models.ParseConcurrencyPolicy("cancel")
+	require.NoError(t, err)
+	assert.Equal(t, models.ConcurrencyCancel, p)
+
+	_, err = models.ParseConcurrencyPolicy("")
+	require.Error(t, err)
+}
+
+func TestReportLockKey(t *testing.T) {
+	t.Parallel()
+
+	mail := models.Recipient{Name: "mail", Type: "email"}
+	group := models.Recipient{Name: "group", Type: models.TelegramRecipient, Chat: &models.Chat{ChatID: -100}}
+	user := models.Recipient{Name: "SpetialTGRcpt", Type: models.TelegramRecipient, Chat: &models.Chat{ChatID: 42}}
+
+	scheduled := models.Report{Name: "daily", Recipients: []models.Recipient{mail, group}}
+	reordered := models.Report{Name: "daily", Recipients: []models.Recipient{group, mail}}
+	manual := models.Report{Name: "daily", Recipients: []models.Recipient{user}}
+
+	assert.Equal(t, scheduled.LockKey(), reordered.LockKey())
+	assert.NotEqual(t, scheduled.LockKey(), manual.LockKey())
+	assert.NotEqual(t, manual.LockKey(), models.Report{Name: "weekly", Recipients: manual.Recipients}.LockKey())
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"errors"
+	"time"
 
 	"github.com/robfig/cron/v3"
 )
@@ -16,9 +17,10 @@ type SheduleUnit struct {
 type CronVO string
 
 type Cron struct {
-	Cron        CronVO
-	Name        string
-	Description *string
+	ID          int     `db:"id"`
+	Cron        CronVO  `db:"cron"`
+	Name        string  `db:"name"`
+	Description *string `db:"description"`
 }
 
 var ErrInvalidCron = errors.New("invalid cron")
@@ -31,3 +33,64 @@ func NewCron(cronExpr string) (CronVO, error) {
 
 	return CronVO(cronExpr), nil
 }
+
+// DiffSchedules сравнивает запущенные задачи с загруженными из базы.
+// Задача с изменившимся cron-выражением или типом события попадает в оба списка:
+// ее нужно снять и поставить заново.
+func DiffSchedules(running, loaded []SheduleUnit) (add, remove []SheduleUnit) {
+	current := make(map[string]SheduleUnit, len(running))
+	for _, u := range running {
+		current[u.Name] = u
+	}
+
+	fresh := make(map[string]struct{}, len(loaded))
+
+	for _, u := range loaded {
+		fresh[u.Name] = struct{}{}
+
+		old, ok := current[u.Name]
+		if ok && old == u {
+			continue
+		}
+
+		if ok {
+			remove = append(remove, old)
+		}
+
+		add = append(add, u)
+	}
+
+	for _, u := range running {
+		if _, ok := fresh[u.Name]; !ok {
+			remove = append(remove, u)
+		}
+	}
+
+	return add, remove
+}
+
+// MissedFire возвращает последний пропущенный запуск расписания между last и now,
+// если он произошел не раньше чем window назад. Более старые пропуски не догоняются,
+// а из нескольких пропусков подряд выполняется только последний.
+func MissedFire(crontab string, last, now time.Time, window time.Duration) (time.Time, bool) {
+	if window <= 0 || last.IsZero() {
+		return time.Time{}, false
+	}
+
+	sch, err := cron.ParseStandard(crontab)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	var missed time.Time
+
+	for t := sch.Next(last); !t.IsZero() && !t.After(now); t = sch.Next(t) {
+		missed = t
+	}
+
+	if missed.IsZero() || now.Sub(missed) > window {
+		return time.Time{}, false
+	}
+
+	return missed, true
+}
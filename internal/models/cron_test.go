@@ -2,6 +2,7 @@ package models_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -62,3 +63,109 @@ func TestNewCron(t *testing.T) {
 		}
 	})
 }
+
+func TestDiffSchedules(t *testing.T) {
+	t.Parallel()
+
+	daily := models.SheduleUnit{Crontab: "0 9 * * *", Name: "daily"}
+	hourly := models.SheduleUnit{Crontab: "0 * * * *", Name: "hourly"}
+
+	t.Run("nothing changed", func(t *testing.T) {
+		t.Parallel()
+
+		add, remove := models.DiffSchedules(
+			[]models.SheduleUnit{daily, hourly},
+			[]models.SheduleUnit{hourly, daily},
+		)
+
+		assert.Empty(t, add)
+		assert.Empty(t, remove)
+	})
+
+	t.Run("new and removed jobs", func(t *testing.T) {
+		t.Parallel()
+
+		add, remove := models.DiffSchedules(
+			[]models.SheduleUnit{daily},
+			[]models.SheduleUnit{hourly},
+		)
+
+		assert.Equal(t, []models.SheduleUnit{hourly}, add)
+		assert.Equal(t, []models.SheduleUnit{daily}, remove)
+	})
+
+	t.Run("changed crontab", func(t *testing.T) {
+		t.Parallel()
+
+		changed := daily
+		changed.Crontab = "0 10 * * *"
+
+		add, remove := models.DiffSchedules(
+			[]models.SheduleUnit{daily, hourly},
+			[]models.SheduleUnit{changed, hourly},
+		)
+
+		assert.Equal(t, []models.SheduleUnit{changed}, add)
+		assert.Equal(t, []models.SheduleUnit{daily}, remove)
+	})
+}
+
+func TestMissedFire(t *testing.T) {
+	t.Parallel()
+
+	day := time.Date(2025, time.March, 10, 0, 0, 0, 0, time.UTC)
+
+	t.Run("missed within window", func(t *testing.T) {
+		t.Parallel()
+
+		last := day.Add(-15 * time.Hour) // вчера в 09:00
+		now := day.Add(9*time.Hour + 30*time.Minute)
+
+		missed, ok := models.MissedFire("0 9 * * *", last, now, time.Hour)
+
+		require.True(t, ok)
+		assert.Equal(t, day.Add(9*time.Hour), missed)
+	})
+
+	t.Run("missed outside window", func(t *testing.T) {
+		t.Parallel()
+
+		last := day.Add(-15 * time.Hour)
+		now := day.Add(12 * time.Hour)
+
+		_, ok := models.MissedFire("0 9 * * *", last, now, time.Hour)
+
+		assert.False(t, ok)
+	})
+
+	t.Run("only last missed fire", func(t *testing.T) {
+		t.Parallel()
+
+		last := day
+		now := day.Add(5*time.Hour + 10*time.Minute)
+
+		missed, ok := models.MissedFire("0 * * * *", last, now, time.Hour)
+
+		require.True(t, ok)
+		assert.Equal(t, day.Add(5*time.Hour), missed)
+	})
+
+	t.Run("nothing missed", func(t *testing.T) {
+		t.Parallel()
+
+		last := day.Add(9 * time.Hour)
+		now := day.Add(10 * time.Hour)
+
+		_, ok := models.MissedFire("0 9 * * *", last, now, time.Hour)
+
+		assert.False(t, ok)
+	})
+
+	t.Run("never fired", func(t *testing.T) {
+		t.Parallel()
+
+		_, ok := models.MissedFire("0 9 * * *", time.Time{}, day, time.Hour)
+
+		assert.False(t, ok)
+	})
+}
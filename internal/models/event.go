@@ -1,5 +1,10 @@
 package models
 
+import (
+	"slices"
+	"time"
+)
+
 type eventType int
 
 const (
@@ -11,6 +16,60 @@ const (
 type Event struct {
 	Name string
 	Type eventType
+	Run  RunInfo
+}
+
+// RunInfo описывает конкретный запуск отчета.
+// Доступен в text/html шаблонах по ключу run, например {{ if .run.CatchUp }}.
+type RunInfo struct {
+	// ScheduledAt — время, на которое был запланирован запуск.
+	ScheduledAt time.Time
+	// CatchUp — запуск пропущен во время простоя и выполняется с опозданием.
+	CatchUp bool
+	// Parent — отчет, после успешной отправки которого выполняется запуск.
+	Parent string
+	// ParentData — данные, собранные родителем, если зависимость передает данные.
+	ParentData map[string][]map[string]any
+	// Chain — родители от корневого запуска, защищает от циклов в зависимостях.
+	Chain []string
+	// Trigger — источник внешнего запуска (http, notify, bot). Пусто для запусков по расписанию.
+	Trigger string
+	// Params — параметры, переданные при внешнем запуске.
+	Params map[string]string
+	// Force — ручной запуск администратором: выполняется, даже если расписание
+	// или отчет на паузе. На зависимые отчеты не распространяется.
+	Force bool
+}
+
+// Chained возвращает информацию о запуске зависимого отчета d после отчета parent.
+// Возвращает false, если d уже встречается в цепочке запусков.
+func (r RunInfo) Chained(
+	parent string,
+	d Dependent,
+	data map[string][]map[string]any,
+) (RunInfo, bool) {
+	chain := make([]string, 0, len(r.Chain)+1)
+	chain = append(chain, r.Chain...)
+	chain = append(chain, parent)
+
+	if slices.Contains(chain, d.Name) {
+		return RunInfo{}, false
+	}
+
+	run := RunInfo{
+		ScheduledAt: r.ScheduledAt,
+		CatchUp:     r.CatchUp,
+		Parent:      parent,
+		Chain:       chain,
+		Trigger:     r.Trigger,
+		Params:      r.Params,
+	}
+
+	if d.PassData {
+		run.ParentData = data
+	}
+
+	return run, true
 }
 
 func NewEvent(name string, t int) Event {
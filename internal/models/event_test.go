@@ -0,0 +1,73 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"support_bot/internal/models"
+)
+
+func TestRunInfoChained(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	data := map[string][]map[string]any{"card": {{"v": 1}}}
+
+	t.Run("root run", func(t *testing.T) {
+		t.Parallel()
+
+		run, ok := models.RunInfo{ScheduledAt: at}.Chained(
+			"etl_check",
+			models.Dependent{Name: "summary"},
+			data,
+		)
+
+		assert.True(t, ok)
+		assert.Equal(t, at, run.ScheduledAt)
+		assert.Equal(t, "etl_check", run.Parent)
+		assert.Equal(t, []string{"etl_check"}, run.Chain)
+		assert.Nil(t, run.ParentData)
+	})
+
+	t.Run("pass data", func(t *testing.T) {
+		t.Parallel()
+
+		run, ok := models.RunInfo{}.Chained(
+			"etl_check",
+			models.Dependent{Name: "summary", PassData: true},
+			data,
+		)
+
+		assert.True(t, ok)
+		assert.Equal(t, data, run.ParentData)
+	})
+
+	t.Run("nested run does not share chain", func(t *testing.T) {
+		t.Parallel()
+
+		parent := models.RunInfo{Parent: "a", Chain: make([]string, 1, 4)}
+		parent.Chain[0] = "a"
+
+		first, ok := parent.Chained("b", models.Dependent{Name: "c"}, nil)
+		assert.True(t, ok)
+
+		second, ok := parent.Chained("b", models.Dependent{Name: "d"}, nil)
+		assert.True(t, ok)
+
+		assert.Equal(t, []string{"a", "b"}, first.Chain)
+		assert.Equal(t, []string{"a", "b"}, second.Chain)
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		t.Parallel()
+
+		run := models.RunInfo{Parent: "a", Chain: []string{"a"}}
+
+		_, ok := run.Chained("b", models.Dependent{Name: "a"}, nil)
+		assert.False(t, ok)
+
+		_, ok = run.Chained("b", models.Dependent{Name: "b"}, nil)
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrInviteInactive — приглашение уже использовано, отозвано или истекло.
+var ErrInviteInactive = errors.New("invite inactive")
+
+// InviteStatus — состояние приглашения.
+type InviteStatus string
+
+const (
+	InviteActive  InviteStatus = "active"
+	InviteUsed    InviteStatus = "used"
+	InviteRevoked InviteStatus = "revoked"
+	InviteExpired InviteStatus = "expired"
+)
+
+// UserGroup — группа пользователей, которой выдается доступ к отчетам.
+type UserGroup struct {
+	ID    int     `db:"id"`
+	Name  string  `db:"name"`
+	Title *string `db:"title"`
+}
+
+// DisplayName возвращает название группы или ее имя, если название не задано.
+func (g UserGroup) DisplayName() string {
+	if g.Title != nil && *g.Title != "" {
+		return *g.Title
+	}
+
+	return g.Name
+}
+
+// Invite — одноразовое приглашение в бота с заранее заданной ролью и группами.
+type Invite struct {
+	ID        int
+	Token     string
+	Role      string
+	CreatedBy string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+
+	UsedBy       *int64
+	UsedUsername *string
+	UsedAt       *time.Time
+
+	RevokedBy *string
+	RevokedAt *time.Time
+
+	// Groups — названия групп, в которые попадет пользователь.
+	Groups []string
+}
+
+// Status возвращает состояние приглашения на момент now.
+func (i Invite) Status(now time.Time) InviteStatus {
+	switch {
+	case i.UsedAt != nil:
+		return InviteUsed
+	case i.RevokedAt != nil:
+		return InviteRevoked
+	case !now.Before(i.ExpiresAt):
+		return InviteExpired
+	default:
+		return InviteActive
+	}
+}
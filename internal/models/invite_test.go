@@ -0,0 +1,52 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"support_bot/internal/models"
+)
+
+func TestInvite_Status(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	at := now.Add(-time.Hour)
+	by := "admin"
+
+	tests := []struct {
+		name   string
+		invite models.Invite
+		want   models.InviteStatus
+	}{
+		{
+			name:   "active",
+			invite: models.Invite{ExpiresAt: now.Add(time.Hour)},
+			want:   models.InviteActive,
+		},
+		{
+			name:   "expired",
+			invite: models.Invite{ExpiresAt: now},
+			want:   models.InviteExpired,
+		},
+		{
+			name:   "revoked",
+			invite: models.Invite{ExpiresAt: now.Add(time.Hour), RevokedAt: &at, RevokedBy: &by},
+			want:   models.InviteRevoked,
+		},
+		{
+			name:   "used after expiry stays used",
+			invite: models.Invite{ExpiresAt: now.Add(-time.Minute), UsedAt: &at},
+			want:   models.InviteUsed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, tt.invite.Status(now))
+		})
+	}
+}
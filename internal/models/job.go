@@ -0,0 +1,9 @@
+package models
+
+// Job — задача на генерацию отчета из очереди report_jobs.
+type Job struct {
+	ID          int64
+	Report      Report
+	Attempts    int
+	MaxAttempts int
+}
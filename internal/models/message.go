@@ -11,10 +11,31 @@ import (
 
 var errEmptyRecipient = errors.New("empty recipient")
 
+// TgLayout — оформление отчета в Telegram.
+type TgLayout struct {
+	// TextAsCaption — отправить текст подписью к первому изображению или документу.
+	TextAsCaption bool
+	// GroupDocuments — отправить документы одним альбомом.
+	GroupDocuments bool
+}
+
+// TgMediaOptions — параметры отправки файлов в Telegram.
+type TgMediaOptions struct {
+	// Caption — подпись в HTML к первому файлу.
+	Caption string
+	// Group — отправить документы альбомом. Изображения всегда уходят альбомом.
+	Group bool
+}
+
 type TgSender interface {
-	SendText(ctx context.Context, rcpt TgChat, s string) (*TgMessage, error)
-	SendDocument(ctx context.Context, rcpt TgChat, model []Data) ([]TgMessage, error)
-	SendMedia(ctx context.Context, rcpt TgChat, model []Data) ([]TgMessage, error)
+	SendText(ctx context.Context, rcpt TgChat, s string) ([]TgMessage, error)
+	SendDocument(ctx context.Context, rcpt TgChat, model []Data, opts TgMediaOptions) ([]TgMessage, error)
+	SendMedia(ctx context.Context, rcpt TgChat, model []Data, opts TgMediaOptions) ([]TgMessage, error)
+	EditText(ctx context.Context, msg TgMessage, s string) error
+	EditMedia(ctx context.Context, msg TgMessage, data Data, caption string) error
+	// Pin и Unpin сами пишут ошибку в лог: она не должна отменять отправку отчета.
+	Pin(ctx context.Context, msg TgMessage) error
+	Unpin(ctx context.Context, msg TgMessage) error
 }
 
 type SmbSender interface {
@@ -33,15 +54,19 @@ type senderProvider interface {
 
 type Message struct {
 	ReportName string
+	TgLayout   TgLayout
 
 	Recipients []Recipient
 
 	Text   []Data
 	Files  []Data
 	Images []Data
+
+	// Live — сообщения, которые отчет обновляет на месте, по чатам и топикам получателей.
+	Live map[TgChat]TgMessage
 }
 
-func NewMessage(rName string, data []Data, rcpts ...Recipient) *Message {
+func NewMessage(rName string, layout TgLayout, data []Data, rcpts ...Recipient) *Message {
 	var txt, fl, imgs []Data
 
 	for _, d := range data {
@@ -58,6 +83,7 @@ func NewMessage(rName string, data []Data, rcpts ...Recipient) *Message {
 
 	return &Message{
 		ReportName: rName,
+		TgLayout:   layout,
 		Recipients: rcpts,
 		Text:       txt,
 		Files:      fl,
@@ -74,15 +100,22 @@ func (m *Message) Send(ctx context.Context, sp senderProvider) ([]TgMessage, err
 	for _, r := range m.Recipients {
 		switch r.Type {
 		case TelegramRecipient:
-			msg, err := m.sendTg(ctx, sp.Tg(), r)
+			send := m.sendTg
+			if r.EditInPlace {
+				send = m.sendTgLive
+			}
+
+			msg, err := send(ctx, sp.Tg(), r)
 			if err != nil {
 				sendErr = errors.Join(sendErr, err)
-
-				continue
 			}
 
-			if r.NeedDeleteAfterEndOfDay {
-				tgMsg = append(tgMsg, msg...)
+			// Сохраняются обновляемые сообщения и те, что нужно удалить по политике хранения.
+			// Политика задается и обновляемым: она начнет действовать, когда сообщение заменят.
+			for _, mm := range msg {
+				if r.Retention.Apply(&mm) || mm.Live {
+					tgMsg = append(tgMsg, mm)
+				}
 			}
 		case emailRecipient:
 			err := m.sendSMTP(ctx, sp.SMTP(), r)
@@ -117,59 +150,176 @@ func (m *Message) sendTg(ctx context.Context, sender TgSender, r Recipient) ([]T
 
 	var sendErr error
 
+	// Отправленные сообщения возвращаются даже при ошибке, чтобы их можно было удалить.
 	var retMsg []TgMessage
 
-	if m.Text != nil {
-		for _, data := range m.Text {
+	texts := m.Text
+
+	var caption string
+
+	captioned := m.TgLayout.TextAsCaption && len(texts) > 0 && (len(m.Images) > 0 || len(m.Files) > 0)
+	if captioned {
+		caption, texts = texts[0].Data.String(), texts[1:]
+	}
+
+	sendTexts := func() {
+		for _, data := range texts {
 			msg, err := sender.SendText(ctx, rcpt, data.Data.String())
 			if err != nil {
 				sendErr = fmt.Errorf("sending text: %w", err)
-			} else {
-				retMsg = append(retMsg, TgMessage{
-					MessageID: msg.MessageID,
-					Time:      msg.Time,
-					ChatID:    msg.ChatID,
-					ThreadID:  msg.ThreadID,
-				})
 			}
+
+			retMsg = append(retMsg, msg...)
 		}
 	}
 
-	if m.Files != nil {
-		msg, err := sender.SendDocument(ctx, rcpt, m.Files)
+	sendFiles := func(caption string) {
+		opts := TgMediaOptions{Caption: caption, Group: m.TgLayout.GroupDocuments}
+
+		msg, err := sender.SendDocument(ctx, rcpt, m.Files, opts)
 		if err != nil {
 			sendErr = fmt.Errorf("sending document: %w", err)
-		} else {
-			for _, m := range msg {
-				retMsg = append(retMsg, TgMessage{
-					MessageID: m.MessageID,
-					Time:      m.Time,
-					ChatID:    m.ChatID,
-					ThreadID:  m.ThreadID,
-				})
-			}
 		}
+
+		retMsg = append(retMsg, msg...)
 	}
 
-	if m.Images != nil {
-		msg, err := sender.SendMedia(ctx, rcpt, m.Images)
+	sendImages := func(caption string) {
+		msg, err := sender.SendMedia(ctx, rcpt, m.Images, TgMediaOptions{Caption: caption})
 		if err != nil {
-			sendErr = fmt.Errorf("sending document: %w", err)
-		} else {
-			for _, m := range msg {
-				retMsg = append(retMsg, TgMessage{
-					MessageID: m.MessageID,
-					Time:      m.Time,
-					ChatID:    m.ChatID,
-					ThreadID:  m.ThreadID,
-				})
-			}
+			sendErr = fmt.Errorf("sending media: %w", err)
+		}
+
+		retMsg = append(retMsg, msg...)
+	}
+
+	// Подпись получает первое изображение, а если изображений нет — первый документ.
+	// Подписанные файлы отправляются первыми, до остальных текстов, чтобы первый
+	// текст оставался в начале отчета.
+	switch {
+	case !captioned:
+		sendTexts()
+
+		if len(m.Files) > 0 {
+			sendFiles("")
+		}
+
+		if len(m.Images) > 0 {
+			sendImages("")
 		}
+	case len(m.Images) > 0:
+		sendImages(caption)
+		sendTexts()
+
+		if len(m.Files) > 0 {
+			sendFiles("")
+		}
+	default:
+		sendFiles(caption)
+		sendTexts()
 	}
 
 	return retMsg, sendErr
 }
 
+// EditsInPlace сообщает, что у сообщения есть получатели, обновляемые на месте.
+func (m *Message) EditsInPlace() bool {
+	for _, r := range m.Recipients {
+		if r.Type == TelegramRecipient && r.EditInPlace {
+			return true
+		}
+	}
+
+	return false
+}
+
+// liveContent возвращает текст и файл, если отчет помещается в одно сообщение Telegram:
+// только текст или один файл с текстом в подписи.
+func (m *Message) liveContent() (string, *Data, bool) {
+	if len(m.Text) > 1 || len(m.Files)+len(m.Images) > 1 {
+		return "", nil, false
+	}
+
+	var text string
+	if len(m.Text) == 1 {
+		text = m.Text[0].Data.String()
+	}
+
+	switch {
+	case len(m.Images) == 1:
+		return text, &m.Images[0], true
+	case len(m.Files) == 1:
+		return text, &m.Files[0], true
+	default:
+		return text, nil, len(m.Text) == 1
+	}
+}
+
+// sendTgLive обновляет ранее отправленное получателю сообщение. Если сообщения еще нет
+// или обновить его не удалось, отправляет новое и закрепляет его, если это настроено.
+// Отчет, который не помещается в одно сообщение, отправляется как обычно.
+func (m *Message) sendTgLive(ctx context.Context, sender TgSender, r Recipient) ([]TgMessage, error) {
+	if r.Chat == nil {
+		return nil, errEmptyRecipient
+	}
+
+	text, file, ok := m.liveContent()
+	if !ok {
+		return m.sendTg(ctx, sender, r)
+	}
+
+	rcpt := TgChat{ChatID: r.Chat.ChatID, ThreadID: deRef(r.ThreadID, 0)}
+
+	prev, hasPrev := m.Live[rcpt]
+	if hasPrev {
+		var err error
+		if file != nil {
+			err = sender.EditMedia(ctx, prev, *file, text)
+		} else {
+			err = sender.EditText(ctx, prev, text)
+		}
+
+		if err == nil {
+			return nil, nil
+		}
+	}
+
+	var (
+		msg []TgMessage
+		err error
+	)
+
+	switch {
+	case file == nil:
+		msg, err = sender.SendText(ctx, rcpt, text)
+	case file.IsImage():
+		msg, err = sender.SendMedia(ctx, rcpt, []Data{*file}, TgMediaOptions{Caption: text})
+	default:
+		msg, err = sender.SendDocument(ctx, rcpt, []Data{*file}, TgMediaOptions{Caption: text})
+	}
+
+	if err != nil {
+		return msg, fmt.Errorf("sending live message: %w", err)
+	}
+
+	// Длинный текст может уйти несколькими сообщениями, обновлять такие на месте нельзя.
+	if len(msg) != 1 {
+		return msg, nil
+	}
+
+	msg[0].Live = true
+
+	if r.Pin {
+		if hasPrev {
+			_ = sender.Unpin(ctx, prev)
+		}
+
+		_ = sender.Pin(ctx, msg[0])
+	}
+
+	return msg, nil
+}
+
 func (m *Message) sendSMB(ctx context.Context, sender SmbSender, r Recipient) error {
 	var sendErr error
 
@@ -0,0 +1,212 @@
+package models_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"support_bot/internal/models"
+)
+
+// tgRecorder записывает вызовы отправки в Telegram.
+type tgRecorder struct {
+	calls   []string
+	editErr error
+}
+
+func (r *tgRecorder) SendText(_ context.Context, _ models.TgChat, s string) ([]models.TgMessage, error) {
+	r.calls = append(r.calls, "text:"+s)
+
+	return []models.TgMessage{{}}, nil
+}
+
+func (r *tgRecorder) SendDocument(
+	_ context.Context,
+	_ models.TgChat,
+	d []models.Data,
+	o models.TgMediaOptions,
+) ([]models.TgMessage, error) {
+	r.calls = append(r.calls, fmt.Sprintf("docs:%d caption=%q group=%v", len(d), o.Caption, o.Group))
+
+	return []models.TgMessage{{}}, nil
+}
+
+func (r *tgRecorder) SendMedia(
+	_ context.Context,
+	_ models.TgChat,
+	d []models.Data,
+	o models.TgMediaOptions,
+) ([]models.TgMessage, error) {
+	r.calls = append(r.calls, fmt.Sprintf("media:%d caption=%q", len(d), o.Caption))
+
+	return []models.TgMessage{{}}, nil
+}
+
+func (r *tgRecorder) EditText(_ context.Context, m models.TgMessage, s string) error {
+	r.calls = append(r.calls, fmt.Sprintf("edit:%d %s", m.MessageID, s))
+
+	return r.editErr
+}
+
+func (r *tgRecorder) EditMedia(_ context.Context, m models.TgMessage, d models.Data, caption string) error {
+	r.calls = append(r.calls, fmt.Sprintf("edit media:%d %s caption=%q", m.MessageID, d.Name, caption))
+
+	return r.editErr
+}
+
+func (r *tgRecorder) Pin(_ context.Context, m models.TgMessage) error {
+	r.calls = append(r.calls, fmt.Sprintf("pin:%d", m.MessageID))
+
+	return nil
+}
+
+func (r *tgRecorder) Unpin(_ context.Context, m models.TgMessage) error {
+	r.calls = append(r.calls, fmt.Sprintf("unpin:%d", m.MessageID))
+
+	return nil
+}
+
+func TestMessage_SendTgLayout(t *testing.T) {
+	t.Parallel()
+
+	img, err := models.NewImageData(bytes.NewBufferString("png"), "chart.png")
+	require.NoError(t, err)
+
+	doc, err := models.NewFileData(bytes.NewBufferString("csv"), "data.csv")
+	require.NoError(t, err)
+
+	txt := models.NewTextData(bytes.NewBufferString("итог"))
+	txt2 := models.NewTextData(bytes.NewBufferString("детали"))
+
+	rcpt := models.Recipient{Type: models.TelegramRecipient, Chat: &models.Chat{ChatID: 1}}
+
+	tests := []struct {
+		name   string
+		layout models.TgLayout
+		data   []models.Data
+		want   []string
+	}{
+		{
+			name: "separate",
+			data: []models.Data{txt, doc, doc, img},
+			want: []string{`text:итог`, `docs:2 caption="" group=false`, `media:1 caption=""`},
+		},
+		{
+			name:   "caption on image",
+			layout: models.TgLayout{TextAsCaption: true, GroupDocuments: true},
+			data:   []models.Data{txt, doc, doc, img},
+			want:   []string{`media:1 caption="итог"`, `docs:2 caption="" group=true`},
+		},
+		{
+			name:   "caption on document",
+			layout: models.TgLayout{TextAsCaption: true},
+			data:   []models.Data{txt, doc},
+			want:   []string{`docs:1 caption="итог" group=false`},
+		},
+		{
+			name:   "caption before other texts",
+			layout: models.TgLayout{TextAsCaption: true},
+			data:   []models.Data{txt, txt2, doc},
+			want:   []string{`docs:1 caption="итог" group=false`, `text:детали`},
+		},
+		{
+			name:   "text only",
+			layout: models.TgLayout{TextAsCaption: true},
+			data:   []models.Data{txt},
+			want:   []string{`text:итог`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tg := &tgRecorder{}
+			msg := models.NewMessage("report", tt.layout, tt.data, rcpt)
+
+			_, err := msg.Send(context.Background(), models.NewSenderProvider(tg, nil, nil))
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, tg.calls)
+		})
+	}
+}
+
+func TestMessage_SendTgLive(t *testing.T) {
+	t.Parallel()
+
+	img, err := models.NewImageData(bytes.NewBufferString("png"), "chart.png")
+	require.NoError(t, err)
+
+	txt := models.NewTextData(bytes.NewBufferString("статус"))
+
+	rcpt := models.Recipient{
+		Type:        models.TelegramRecipient,
+		Chat:        &models.Chat{ChatID: 1},
+		EditInPlace: true,
+		Pin:         true,
+	}
+	prev := map[models.TgChat]models.TgMessage{{ChatID: 1}: {MessageID: 7, ChatID: 1}}
+
+	tests := []struct {
+		name     string
+		data     []models.Data
+		live     map[models.TgChat]models.TgMessage
+		editErr  error
+		want     []string
+		wantLive int
+	}{
+		{
+			name:     "first send",
+			data:     []models.Data{txt},
+			want:     []string{`text:статус`, `pin:0`},
+			wantLive: 1,
+		},
+		{
+			name: "edit text",
+			data: []models.Data{txt},
+			live: prev,
+			want: []string{`edit:7 статус`},
+		},
+		{
+			name: "edit media",
+			data: []models.Data{txt, img},
+			live: prev,
+			want: []string{`edit media:7 chart.png caption="статус"`},
+		},
+		{
+			name:     "edit failed",
+			data:     []models.Data{txt},
+			live:     prev,
+			editErr:  errors.New("message to edit not found"),
+			want:     []string{`edit:7 статус`, `text:статус`, `unpin:7`, `pin:0`},
+			wantLive: 1,
+		},
+		{
+			name: "does not fit one message",
+			data: []models.Data{txt, img, img},
+			live: prev,
+			want: []string{`text:статус`, `media:2 caption=""`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tg := &tgRecorder{editErr: tt.editErr}
+			msg := models.NewMessage("report", models.TgLayout{}, tt.data, rcpt)
+			msg.Live = tt.live
+
+			sent, err := msg.Send(context.Background(), models.NewSenderProvider(tg, nil, nil))
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, tg.calls)
+			assert.Len(t, sent, tt.wantLive)
+		})
+	}
+}
@@ -55,3 +55,6 @@ func NewFileData(file *bytes.Buffer, name string) (Data, error) {
 }
 
 func (d Data) kind() sendKind { return d.Type }
+
+// IsImage сообщает, что данные отправляются в Telegram как изображение.
+func (d Data) IsImage() bool { return d.Type == sendImageKind }
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"strconv"
+	"time"
+)
+
+// RegistrationStatus — состояние заявки на регистрацию.
+type RegistrationStatus string
+
+const (
+	RegistrationPending  RegistrationStatus = "pending"
+	RegistrationApproved RegistrationStatus = "approved"
+	RegistrationDenied   RegistrationStatus = "denied"
+)
+
+// RegistrationRequest — заявка пользователя на доступ к боту.
+type RegistrationRequest struct {
+	ID         int                `db:"id"`
+	TelegramID int64              `db:"telegram_id"`
+	Username   *string            `db:"username"`
+	FirstName  *string            `db:"first_name"`
+	LastName   *string            `db:"last_name"`
+	Status     RegistrationStatus `db:"status"`
+	DecidedBy  *string            `db:"decided_by"`
+	CreatedAt  time.Time          `db:"created_at"`
+	DecidedAt  *time.Time         `db:"decided_at"`
+}
+
+// DisplayName возвращает @username или имя, если username не задан.
+func (r RegistrationRequest) DisplayName() string {
+	if r.Username != nil && *r.Username != "" {
+		return "@" + *r.Username
+	}
+
+	if r.FirstName != nil && *r.FirstName != "" {
+		return *r.FirstName
+	}
+
+	return "id " + strconv.FormatInt(r.TelegramID, 10)
+}
@@ -1,6 +1,9 @@
 package models
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"time"
+)
 
 type Report struct {
 	Name  string
@@ -10,11 +13,58 @@ type Report struct {
 	Recipients []Recipient
 	Exports    []Export
 	Evaluation string
+	Dependents []Dependent
+	// Concurrency — политика одновременных запусков. Пусто — политика по умолчанию.
+	Concurrency ConcurrencyPolicy
+	// Timeout — время на генерацию отчета. 0 — значение по умолчанию.
+	Timeout time.Duration
+	// Params — параметры, которые бот запрашивает перед ручным запуском.
+	Params []ReportParam
+	// TgLayout — оформление отчета в Telegram.
+	TgLayout TgLayout
+
+	Run RunInfo
+}
+
+// QueriesWithParams возвращает карточки отчета с параметрами текущего запуска.
+func (r Report) QueriesWithParams() []Card {
+	params := r.CardParams()
+	if len(params) == 0 {
+		return r.Queries
+	}
+
+	cards := make([]Card, 0, len(r.Queries))
+	for _, c := range r.Queries {
+		c.Params = params
+		cards = append(cards, c)
+	}
+
+	return cards
+}
+
+// IsHeavy сообщает, что отчет рендерится в pdf или png и обрабатывается отдельным пулом.
+func (r Report) IsHeavy() bool {
+	for _, e := range r.Exports {
+		if e.Format == ReportFormatPdf || e.Format == ReportFormatPng {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Dependent — отчет, который запускается после успешной отправки родительского.
+type Dependent struct {
+	Name string
+	// PassData — передать дочернему отчету данные, собранные родителем.
+	PassData bool
 }
 
 type Card struct {
 	CardUUID string `json:"card_uuid"`
 	Title    string `json:"title"`
+	// Params — параметры запроса карточки для текущего запуска.
+	Params []CardParam `json:"-"`
 }
 
 type RecipientType string
@@ -34,7 +84,12 @@ type Recipient struct {
 	Email      *EmailTemplate
 	Type       RecipientType
 
-	NeedDeleteAfterEndOfDay bool
+	// Retention — когда удалять отправленные получателю сообщения Telegram.
+	Retention Retention
+	// EditInPlace — обновлять ранее отправленное сообщение вместо отправки нового.
+	EditInPlace bool
+	// Pin — закрепить обновляемое сообщение.
+	Pin bool
 }
 
 type EmailTemplate struct {
@@ -0,0 +1,159 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	reportNameRe = regexp.MustCompile(`^[a-z][a-z0-9_]{2,63}$`)
+	cardUUIDRe   = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	cardTitleRe  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+var (
+	ErrInvalidReportName = errors.New("report name must be 3-64 lowercase latin letters, digits or _ and start with a letter")
+	ErrEmptyReportTitle  = errors.New("report title is empty")
+	ErrNoCards           = errors.New("at least one metabase card is required")
+	ErrNoFormats         = errors.New("at least one export format is required")
+	ErrNoRecipients      = errors.New("at least one recipient is required")
+)
+
+// templateFormats — форматы, которые рендерятся по шаблону отчета.
+var templateFormats = []string{ReportFormatText, ReportFormatHTML, ReportFormatPng, ReportFormatPdf}
+
+// ReportDraft — отчет, который создается или редактируется из админ-меню бота.
+type ReportDraft struct {
+	// ID — идентификатор отчета в базе, 0 для нового.
+	ID     int
+	Name   string
+	Title  string
+	Active bool
+	// Subscribable — чаты могут сами подписаться на отчет командой /subscribe.
+	Subscribable bool
+
+	Cards []Card
+	// Formats — выбранные форматы экспорта из export_formats.
+	Formats []string
+	// ChatIDs — идентификаторы чатов (chats.id), в которые отправляется отчет.
+	ChatIDs []int
+	// OtherRecipients — число получателей другого типа (почта, SMB),
+	// которые из бота не редактируются и сохраняются как есть.
+	OtherRecipients int
+	// Crontabs — расписания генерации отчета. Пусто — без расписания.
+	Crontabs   []string
+	Evaluation string
+	// HasTemplate — к отчету привязан шаблон в report_templates.
+	HasTemplate bool
+}
+
+func ValidateReportName(name string) error {
+	if !reportNameRe.MatchString(name) {
+		return ErrInvalidReportName
+	}
+
+	return nil
+}
+
+func ValidateReportTitle(title string) error {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return ErrEmptyReportTitle
+	}
+
+	if len([]rune(title)) > 255 {
+		return errors.New("report title is longer than 255 characters")
+	}
+
+	return nil
+}
+
+// ParseDraftCards разбирает карточки Metabase, по одной на строку: "<uuid> <ключ>".
+// Ключ используется в шаблонах и CEL-условии (report.<ключ>), поэтому должен быть идентификатором.
+func ParseDraftCards(text string) ([]Card, error) {
+	var cards []Card
+
+	seen := make(map[string]bool)
+
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: expected \"<uuid> <key>\"", i+1)
+		}
+
+		uuid, title := fields[0], fields[1]
+
+		if !cardUUIDRe.MatchString(uuid) {
+			return nil, fmt.Errorf("line %d: %q is not a public card uuid", i+1, uuid)
+		}
+
+		if !cardTitleRe.MatchString(title) {
+			return nil, fmt.Errorf("line %d: key %q must contain only latin letters, digits and _", i+1, title)
+		}
+
+		if seen[title] {
+			return nil, fmt.Errorf("line %d: duplicate key %q", i+1, title)
+		}
+
+		seen[title] = true
+
+		cards = append(cards, Card{CardUUID: strings.ToLower(uuid), Title: title})
+	}
+
+	if len(cards) == 0 {
+		return nil, ErrNoCards
+	}
+
+	return cards, nil
+}
+
+// Validate проверяет, что черновик можно сохранить.
+func (d ReportDraft) Validate() error {
+	if err := ValidateReportName(d.Name); err != nil {
+		return err
+	}
+
+	if err := ValidateReportTitle(d.Title); err != nil {
+		return err
+	}
+
+	if len(d.Cards) == 0 {
+		return ErrNoCards
+	}
+
+	if len(d.Formats) == 0 {
+		return ErrNoFormats
+	}
+
+	if len(d.ChatIDs) == 0 && d.OtherRecipients == 0 {
+		return ErrNoRecipients
+	}
+
+	for _, c := range d.Crontabs {
+		if _, err := NewCron(c); err != nil {
+			return fmt.Errorf("%w: %q", err, c)
+		}
+	}
+
+	return nil
+}
+
+// NeedsTemplate сообщает, что выбран формат, который рендерится по шаблону,
+// а шаблон к отчету не привязан.
+func (d ReportDraft) NeedsTemplate() bool {
+	if d.HasTemplate {
+		return false
+	}
+
+	return slices.ContainsFunc(d.Formats, func(f string) bool {
+		return slices.Contains(templateFormats, f)
+	})
+}
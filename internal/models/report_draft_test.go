@@ -0,0 +1,68 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"support_bot/internal/models"
+)
+
+func TestParseDraftCards(t *testing.T) {
+	t.Parallel()
+
+	cards, err := models.ParseDraftCards(`
+		6F1C2D3E-0000-4000-8000-000000000001 sales
+
+		6f1c2d3e-0000-4000-8000-000000000002 returns_total
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, []models.Card{
+		{CardUUID: "6f1c2d3e-0000-4000-8000-000000000001", Title: "sales"},
+		{CardUUID: "6f1c2d3e-0000-4000-8000-000000000002", Title: "returns_total"},
+	}, cards)
+
+	for _, text := range []string{
+		"",
+		"6f1c2d3e-0000-4000-8000-000000000001",
+		"not-a-uuid sales",
+		"6f1c2d3e-0000-4000-8000-000000000001 sales-total",
+		"6f1c2d3e-0000-4000-8000-000000000001 a\n6f1c2d3e-0000-4000-8000-000000000002 a",
+	} {
+		_, err := models.ParseDraftCards(text)
+		assert.Error(t, err, text)
+	}
+}
+
+func TestReportDraftValidate(t *testing.T) {
+	t.Parallel()
+
+	d := models.ReportDraft{
+		Name:     "daily_sales",
+		Title:    "Продажи за день",
+		Cards:    []models.Card{{CardUUID: "6f1c2d3e-0000-4000-8000-000000000001", Title: "sales"}},
+		Formats:  []string{models.ReportFormatXlsx},
+		ChatIDs:  []int{1},
+		Crontabs: []string{"0 9 * * 1-5"},
+	}
+	require.NoError(t, d.Validate())
+	assert.False(t, d.NeedsTemplate())
+
+	d.Formats = append(d.Formats, models.ReportFormatText)
+	assert.True(t, d.NeedsTemplate())
+
+	noRecipients := d
+	noRecipients.ChatIDs = nil
+	require.ErrorIs(t, noRecipients.Validate(), models.ErrNoRecipients)
+
+	noRecipients.OtherRecipients = 1
+	require.NoError(t, noRecipients.Validate())
+
+	badCron := d
+	badCron.Crontabs = []string{"every day"}
+	require.ErrorIs(t, badCron.Validate(), models.ErrInvalidCron)
+
+	badName := d
+	badName.Name = "Daily"
+	require.ErrorIs(t, badName.Validate(), models.ErrInvalidReportName)
+}
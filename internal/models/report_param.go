@@ -0,0 +1,136 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ParamType — вид параметра отчета, от него зависит, как бот запрашивает значение.
+type ParamType string
+
+const (
+	// ParamDate — одна дата, значение "2006-01-02".
+	ParamDate ParamType = "date"
+	// ParamDateRange — период, значение "2006-01-02~2006-01-02".
+	ParamDateRange ParamType = "date_range"
+	// ParamEnum — одно значение из списка Options.
+	ParamEnum ParamType = "enum"
+)
+
+// ParamDateLayout — формат дат в значениях параметров.
+const ParamDateLayout = "2006-01-02"
+
+// paramRangeSep разделяет начало и конец периода, как в параметрах Metabase.
+const paramRangeSep = "~"
+
+var ErrMissingParam = errors.New("report parameter is not set")
+
+// ReportParam — параметр, который бот запрашивает перед ручным запуском отчета.
+type ReportParam struct {
+	// Name — имя переменной в запросах Metabase и ключ в RunInfo.Params.
+	Name    string
+	Title   string
+	Type    ParamType
+	Options []string
+}
+
+// DateRange собирает значение параметра-периода.
+func DateRange(from, to time.Time) string {
+	return from.Format(ParamDateLayout) + paramRangeSep + to.Format(ParamDateLayout)
+}
+
+// Validate проверяет значение параметра.
+func (p ReportParam) Validate(value string) error {
+	switch p.Type {
+	case ParamDate:
+		if _, err := time.Parse(ParamDateLayout, value); err != nil {
+			return fmt.Errorf("param %s: invalid date %q", p.Name, value)
+		}
+	case ParamDateRange:
+		from, to, ok := strings.Cut(value, paramRangeSep)
+		if !ok {
+			return fmt.Errorf("param %s: invalid date range %q", p.Name, value)
+		}
+
+		f, err := time.Parse(ParamDateLayout, from)
+		if err != nil {
+			return fmt.Errorf("param %s: invalid date range %q", p.Name, value)
+		}
+
+		t, err := time.Parse(ParamDateLayout, to)
+		if err != nil || t.Before(f) {
+			return fmt.Errorf("param %s: invalid date range %q", p.Name, value)
+		}
+	case ParamEnum:
+		if !slices.Contains(p.Options, value) {
+			return fmt.Errorf("param %s: %q is not one of %v", p.Name, value, p.Options)
+		}
+	default:
+		return fmt.Errorf("param %s: unknown type %q", p.Name, p.Type)
+	}
+
+	return nil
+}
+
+// CardParam — параметр запроса публичной карточки Metabase.
+type CardParam struct {
+	Type   string `json:"type"`
+	Target []any  `json:"target"`
+	Value  any    `json:"value"`
+}
+
+// CardParam переводит значение параметра в формат Metabase.
+// Дата и список передаются в переменную запроса, период — в фильтр по полю,
+// потому что переменная не может хранить диапазон.
+func (p ReportParam) CardParam(value string) CardParam {
+	variable := []any{"variable", []any{"template-tag", p.Name}}
+
+	switch p.Type {
+	case ParamDate:
+		return CardParam{Type: "date/single", Target: variable, Value: value}
+	case ParamDateRange:
+		return CardParam{
+			Type:   "date/range",
+			Target: []any{"dimension", []any{"template-tag", p.Name}},
+			Value:  value,
+		}
+	default:
+		return CardParam{Type: "category", Target: variable, Value: value}
+	}
+}
+
+// CardParams собирает параметры Metabase из объявленных параметров отчета
+// и значений запуска. Параметры без значения не передаются.
+func (r Report) CardParams() []CardParam {
+	var params []CardParam
+
+	for _, p := range r.Params {
+		value, ok := r.Run.Params[p.Name]
+		if !ok {
+			continue
+		}
+
+		params = append(params, p.CardParam(value))
+	}
+
+	return params
+}
+
+// ValidateParams проверяет, что заданы и корректны значения всех параметров.
+func ValidateParams(params []ReportParam, values map[string]string) error {
+	for _, p := range params {
+		value, ok := values[p.Name]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrMissingParam, p.Name)
+		}
+
+		if err := p.Validate(value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
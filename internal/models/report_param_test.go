@@ -0,0 +1,54 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"support_bot/internal/models"
+)
+
+func TestReportParamValidate(t *testing.T) {
+	t.Parallel()
+
+	date := models.ReportParam{Name: "day", Type: models.ParamDate}
+	period := models.ReportParam{Name: "period", Type: models.ParamDateRange}
+	region := models.ReportParam{Name: "region", Type: models.ParamEnum, Options: []string{"north", "south"}}
+
+	require.NoError(t, date.Validate("2026-03-01"))
+	require.Error(t, date.Validate("01.03.2026"))
+
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, period.Validate(models.DateRange(from, from.AddDate(0, 0, 6))))
+	require.Error(t, period.Validate(models.DateRange(from, from.AddDate(0, 0, -1))))
+	require.Error(t, period.Validate("2026-03-01"))
+
+	require.NoError(t, region.Validate("south"))
+	require.Error(t, region.Validate("east"))
+
+	err := models.ValidateParams([]models.ReportParam{date, region}, map[string]string{"day": "2026-03-01"})
+	require.ErrorIs(t, err, models.ErrMissingParam)
+}
+
+func TestReportQueriesWithParams(t *testing.T) {
+	t.Parallel()
+
+	r := models.Report{
+		Queries: []models.Card{{CardUUID: "a", Title: "sales"}},
+		Params: []models.ReportParam{
+			{Name: "day", Type: models.ParamDate},
+			{Name: "period", Type: models.ParamDateRange},
+		},
+	}
+
+	assert.Nil(t, r.QueriesWithParams()[0].Params)
+
+	r.Run.Params = map[string]string{"period": "2026-03-01~2026-03-07"}
+
+	cards := r.QueriesWithParams()
+	require.Len(t, cards[0].Params, 1)
+	assert.Equal(t, "date/range", cards[0].Params[0].Type)
+	assert.Equal(t, "2026-03-01~2026-03-07", cards[0].Params[0].Value)
+	assert.Nil(t, r.Queries[0].Params)
+}
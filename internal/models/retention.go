@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// RetentionPolicy — политика хранения сообщений, отправленных получателю в Telegram.
+type RetentionPolicy string
+
+const (
+	// RetentionNever — не удалять сообщения.
+	RetentionNever RetentionPolicy = "never"
+	// RetentionAfterHours — удалять сообщения через Hours часов после отправки.
+	RetentionAfterHours RetentionPolicy = "after_hours"
+	// RetentionKeepLast — оставлять Keep последних отправок отчета в чате, остальные удалять.
+	RetentionKeepLast RetentionPolicy = "keep_last"
+)
+
+type Retention struct {
+	Policy RetentionPolicy
+	Hours  int
+	Keep   int
+}
+
+// Apply задает сообщению условие удаления по политике.
+// Возвращает false, если сообщение удалять не нужно.
+func (r Retention) Apply(msg *TgMessage) bool {
+	switch {
+	case r.Policy == RetentionAfterHours && r.Hours > 0:
+		at := msg.Time.Add(time.Duration(r.Hours) * time.Hour)
+		msg.DeleteAt = &at
+
+		return true
+	case r.Policy == RetentionKeepLast && r.Keep > 0:
+		keep := r.Keep
+		msg.KeepLast = &keep
+
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,63 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"support_bot/internal/models"
+)
+
+func TestRetention_Apply(t *testing.T) {
+	t.Parallel()
+
+	sent := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	deleteAt := sent.Add(6 * time.Hour)
+	keep := 3
+
+	tests := []struct {
+		name      string
+		retention models.Retention
+		want      bool
+		wantMsg   models.TgMessage
+	}{
+		{
+			name:      "never",
+			retention: models.Retention{Policy: models.RetentionNever},
+			wantMsg:   models.TgMessage{Time: sent},
+		},
+		{
+			name:      "empty",
+			retention: models.Retention{},
+			wantMsg:   models.TgMessage{Time: sent},
+		},
+		{
+			name:      "after hours",
+			retention: models.Retention{Policy: models.RetentionAfterHours, Hours: 6},
+			want:      true,
+			wantMsg:   models.TgMessage{Time: sent, DeleteAt: &deleteAt},
+		},
+		{
+			name:      "keep last",
+			retention: models.Retention{Policy: models.RetentionKeepLast, Keep: 3},
+			want:      true,
+			wantMsg:   models.TgMessage{Time: sent, KeepLast: &keep},
+		},
+		{
+			name:      "keep zero",
+			retention: models.Retention{Policy: models.RetentionKeepLast},
+			wantMsg:   models.TgMessage{Time: sent},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			msg := models.TgMessage{Time: sent}
+
+			assert.Equal(t, tt.want, tt.retention.Apply(&msg))
+			assert.Equal(t, tt.wantMsg, msg)
+		})
+	}
+}
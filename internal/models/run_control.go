@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// ControlTarget — вид объекта, запусками которого управляют вручную.
+type ControlTarget string
+
+const (
+	ControlCron   ControlTarget = "cron"
+	ControlReport ControlTarget = "report"
+)
+
+func ParseControlTarget(s string) (ControlTarget, bool) {
+	switch t := ControlTarget(s); t {
+	case ControlCron, ControlReport:
+		return t, true
+	default:
+		return "", false
+	}
+}
+
+// Admission — решение о выполнении очередного запуска.
+type Admission string
+
+const (
+	// AdmitRun — запуск выполняется.
+	AdmitRun Admission = ""
+	// AdmitPaused — запуск пропущен: объект на паузе.
+	AdmitPaused Admission = "paused"
+	// AdmitSkipped — запуск пропущен по флагу «пропустить следующий».
+	AdmitSkipped Admission = "skipped"
+)
+
+// RunControl — сохраненное состояние ручного управления запусками.
+type RunControl struct {
+	Target      ControlTarget `db:"target"`
+	Name        string        `db:"name"`
+	PausedUntil *time.Time    `db:"paused_until"`
+	SkipNext    bool          `db:"skip_next"`
+	UpdatedBy   *string       `db:"updated_by"`
+	UpdatedAt   time.Time     `db:"updated_at"`
+}
+
+// PausedAt сообщает, действует ли пауза в момент at.
+func (c RunControl) PausedAt(at time.Time) bool {
+	return c.PausedUntil != nil && c.PausedUntil.After(at)
+}
@@ -0,0 +1,23 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"support_bot/internal/models"
+)
+
+func TestRunControlPausedAt(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	until := now.Add(time.Hour)
+
+	assert.False(t, models.RunControl{}.PausedAt(now))
+	assert.True(t, models.RunControl{PausedUntil: &until}.PausedAt(now))
+	assert.False(t, models.RunControl{PausedUntil: &until}.PausedAt(until))
+
+	_, ok := models.ParseControlTarget("queue")
+	assert.False(t, ok)
+}
@@ -12,3 +12,38 @@ type LoadReportRPL struct {
 	CurrentPage  int
 	Reports      []ReportForTgLK
 }
+
+// ControlItem — расписание или отчет в меню управления запусками.
+type ControlItem struct {
+	ID        int
+	Name      string
+	Title     string
+	EventType int
+	Control   RunControl
+}
+
+type LoadControlRPL struct {
+	Target      ControlTarget
+	PageCount   int
+	CurrentPage int
+	Items       []ControlItem
+}
+
+// Subscription — отчет в меню подписок чата.
+type Subscription struct {
+	ReportID int
+	Name     string
+	Title    string
+	// ThreadID — топик, в который приходит отчет. 0 — чат целиком.
+	ThreadID int
+	// Subscribed — отчет приходит в текущий чат и топик.
+	Subscribed bool
+	// Subscribable — подписку можно оформить и отменить из бота.
+	Subscribable bool
+}
+
+type LoadSubscriptionRPL struct {
+	PageCount   int
+	CurrentPage int
+	Reports     []Subscription
+}
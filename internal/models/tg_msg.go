@@ -24,6 +24,14 @@ type TgMessage struct {
 	Title string `db:"title"`
 
 	Deleted bool `db:"deleted"`
+
+	// Live — сообщение обновляется отчетом на месте и не удаляется в конце дня.
+	Live bool `db:"live"`
+
+	// DeleteAt — когда удалить сообщение, KeepLast — сколько последних отправок отчета
+	// оставить в чате. Задаются политикой хранения получателя.
+	DeleteAt *time.Time `db:"delete_at"`
+	KeepLast *int       `db:"keep_last"`
 }
 
 func NewFromTelebot(msg *telebot.Message) *TgMessage {
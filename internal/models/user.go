@@ -21,6 +21,8 @@ type User struct {
 	FirstName  string  `db:"first_name"  json:"first_name"`
 	LastName   *string `db:"last_name"   json:"last_name"`
 	Role       string  `db:"role"        json:"role"` // admin или user или primary
+	// Placeholder — пользователь добавлен по username и еще не писал боту.
+	Placeholder bool `db:"is_placeholder" json:"is_placeholder"`
 }
 
 func NewUser(tgID int64, username, firstname string, lastname *string, isAdmin bool) User {
@@ -47,9 +49,10 @@ func NewEmptyUser(username string, isAdmin bool) User {
 	}
 
 	return User{
-		TelegramID: rand.Int64(),
-		Username:   username,
-		Role:       role,
+		TelegramID:  rand.Int64(),
+		Username:    username,
+		Role:        role,
+		Placeholder: true,
 	}
 }
 
@@ -3,6 +3,7 @@ package orchestrator
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"strings"
 
 	"support_bot/internal/models"
@@ -13,6 +14,11 @@ type report struct {
 	Name  string `db:"name"`
 	Title string `db:"title"`
 	Expr  string `db:"evaluation"`
+
+	ConcurrencyPolicy *string `db:"concurrency_policy"`
+	TimeoutSec        *int    `db:"timeout_sec"`
+	TgTextAsCaption   bool    `db:"tg_text_as_caption"`
+	TgGroupDocuments  bool    `db:"tg_group_documents"`
 }
 
 type card struct {
@@ -51,13 +57,17 @@ type recipient struct {
 	Body    *string `db:"body"`
 	Type    string  `db:"type"`
 
-	ChatID                  *int64  `db:"chat_id"`
-	ThreadID                *int    `db:"thread_id"`
-	ChatTitle               *string `db:"title"`
-	ChatType                *string `db:"chat_type"`
-	Description             *string `db:"description"`
-	IsActive                *bool   `db:"is_active"`
-	NeedDeleteAfterEndOfDay *bool   `db:"need_delete_after_end_of_day"`
+	ChatID          *int64  `db:"chat_id"`
+	ThreadID        *int    `db:"thread_id"`
+	ChatTitle       *string `db:"title"`
+	ChatType        *string `db:"chat_type"`
+	Description     *string `db:"description"`
+	IsActive        *bool   `db:"is_active"`
+	EditInPlace     bool    `db:"edit_in_place"`
+	Pin             bool    `db:"pin"`
+	RetentionPolicy string  `db:"retention_policy"`
+	RetentionHours  *int    `db:"retention_hours"`
+	RetentionKeep   *int    `db:"retention_keep"`
 }
 
 func deref[T any](t *T) T {
@@ -104,21 +114,21 @@ func mapRecipientToModel(r recipient) models.Recipient {
 		}
 	}
 
-	needDeleteAfterEndOfDay := false
-
-	if r.NeedDeleteAfterEndOfDay != nil {
-		needDeleteAfterEndOfDay = *r.NeedDeleteAfterEndOfDay
-	}
-
 	return models.Recipient{
-		Name:                    r.Name,
-		Config:                  r.Config,
-		RemotePath:              r.RemotePath,
-		Chat:                    c,
-		ThreadID:                r.ThreadID,
-		Email:                   e,
-		Type:                    models.RecipientType(r.Type),
-		NeedDeleteAfterEndOfDay: needDeleteAfterEndOfDay,
+		Name:        r.Name,
+		Config:      r.Config,
+		RemotePath:  r.RemotePath,
+		Chat:        c,
+		ThreadID:    r.ThreadID,
+		Email:       e,
+		Type:        models.RecipientType(r.Type),
+		EditInPlace: r.EditInPlace,
+		Pin:         r.Pin,
+		Retention: models.Retention{
+			Policy: models.RetentionPolicy(r.RetentionPolicy),
+			Hours:  deref(r.RetentionHours),
+			Keep:   deref(r.RetentionKeep),
+		},
 	}
 }
 
@@ -199,3 +209,49 @@ func mapExportsToModel(e ...export) ([]models.Export, error) {
 
 	return exprts, mapErr
 }
+
+type dependent struct {
+	Name     string `db:"name"`
+	PassData bool   `db:"pass_data"`
+}
+
+func mapDependentsToModel(d ...dependent) []models.Dependent {
+	var deps []models.Dependent
+
+	for _, dep := range d {
+		deps = append(deps, models.Dependent{
+			Name:     dep.Name,
+			PassData: dep.PassData,
+		})
+	}
+
+	return deps
+}
+
+type param struct {
+	Name    string          `db:"name"`
+	Title   string          `db:"title"`
+	Type    string          `db:"type"`
+	Options json.RawMessage `db:"options"`
+}
+
+func mapParamsToModel(p ...param) ([]models.ReportParam, error) {
+	var params []models.ReportParam
+
+	for _, prm := range p {
+		var options []string
+
+		if err := json.Unmarshal(prm.Options, &options); err != nil {
+			return nil, fmt.Errorf("param %s options: %w", prm.Name, err)
+		}
+
+		params = append(params, models.ReportParam{
+			Name:    prm.Name,
+			Title:   prm.Title,
+			Type:    models.ParamType(prm.Type),
+			Options: options,
+		})
+	}
+
+	return params, nil
+}
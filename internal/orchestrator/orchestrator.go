@@ -14,17 +14,44 @@ type ReportLoader interface {
 	LoadByEvent(ctx context.Context, event string, active bool) (*models2.Report, error)
 }
 
+// ReportQueue принимает собранные отчеты на генерацию.
+type ReportQueue interface {
+	Enqueue(ctx context.Context, report models2.Report) error
+}
+
+// RunGate решает, выполнять ли запуск отчета, с учетом паузы
+// и пропуска следующего запуска.
+type RunGate interface {
+	Admit(
+		ctx context.Context,
+		target models2.ControlTarget,
+		name string,
+		at time.Time,
+	) (models2.Admission, error)
+}
+
 type Orchestrator struct {
 	EventC        chan models2.Event
 	SpecialEventC chan models2.SpecialEventForLK
 
-	ReportC chan models2.Report
 	DeleteC chan models2.Event
 
-	rL ReportLoader
+	queue ReportQueue
+
+	rL   ReportLoader
+	gate RunGate
 
 	mu    sync.RWMutex
 	cache map[string][]models2.Report
+	// gen увеличивается при каждом сбросе кэша, чтобы загрузка,
+	// начатая до сброса, не положила в кэш устаревшие данные.
+	gen uint64
+
+	changes <-chan string
+
+	// stop закрывается в Stop, done — когда цикл обработки событий завершился.
+	stop chan struct{}
+	done chan struct{}
 
 	log *slog.Logger
 }
@@ -32,9 +59,11 @@ type Orchestrator struct {
 func New(
 	evC chan models2.Event,
 	specialEventC chan models2.SpecialEventForLK,
-	reportC chan models2.Report,
+	queue ReportQueue,
 	delC chan models2.Event,
 	rl ReportLoader,
+	gate RunGate,
+	changes <-chan string,
 	log *slog.Logger,
 ) *Orchestrator {
 	l := log.With(slog.Any("module", "orchestrator"))
@@ -43,10 +72,14 @@ func New(
 	return &Orchestrator{
 		EventC:        evC,
 		SpecialEventC: specialEventC,
-		ReportC:       reportC,
 		DeleteC:       delC,
+		queue:         queue,
 		rL:            rl,
+		gate:          gate,
 		cache:         cache,
+		changes:       changes,
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
 		log:           l,
 	}
 }
@@ -54,23 +87,63 @@ func New(
 func (o *Orchestrator) Start(ctx context.Context) {
 	o.log.InfoContext(ctx, "starting...")
 
-	go o.run(ctx)
+	go func() {
+		defer close(o.done)
 
-	o.cleaner(ctx)
+		o.run(ctx)
+	}()
+
+	o.invalidator(ctx)
+}
+
+// Stop перестает принимать события и ждет завершения обработки текущего.
+// Необработанные события остаются в каналах.
+func (o *Orchestrator) Stop(ctx context.Context) {
+	close(o.stop)
+
+	select {
+	case <-o.done:
+	case <-ctx.Done():
+		o.log.WarnContext(ctx, "orchestrator did not stop in time")
+	}
 }
 
-func (o *Orchestrator) reLoad() {
+// Flush сбрасывает кэш целиком.
+func (o *Orchestrator) Flush() {
 	o.mu.Lock()
 	clear(o.cache)
+	o.gen++
 	o.mu.Unlock()
 }
 
+// Invalidate сбрасывает записи кэша, затронутые изменением.
+// Изменения расписаний на описание отчета не влияют и пропускаются.
+func (o *Orchestrator) Invalidate(ctx context.Context, c models2.Change) {
+	if c.Name == "" {
+		o.Flush()
+		o.log.DebugContext(ctx, "cache flushed", slog.Any("change", c))
+
+		return
+	}
+
+	o.mu.Lock()
+	delete(o.cache, c.Name)
+	o.gen++
+	o.mu.Unlock()
+
+	o.log.DebugContext(ctx, "cache invalidated", slog.Any("report", c.Name))
+}
+
 func (o *Orchestrator) run(ctx context.Context) {
 	for {
 		select {
 		case <-ctx.Done():
 			o.log.InfoContext(ctx, "context cancelled. stopping")
 
+			return
+		case <-o.stop:
+			o.log.InfoContext(ctx, "stopped")
+
 			return
 		case event, ok := <-o.EventC:
 			if !ok {
@@ -84,7 +157,7 @@ func (o *Orchestrator) run(ctx context.Context) {
 				o.processDelReportEvent(ctx, event.Name)
 
 			default:
-				o.processGenReportEvent(ctx, event.Name)
+				o.processGenReportEvent(ctx, event)
 
 			}
 		case event, ok := <-o.SpecialEventC:
@@ -103,8 +176,8 @@ func (o *Orchestrator) run(ctx context.Context) {
 	}
 }
 
-func (o *Orchestrator) processGenReportEvent(ctx context.Context, event string) {
-	reports, err := o.getReportByEvent(ctx, event, true)
+func (o *Orchestrator) processGenReportEvent(ctx context.Context, event models2.Event) {
+	reports, err := o.getReportByEvent(ctx, event.Name, true)
 	if err != nil {
 		o.log.ErrorContext(ctx, "error loading report", slog.Any("error", err))
 
@@ -112,21 +185,55 @@ func (o *Orchestrator) processGenReportEvent(ctx context.Context, event string)
 	}
 
 	for _, report := range reports {
-		select {
-		case <-ctx.Done():
-			o.log.InfoContext(ctx, "context cancelled. stopping")
+		report.Run = event.Run
 
-			return
-		case o.ReportC <- report:
-			o.log.DebugContext(
-				ctx,
-				"sending report to generator",
-				slog.Any("report", report.Name),
-			)
+		if !o.admit(ctx, report) {
+			continue
 		}
+
+		o.enqueue(ctx, report)
 	}
 }
 
+// admit проверяет паузу и пропуск следующего запуска отчета.
+// Ручные запуски и запросы из личного кабинета не ограничиваются.
+// Если состояние прочитать не удалось, запуск выполняется.
+func (o *Orchestrator) admit(ctx context.Context, report models2.Report) bool {
+	if o.gate == nil || report.Run.Force {
+		return true
+	}
+
+	at := report.Run.ScheduledAt
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	adm, err := o.gate.Admit(ctx, models2.ControlReport, report.Name, at)
+	if err != nil {
+		o.log.WarnContext(
+			ctx,
+			"unable to check run control, running anyway",
+			slog.Any("report", report.Name),
+			slog.Any("error", err),
+		)
+
+		return true
+	}
+
+	if adm != models2.AdmitRun {
+		o.log.InfoContext(
+			ctx,
+			"report run skipped",
+			slog.Any("report", report.Name),
+			slog.Any("reason", adm),
+		)
+
+		return false
+	}
+
+	return true
+}
+
 func (o *Orchestrator) processGenReportSpecialEvent(
 	ctx context.Context,
 	event models2.SpecialEventForLK,
@@ -140,19 +247,25 @@ func (o *Orchestrator) processGenReportSpecialEvent(
 
 	for _, report := range reports {
 		report.Recipients = []models2.Recipient{event.Recipient}
-		select {
-		case <-ctx.Done():
-			o.log.InfoContext(ctx, "context cancelled. stopping")
+		report.Run = event.Event.Run
 
-			return
-		case o.ReportC <- report:
-			o.log.DebugContext(
-				ctx,
-				"sending report to generator",
-				slog.Any("report", report.Name),
-			)
-		}
+		o.enqueue(ctx, report)
+	}
+}
+
+func (o *Orchestrator) enqueue(ctx context.Context, report models2.Report) {
+	if err := o.queue.Enqueue(ctx, report); err != nil {
+		o.log.ErrorContext(
+			ctx,
+			"unable to enqueue report",
+			slog.Any("report", report.Name),
+			slog.Any("error", err),
+		)
+
+		return
 	}
+
+	o.log.DebugContext(ctx, "report enqueued", slog.Any("report", report.Name))
 }
 
 func (o *Orchestrator) processDelReportEvent(ctx context.Context, event string) {
@@ -166,19 +279,32 @@ func (o *Orchestrator) processDelReportEvent(ctx context.Context, event string)
 	}
 }
 
-func (o *Orchestrator) cleaner(ctx context.Context) {
-	tick := time.NewTicker(5 * time.Minute)
+func (o *Orchestrator) invalidator(ctx context.Context) {
+	if o.changes == nil {
+		return
+	}
 
 	go func() {
-		defer tick.Stop()
-
 		for {
 			select {
 			case <-ctx.Done():
 				return
-			case <-tick.C:
-				o.reLoad()
-				o.log.DebugContext(ctx, "cache cleaned")
+			case payload := <-o.changes:
+				c, err := models2.ParseChange(payload)
+				if err != nil {
+					o.log.WarnContext(
+						ctx,
+						"unable to parse change, flushing cache",
+						slog.Any("payload", payload),
+						slog.Any("error", err),
+					)
+				}
+
+				if c.Table == "crons" || c.Table == "report_crons" {
+					continue
+				}
+
+				o.Invalidate(ctx, c)
 			}
 		}
 	}()
@@ -202,6 +328,8 @@ func (o *Orchestrator) getReportByEvent(
 		return r, nil
 	}
 
+	gen := o.gen
+
 	o.mu.RUnlock()
 
 	l.DebugContext(ctx, "cache miss, loading report")
@@ -222,10 +350,8 @@ func (o *Orchestrator) getReportByEvent(
 	o.mu.Lock()
 	defer o.mu.Unlock()
 
-	if rp, ok := o.cache[event]; ok {
-		o.cache[event] = append(rp, *reports)
-	} else {
-		o.cache[event] = append([]models2.Report{}, *reports)
+	if gen == o.gen {
+		o.cache[event] = []models2.Report{*reports}
 	}
 
 	return []models2.Report{*reports}, nil
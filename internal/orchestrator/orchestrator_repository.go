@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"support_bot/internal/models"
@@ -121,7 +122,8 @@ func (o *Repository) loadReports(ctx context.Context, tx *sqlx.Tx) ([]report, er
 		return nil, fmt.Errorf("orchestrator load reports: %w", ctx.Err())
 	}
 
-	const query = `select r.id, r.name, r.title, e.expr as evaluation
+	const query = `select r.id, r.name, r.title, e.expr as evaluation, r.concurrency_policy, r.timeout_sec,
+       r.tg_text_as_caption, r.tg_group_documents
 from reports r
 left join evaluate e on e.id = r.eval_id
 where r.active = true
@@ -147,7 +149,8 @@ func (o *Repository) loadActiveReportByName(
 		return report{}, fmt.Errorf("orchestrator load report by name: %w", ctx.Err())
 	}
 
-	const query = `select r.id, r.name, r.title, e.expr as evaluation
+	const query = `select r.id, r.name, r.title, e.expr as evaluation, r.concurrency_policy, r.timeout_sec,
+       r.tg_text_as_caption, r.tg_group_documents
 from reports r
 left join evaluate e on e.id = r.eval_id
 where r.name = $1 and r.active = true
@@ -173,7 +176,8 @@ func (o *Repository) loadAnyReportByName(
 		return report{}, fmt.Errorf("orchestrator load report by name: %w", ctx.Err())
 	}
 
-	const query = `select r.id, r.name, r.title, e.expr as evaluation
+	const query = `select r.id, r.name, r.title, e.expr as evaluation, r.concurrency_policy, r.timeout_sec,
+       r.tg_text_as_caption, r.tg_group_documents
 from reports r
 left join evaluate e on e.id = r.eval_id
 where r.name = $1
@@ -229,7 +233,11 @@ select
     r.thread_id,
     r.email_id,
     r.type,
-    r.need_delete_after_end_of_day,
+    r.edit_in_place,
+    r.pin,
+    r.retention_policy,
+    r.retention_hours,
+    r.retention_keep,
 
 	e.dest,
 	e.copy,
@@ -286,6 +294,53 @@ where re.report_id = $1
 	return exprt, nil
 }
 
+func (o *Repository) loadDependents(
+	ctx context.Context,
+	reportID int,
+	tx *sqlx.Tx,
+) ([]dependent, error) {
+	const query = `
+select r.name, d.pass_data
+from report_dependencies d
+join reports r on r.id = d.dependent_id
+where d.report_id = $1
+;
+
+`
+
+	var deps []dependent
+
+	err := tx.SelectContext(ctx, &deps, query, reportID)
+	if err != nil {
+		return nil, err
+	}
+
+	return deps, nil
+}
+
+func (o *Repository) loadParams(
+	ctx context.Context,
+	reportID int,
+	tx *sqlx.Tx,
+) ([]param, error) {
+	const query = `
+select name, title, type, coalesce(array_to_json(options), '[]')::text as options
+from report_params
+where report_id = $1
+order by position, id
+;
+`
+
+	var params []param
+
+	err := tx.SelectContext(ctx, &params, query, reportID)
+	if err != nil {
+		return nil, err
+	}
+
+	return params, nil
+}
+
 func (o *Repository) getReportByID(
 	ctx context.Context,
 	r report,
@@ -323,6 +378,27 @@ func (o *Repository) getReportByID(
 		return nil, err
 	}
 
+	deps, err := o.loadDependents(ctx, r.ID, tx)
+	if err != nil {
+		o.log.ErrorContext(ctx, "error loading dependents for report", slog.Any("error", err))
+
+		return nil, err
+	}
+
+	prms, err := o.loadParams(ctx, r.ID, tx)
+	if err != nil {
+		o.log.ErrorContext(ctx, "error loading params for report", slog.Any("error", err))
+
+		return nil, err
+	}
+
+	mPrms, err := mapParamsToModel(prms...)
+	if err != nil {
+		o.log.ErrorContext(ctx, "error with map params", slog.Any("error", err))
+
+		return nil, err
+	}
+
 	return &models.Report{
 		Name:       r.Name,
 		Title:      r.Title,
@@ -330,5 +406,14 @@ func (o *Repository) getReportByID(
 		Recipients: mRcpts,
 		Exports:    mExprt,
 		Evaluation: r.Expr,
+		Dependents: mapDependentsToModel(deps...),
+
+		Concurrency: models.ConcurrencyPolicy(deref(r.ConcurrencyPolicy)),
+		Timeout:     time.Duration(deref(r.TimeoutSec)) * time.Second,
+		Params:      mPrms,
+		TgLayout: models.TgLayout{
+			TextAsCaption:  r.TgTextAsCaption,
+			GroupDocuments: r.TgGroupDocuments,
+		},
 	}, nil
 }
@@ -24,7 +24,7 @@ type Config struct {
 	Name     string `env:"DATABASE_NAME"     env-default:"postgres"  yaml:"name"     comment:"Имя базы данных"`
 	SSL      string `env:"DATABASE_SSL_MODE" env-default:"false"     yaml:"sslmode"  comment:"SSL режим: (disable|require|verify-ca|verify-full|allow|prefer)"`
 
-	MaxConns        int           `env:"DATABASE_MAX_CONNS"          env-default:"10"  yaml:"max_conns"          comment:"Максимально количество соединений"`
+	MaxConns        int           `env:"DATABASE_MAX_CONNS"          env-default:"20"  yaml:"max_conns"          comment:"Максимально количество соединений.\nНе меньше pipeline.light_workers + pipeline.heavy_workers + 6: выполняющийся отчет держит свое соединение."`
 	MaxIdleConns    int           `env:"DATABASE_MAX_IDLE_CONNS"     env-default:"2"   yaml:"max_idle_conns"     comment:"Максимальное количество ожидающий соединений"`
 	MaxConnLifeTime time.Duration `env:"DATABASE_MAX_CONN_LIFE_TIME" env-default:"30m" yaml:"max_conn_life_time" comment:"Максимальное время жизни одного соединения"`
 	MaxConnIdleTime time.Duration `env:"DATABASE_MAX_CONN_IDLE_TIME" env-default:"5m"  yaml:"max_conn_idle_time" comment:"Максимальное время ожидания соединения"`
@@ -0,0 +1,157 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Elector выбирает лидера среди нескольких экземпляров бота.
+// Лидерство — это session-level advisory lock, удерживаемый на выделенном соединении:
+// если соединение рвется, блокировка снимается сервером и ее забирает другой экземпляр.
+type Elector struct {
+	db *sqlx.DB
+
+	lockID        int64
+	retryInterval time.Duration
+	checkInterval time.Duration
+
+	log *slog.Logger
+}
+
+func NewElector(
+	db *sqlx.DB,
+	lockID int64,
+	retryInterval time.Duration,
+	checkInterval time.Duration,
+	log *slog.Logger,
+) *Elector {
+	l := log.With(slog.Any("module", "leader_elector"))
+
+	return &Elector{
+		db:            db,
+		lockID:        lockID,
+		retryInterval: retryInterval,
+		checkInterval: checkInterval,
+		log:           l,
+	}
+}
+
+// Run пытается стать лидером до отмены ctx. Получив блокировку, вызывает lead
+// с контекстом, который отменяется при потере блокировки, и дожидается возврата из lead
+// перед следующей попыткой.
+func (e *Elector) Run(ctx context.Context, lead func(ctx context.Context)) {
+	for {
+		conn, ok, err := e.acquire(ctx)
+		if err != nil {
+			e.log.WarnContext(ctx, "unable to acquire leader lock", slog.Any("error", err))
+		}
+
+		if ok {
+			e.log.InfoContext(ctx, "became leader", slog.Any("lock_id", e.lockID))
+
+			e.hold(ctx, conn, lead)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(e.retryInterval):
+		}
+	}
+}
+
+func (e *Elector) acquire(ctx context.Context) (*sql.Conn, bool, error) {
+	const query = `select pg_try_advisory_lock($1)`
+
+	conn, err := e.db.Conn(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("acquire conn: %w", err)
+	}
+
+	var locked bool
+
+	err = conn.QueryRowContext(ctx, query, e.lockID).Scan(&locked)
+	if err != nil || !locked {
+		//nolint:errcheck // conn is useless anyway
+		conn.Close()
+
+		return nil, false, err
+	}
+
+	return conn, true, nil
+}
+
+func (e *Elector) hold(ctx context.Context, conn *sql.Conn, lead func(ctx context.Context)) {
+	leadCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		lead(leadCtx)
+	}()
+
+	ticker := time.NewTicker(e.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			cancel()
+			<-done
+
+			e.release(conn)
+
+			return
+		case <-ticker.C:
+			pingCtx, pingCancel := context.WithTimeout(ctx, e.checkInterval)
+			err := conn.PingContext(pingCtx)
+
+			pingCancel()
+
+			if err == nil {
+				continue
+			}
+
+			e.log.ErrorContext(ctx, "leader lease lost", slog.Any("error", err))
+
+			cancel()
+			<-done
+
+			e.discard(conn)
+
+			return
+		}
+	}
+}
+
+func (e *Elector) release(conn *sql.Conn) {
+	const query = `select pg_advisory_unlock($1)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.checkInterval)
+	defer cancel()
+
+	if _, err := conn.ExecContext(ctx, query, e.lockID); err != nil {
+		e.log.WarnContext(ctx, "unable to release leader lock", slog.Any("error", err))
+	}
+
+	e.discard(conn)
+
+	e.log.InfoContext(ctx, "leadership released")
+}
+
+// discard закрывает соединение лидера, не возвращая его в пул: если блокировку снять
+// не удалось, сессия все еще держит ее, и другой экземпляр не станет лидером.
+func (e *Elector) discard(conn *sql.Conn) {
+	//nolint:errcheck // ErrBadConn is expected
+	conn.Raw(func(any) error { return driver.ErrBadConn })
+
+	//nolint:errcheck // conn is already removed from the pool
+	conn.Close()
+}
@@ -0,0 +1,215 @@
+package postgres
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/jmoiron/sqlx"
+)
+
+// Каналы LISTEN/NOTIFY, в которые пишут триггеры из migrations/init.sql.
+const (
+	ChannelCrons   = "crons_changed"
+	ChannelReports = "reports_changed"
+	ChannelJobs    = "report_jobs"
+	ChannelTrigger = "report_trigger"
+)
+
+const (
+	listenerBufferSize = 64
+	reconnectDelay     = 5 * time.Second
+)
+
+// Listener держит выделенное соединение с PostgreSQL и раздает уведомления подписчикам.
+// После переподключения всем подписчикам отправляется пустой payload,
+// так как уведомления за время простоя могли потеряться.
+type Listener struct {
+	db *sqlx.DB
+
+	mu   sync.Mutex
+	subs map[string][]*Subscription
+
+	log *slog.Logger
+}
+
+func NewListener(db *sqlx.DB, log *slog.Logger) *Listener {
+	l := log.With(slog.Any("module", "postgres_listener"))
+
+	return &Listener{
+		db:   db,
+		subs: make(map[string][]*Subscription),
+		log:  l,
+	}
+}
+
+// Subscription — подписка на канал, которую можно приостановить. Пока подписка
+// приостановлена, уведомления для нее отбрасываются, а не копятся в буфере.
+type Subscription struct {
+	c      chan string
+	active atomic.Bool
+}
+
+// C возвращает канал с уведомлениями.
+func (s *Subscription) C() <-chan string {
+	return s.c
+}
+
+// Resume возобновляет доставку уведомлений.
+func (s *Subscription) Resume() {
+	s.active.Store(true)
+}
+
+// Pause приостанавливает доставку и выбрасывает уже накопленные уведомления,
+// чтобы после Resume не обработать устаревшие.
+func (s *Subscription) Pause() {
+	s.active.Store(false)
+
+	for {
+		select {
+		case <-s.c:
+		default:
+			return
+		}
+	}
+}
+
+// Listen подписывается на канал. Подписываться нужно до вызова Start.
+func (l *Listener) Listen(channel string) <-chan string {
+	sub := l.Subscribe(channel)
+	sub.Resume()
+
+	return sub.C()
+}
+
+// Subscribe подписывается на канал, как Listen, но подписка создается приостановленной.
+// Так подписываются обработчики, работающие только на лидере.
+func (l *Listener) Subscribe(channel string) *Subscription {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	sub := &Subscription{c: make(chan string, listenerBufferSize)}
+	l.subs[channel] = append(l.subs[channel], sub)
+
+	return sub
+}
+
+func (l *Listener) Start(ctx context.Context) {
+	go func() {
+		first := true
+
+		for {
+			err := l.listen(ctx, !first)
+			if ctx.Err() != nil {
+				l.log.InfoContext(ctx, "listener stopped")
+
+				return
+			}
+
+			first = false
+
+			l.log.WarnContext(
+				ctx,
+				"listener connection lost, reconnecting",
+				slog.Any("error", err),
+				slog.Any("delay", reconnectDelay),
+			)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(reconnectDelay):
+			}
+		}
+	}()
+}
+
+func (l *Listener) listen(ctx context.Context, reconnected bool) error {
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire listener conn: %w", err)
+	}
+	defer conn.Close()
+
+	channels := l.channels()
+
+	// Соединение в режиме LISTEN нельзя возвращать в пул, поэтому всегда помечаем его как сломанное.
+	return conn.Raw(func(dc any) error {
+		sc, ok := dc.(*stdlib.Conn)
+		if !ok {
+			return errors.Join(fmt.Errorf("unexpected driver conn %T", dc), driver.ErrBadConn)
+		}
+
+		pc := sc.Conn()
+
+		for _, ch := range channels {
+			if _, err := pc.Exec(ctx, "listen "+pgx.Identifier{ch}.Sanitize()); err != nil {
+				return errors.Join(fmt.Errorf("listen %s: %w", ch, err), driver.ErrBadConn)
+			}
+		}
+
+		l.log.InfoContext(ctx, "listening", slog.Any("channels", channels))
+
+		if reconnected {
+			for _, ch := range channels {
+				l.dispatch(ctx, ch, "")
+			}
+		}
+
+		for {
+			n, err := pc.WaitForNotification(ctx)
+			if err != nil {
+				return errors.Join(fmt.Errorf("wait for notification: %w", err), driver.ErrBadConn)
+			}
+
+			l.log.DebugContext(
+				ctx,
+				"notification received",
+				slog.Any("channel", n.Channel),
+				slog.Any("payload", n.Payload),
+			)
+
+			l.dispatch(ctx, n.Channel, n.Payload)
+		}
+	})
+}
+
+func (l *Listener) channels() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	channels := make([]string, 0, len(l.subs))
+	for ch := range l.subs {
+		channels = append(channels, ch)
+	}
+
+	return channels
+}
+
+func (l *Listener) dispatch(ctx context.Context, channel, payload string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, sub := range l.subs[channel] {
+		if !sub.active.Load() {
+			continue
+		}
+
+		select {
+		case sub.c <- payload:
+		default:
+			l.log.WarnContext(
+				ctx,
+				"subscriber is too slow, notification dropped",
+				slog.Any("channel", channel),
+			)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	// reportLockSpace — первый ключ двухключевых advisory lock запусков отчетов.
+	// Пространство двухключевых блокировок не пересекается с блокировкой лидера.
+	reportLockSpace = 7305452
+	unlockTimeout   = 5 * time.Second
+)
+
+// Locker выдает advisory lock по строковому ключу. Блокировка удерживается
+// на выделенном соединении и снимается сервером, если экземпляр упал.
+type Locker struct {
+	db *sqlx.DB
+
+	log *slog.Logger
+}
+
+func NewLocker(db *sqlx.DB, log *slog.Logger) *Locker {
+	l := log.With(slog.Any("module", "postgres_locker"))
+
+	return &Locker{
+		db:  db,
+		log: l,
+	}
+}
+
+// TryLock пытается взять блокировку key без ожидания.
+// Если блокировка взята, release снимает ее и возвращает соединение в пул.
+func (l *Locker) TryLock(ctx context.Context, key string) (func(), bool, error) {
+	const query = `select pg_try_advisory_lock($1, hashtext($2))`
+
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("acquire conn: %w", err)
+	}
+
+	var locked bool
+
+	err = conn.QueryRowContext(ctx, query, reportLockSpace, key).Scan(&locked)
+	if err != nil || !locked {
+		//nolint:errcheck // conn is useless anyway
+		conn.Close()
+
+		if err != nil {
+			return nil, false, fmt.Errorf("try advisory lock: %w", err)
+		}
+
+		return nil, false, nil
+	}
+
+	release := func() {
+		const unlock = `select pg_advisory_unlock($1, hashtext($2))`
+
+		ctx, cancel := context.WithTimeout(context.Background(), unlockTimeout)
+		defer cancel()
+
+		if _, err := conn.ExecContext(ctx, unlock, reportLockSpace, key); err != nil {
+			l.log.WarnContext(ctx, "unable to release lock", slog.Any("key", key), slog.Any("error", err))
+
+			// Соединение с неснятой блокировкой нельзя возвращать в пул.
+			//nolint:errcheck // ErrBadConn is expected
+			conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+
+		if err := conn.Close(); err != nil {
+			l.log.WarnContext(ctx, "unable to close lock conn", slog.Any("error", err))
+		}
+	}
+
+	return release, true, nil
+}
@@ -0,0 +1,269 @@
+// Package queue реализует очередь задач на генерацию отчетов поверх PostgreSQL.
+package queue
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"support_bot/internal/models"
+)
+
+type Queue struct {
+	db *sqlx.DB
+
+	visibility  time.Duration
+	maxAttempts int
+	retryDelay  time.Duration
+	retention   time.Duration
+	owner       string
+
+	log *slog.Logger
+}
+
+func New(
+	db *sqlx.DB,
+	visibility time.Duration,
+	maxAttempts int,
+	retryDelay time.Duration,
+	retention time.Duration,
+	log *slog.Logger,
+) *Queue {
+	l := log.With(slog.Any("module", "report_queue"))
+
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+
+	return &Queue{
+		db:          db,
+		visibility:  visibility,
+		maxAttempts: maxAttempts,
+		retryDelay:  retryDelay,
+		retention:   retention,
+		owner:       host + ":" + strconv.Itoa(os.Getpid()),
+		log:         l,
+	}
+}
+
+// Enqueue сохраняет снимок отчета как новую задачу.
+func (q *Queue) Enqueue(ctx context.Context, report models.Report) error {
+	const query = `with j as (
+    insert into report_jobs(report_name, lock_key, heavy, payload, max_attempts)
+    values ($1, $2, $3, $4, $5)
+    returning heavy
+)
+select pg_notify('report_jobs', case when j.heavy then 'heavy' else 'light' end) from j;`
+
+	payload, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("marshal report: %w", err)
+	}
+
+	if _, err := q.db.ExecContext(
+		ctx,
+		query,
+		report.Name,
+		report.LockKey(),
+		report.IsHeavy(),
+		payload,
+		q.maxAttempts,
+	); err != nil {
+		return fmt.Errorf("enqueue report job: %w", err)
+	}
+
+	return nil
+}
+
+// Dequeue забирает следующую доступную задачу тяжелого или легкого пула
+// и продлевает ее видимость на visibility. Если задач нет, возвращает nil, nil.
+func (q *Queue) Dequeue(ctx context.Context, heavy bool) (*models.Job, error) {
+	const query = `update report_jobs
+set state      = 'running',
+    attempts   = attempts + 1,
+    visible_at = now() + make_interval(secs => $1),
+    started_at = now(),
+    locked_by  = $2
+where id = (
+    select id
+    from report_jobs
+    where state in ('queued', 'running')
+      and visible_at <= now()
+      and attempts < max_attempts
+      and heavy = $3
+    order by visible_at, id
+    for update skip locked
+    limit 1
+)
+returning id, payload, attempts, max_attempts;`
+
+	var row struct {
+		ID          int64           `db:"id"`
+		Payload     json.RawMessage `db:"payload"`
+		Attempts    int             `db:"attempts"`
+		MaxAttempts int             `db:"max_attempts"`
+	}
+
+	err := q.db.GetContext(ctx, &row, query, q.visibility.Seconds(), q.owner, heavy)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("dequeue report job: %w", err)
+	}
+
+	job := &models.Job{
+		ID:          row.ID,
+		Attempts:    row.Attempts,
+		MaxAttempts: row.MaxAttempts,
+	}
+
+	if err := json.Unmarshal(row.Payload, &job.Report); err != nil {
+		failErr := q.Fail(ctx, job, err)
+
+		return nil, errors.Join(fmt.Errorf("unmarshal job %d: %w", row.ID, err), failErr)
+	}
+
+	return job, nil
+}
+
+// Extend продлевает видимость выполняющейся задачи, чтобы ее не забрал другой экземпляр.
+// Возвращает false, если задача больше не выполняется, например отменена более новым запуском.
+func (q *Queue) Extend(ctx context.Context, id int64) (bool, error) {
+	const query = `update report_jobs
+set visible_at = now() + make_interval(secs => $2)
+where id = $1 and state = 'running';`
+
+	res, err := q.db.ExecContext(ctx, query, id, q.visibility.Seconds())
+	if err != nil {
+		return false, fmt.Errorf("extend report job: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("extend report job: %w", err)
+	}
+
+	return n > 0, nil
+}
+
+func (q *Queue) Complete(ctx context.Context, id int64) error {
+	const query = `update report_jobs
+set state = 'done', finished_at = now(), error = null
+where id = $1 and state = 'running';`
+
+	if _, err := q.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("complete report job: %w", err)
+	}
+
+	return nil
+}
+
+// Skip завершает задачу без выполнения.
+func (q *Queue) Skip(ctx context.Context, id int64, reason string) error {
+	const query = `update report_jobs
+set state = 'skipped', finished_at = now(), error = $2
+where id = $1 and state = 'running';`
+
+	if _, err := q.db.ExecContext(ctx, query, id, reason); err != nil {
+		return fmt.Errorf("skip report job: %w", err)
+	}
+
+	return nil
+}
+
+// Defer возвращает задачу в очередь через delay, не расходуя попытку.
+func (q *Queue) Defer(ctx context.Context, id int64, delay time.Duration) error {
+	const query = `update report_jobs
+set state      = 'queued',
+    attempts   = greatest(attempts - 1, 0),
+    visible_at = now() + make_interval(secs => $2)
+where id = $1 and state = 'running';`
+
+	if _, err := q.db.ExecContext(ctx, query, id, delay.Seconds()); err != nil {
+		return fmt.Errorf("defer report job: %w", err)
+	}
+
+	return nil
+}
+
+// Cancel помечает отмененными выполняющиеся задачи с ключом lockKey, кроме задачи by.
+// Обработчики отмененных задач узнают об этом при следующем продлении видимости.
+func (q *Queue) Cancel(ctx context.Context, lockKey string, by int64) (int64, error) {
+	const query = `update report_jobs
+set state = 'cancelled', finished_at = now(), error = 'cancelled by job ' || $2::bigint
+where lock_key = $1 and state = 'running' and id <> $2::bigint;`
+
+	res, err := q.db.ExecContext(ctx, query, lockKey, by)
+	if err != nil {
+		return 0, fmt.Errorf("cancel report jobs: %w", err)
+	}
+
+	return res.RowsAffected()
+}
+
+// Fail возвращает задачу в очередь с задержкой retryDelay
+// или помечает ее проваленной, если попытки исчерпаны.
+func (q *Queue) Fail(ctx context.Context, job *models.Job, cause error) error {
+	const query = `update report_jobs
+set state       = case when attempts < max_attempts then 'queued' else 'failed' end,
+    visible_at  = now() + make_interval(secs => $3),
+    finished_at = case when attempts < max_attempts then null else now() end,
+    error       = $2
+where id = $1 and state = 'running';`
+
+	if _, err := q.db.ExecContext(ctx, query, job.ID, cause.Error(), q.retryDelay.Seconds()); err != nil {
+		return fmt.Errorf("fail report job: %w", err)
+	}
+
+	return nil
+}
+
+// Reap помечает проваленными задачи, которые зависли в running после последней попытки,
+// и удаляет завершенные задачи старше retention.
+func (q *Queue) Reap(ctx context.Context) error {
+	const (
+		expireQuery = `update report_jobs
+set state = 'failed', finished_at = now(), error = 'visibility timeout exceeded'
+where state = 'running' and visible_at <= now() and attempts >= max_attempts;`
+		purgeQuery = `delete from report_jobs
+where state in ('done', 'failed', 'skipped', 'cancelled') and finished_at < now() - make_interval(secs => $1);`
+	)
+
+	res, err := q.db.ExecContext(ctx, expireQuery)
+	if err != nil {
+		return fmt.Errorf("expire report jobs: %w", err)
+	}
+
+	if n, _ := res.RowsAffected(); n > 0 {
+		q.log.WarnContext(ctx, "report jobs expired", slog.Any("count", n))
+	}
+
+	if q.retention <= 0 {
+		return nil
+	}
+
+	res, err = q.db.ExecContext(ctx, purgeQuery, q.retention.Seconds())
+	if err != nil {
+		return fmt.Errorf("purge report jobs: %w", err)
+	}
+
+	if n, _ := res.RowsAffected(); n > 0 {
+		q.log.DebugContext(ctx, "finished report jobs purged", slog.Any("count", n))
+	}
+
+	return nil
+}
@@ -1,39 +1,71 @@
 package sheduler
 
-type SheduleAPIEvent string
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// ControlChannel — канал NOTIFY, по которому команды управления рассылками доходят
+// до лидера: в режиме webhook обработчики бота работают на каждом экземпляре,
+// а планировщик — только на лидере.
+const ControlChannel = "scheduler_control"
+
+type controlAction string
 
 const (
-	eventStart SheduleAPIEvent = "start"
-	eventStop  SheduleAPIEvent = "stop"
+	actionStart controlAction = "start"
+	actionStop  controlAction = "stop"
+	actionRun   controlAction = "run"
 )
 
-type SheduleAPI struct {
-	c chan SheduleAPIEvent
+// controlCommand — команда планировщику в payload уведомления.
+type controlCommand struct {
+	Action    controlAction `json:"action"`
+	Name      string        `json:"name,omitempty"`
+	EventType int           `json:"event_type,omitempty"`
+	Source    string        `json:"source,omitempty"`
 }
 
-func NewSheduleAPI(c chan SheduleAPIEvent) *SheduleAPI {
-	return &SheduleAPI{c: c}
+// Notifier отправляет уведомление в канал PostgreSQL NOTIFY.
+type Notifier interface {
+	Notify(ctx context.Context, channel, payload string) error
 }
 
-func (sha *SheduleAPI) Start() {
-	defer func() { recover() }()
-
-	sha.c <- eventStart
+type SheduleAPI struct {
+	notifier Notifier
 }
 
-func (sha *SheduleAPI) Stop() {
-	defer func() { recover() }()
+func NewSheduleAPI(notifier Notifier) *SheduleAPI {
+	return &SheduleAPI{notifier: notifier}
+}
 
-	sha.c <- eventStop
+// Start перезапускает cron-рассылки на лидере.
+func (sha *SheduleAPI) Start(ctx context.Context) error {
+	return sha.send(ctx, controlCommand{Action: actionStart})
 }
 
-func (sha *SheduleAPI) restart() {
-	defer func() { recover() }()
+// Stop останавливает cron-рассылки на лидере.
+func (sha *SheduleAPI) Stop(ctx context.Context) error {
+	return sha.send(ctx, controlCommand{Action: actionStop})
+}
 
-	sha.Stop()
-	sha.Start()
+// RunNow запускает расписание name вне очереди, не дожидаясь cron и не учитывая паузу.
+// Время последнего срабатывания при этом не меняется.
+func (sha *SheduleAPI) RunNow(ctx context.Context, name string, eventType int, source string) error {
+	return sha.send(ctx, controlCommand{
+		Action:    actionRun,
+		Name:      name,
+		EventType: eventType,
+		Source:    source,
+	})
 }
 
-func (sha *SheduleAPI) stopAPI() {
-	close(sha.c)
+func (sha *SheduleAPI) send(ctx context.Context, cmd controlCommand) error {
+	payload, err := json.Marshal(cmd)
+	if err != nil {
+		return fmt.Errorf("marshal scheduler command: %w", err)
+	}
+
+	return sha.notifier.Notify(ctx, ControlChannel, string(payload))
 }
@@ -2,7 +2,9 @@ package sheduler
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"support_bot/internal/models"
@@ -38,3 +40,48 @@ func (s *SheduleRepo) Load(ctx context.Context) ([]models.SheduleUnit, error) {
 
 	return units, nil
 }
+
+func (s *SheduleRepo) LastFires(ctx context.Context) (map[string]time.Time, error) {
+	const query string = `select cron_name, last_fire_at from cron_runs`
+
+	var rows []struct {
+		Name string    `db:"cron_name"`
+		At   time.Time `db:"last_fire_at"`
+	}
+
+	err := s.db.SelectContext(ctx, &rows, query)
+	if err != nil {
+		return nil, fmt.Errorf("load last fires: %w", err)
+	}
+
+	fires := make(map[string]time.Time, len(rows))
+	for _, r := range rows {
+		fires[r.Name] = r.At
+	}
+
+	return fires, nil
+}
+
+func (s *SheduleRepo) SaveFire(ctx context.Context, name string, at time.Time) error {
+	const query string = `insert into cron_runs(cron_name, last_fire_at) values ($1, $2)
+on conflict (cron_name) do update set last_fire_at = greatest(cron_runs.last_fire_at, excluded.last_fire_at)`
+
+	_, err := s.db.ExecContext(ctx, query, name, at)
+	if err != nil {
+		return fmt.Errorf("save fire: %w", err)
+	}
+
+	return nil
+}
+
+// Notify отправляет уведомление payload в канал channel.
+func (s *SheduleRepo) Notify(ctx context.Context, channel, payload string) error {
+	const query string = `select pg_notify($1, $2)`
+
+	_, err := s.db.ExecContext(ctx, query, channel, payload)
+	if err != nil {
+		return fmt.Errorf("notify %s: %w", channel, err)
+	}
+
+	return nil
+}
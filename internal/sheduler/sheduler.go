@@ -2,7 +2,10 @@ package sheduler
 
 import (
 	"context"
+	"encoding/json"
 	"log/slog"
+	"sync"
+	"time"
 
 	"github.com/robfig/cron/v3"
 	models2 "support_bot/internal/models"
@@ -12,38 +15,131 @@ type SheduleLoader interface {
 	Load(ctx context.Context) ([]models2.SheduleUnit, error)
 }
 
+// FireStore хранит время последнего срабатывания расписаний.
+type FireStore interface {
+	LastFires(ctx context.Context) (map[string]time.Time, error)
+	SaveFire(ctx context.Context, name string, at time.Time) error
+}
+
+// RunGate решает, выполнять ли запуск расписания, с учетом паузы
+// и пропуска следующего запуска.
+type RunGate interface {
+	Admit(
+		ctx context.Context,
+		target models2.ControlTarget,
+		name string,
+		at time.Time,
+	) (models2.Admission, error)
+}
+
+const (
+	saveFireTimeout = 5 * time.Second
+	// emitTimeout ограничивает ожидание, если EventCreator не успевает разбирать события.
+	emitTimeout = time.Minute
+)
+
+type entry struct {
+	unit models2.SheduleUnit
+	id   cron.EntryID
+}
+
 type Sheduler struct {
-	cron   *cron.Cron
+	mu      sync.Mutex
+	cron    *cron.Cron
+	entries map[string]entry
+	running bool
+
 	log    *slog.Logger
 	loader SheduleLoader
+	fires  FireStore
+	gate   RunGate
 
 	EventChan chan models2.Event
 
-	api chan SheduleAPIEvent
+	// control — команды SheduleAPI из канала ControlChannel.
+	control <-chan string
+	changes <-chan string
+
+	pollInterval  time.Duration
+	catchUpWindow time.Duration
 }
 
 func NewSheduler(
 	shLoader SheduleLoader,
+	fires FireStore,
+	gate RunGate,
 	log *slog.Logger,
 	events chan models2.Event,
-	apiChan chan SheduleAPIEvent,
+	control <-chan string,
+	changes <-chan string,
+	pollInterval time.Duration,
+	catchUpWindow time.Duration,
 ) *Sheduler {
 	l := log.With(slog.Any("module", "sheduler"))
 
 	return &Sheduler{
-		cron:      cron.New(),
-		log:       l,
-		loader:    shLoader,
-		EventChan: events,
-		api:       apiChan,
+		cron:          cron.New(),
+		entries:       make(map[string]entry),
+		log:           l,
+		loader:        shLoader,
+		fires:         fires,
+		gate:          gate,
+		EventChan:     events,
+		control:       control,
+		changes:       changes,
+		pollInterval:  pollInterval,
+		catchUpWindow: catchUpWindow,
 	}
 }
 
 func (s *Sheduler) Start(ctx context.Context) error {
+	s.log.InfoContext(ctx, "Starting")
+
+	if err := s.restart(ctx); err != nil {
+		return err
+	}
+
+	s.catchUp(ctx)
+	s.startMonitor(ctx)
+
+	return nil
+}
+
+func (s *Sheduler) Stop() {
+	s.log.Info("stoping sheduler")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cron.Stop()
+	s.running = false
+}
+
+// restart полностью пересоздает cron и заново ставит все задачи.
+func (s *Sheduler) restart(ctx context.Context) error {
+	s.mu.Lock()
 	s.cron.Stop()
 	s.cron = cron.New()
-	s.log.InfoContext(ctx, "Starting")
+	s.entries = make(map[string]entry)
+	s.running = false
+	s.mu.Unlock()
+
+	if err := s.Reload(ctx); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.cron.Start()
+	s.running = true
+	s.mu.Unlock()
+
+	s.log.InfoContext(ctx, "Scheduler started")
 
+	return nil
+}
+
+// Reload перечитывает расписания и снимает/ставит только изменившиеся задачи.
+func (s *Sheduler) Reload(ctx context.Context) error {
 	units, err := s.loader.Load(ctx)
 	if err != nil {
 		s.log.ErrorContext(ctx, "Error while loading shedule", slog.Any("error", err))
@@ -51,57 +147,308 @@ func (s *Sheduler) Start(ctx context.Context) error {
 		return err
 	}
 
-	for _, u := range units {
-		entry, err := s.cron.AddFunc(u.Crontab, func() {
-			go func() {
-				s.log.Debug("cron job executed", slog.Any("job_name", u.Name))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	running := make([]models2.SheduleUnit, 0, len(s.entries))
+	for _, e := range s.entries {
+		running = append(running, e.unit)
+	}
+
+	add, remove := models2.DiffSchedules(running, units)
+
+	for _, u := range remove {
+		e, ok := s.entries[u.Name]
+		if !ok {
+			continue
+		}
+
+		s.cron.Remove(e.id)
+		delete(s.entries, u.Name)
 
-				s.EventChan <- models2.NewEvent(u.Name, u.EventType)
-			}()
-		})
+		s.log.InfoContext(ctx, "Removed job", slog.Any("job", u))
+	}
+
+	for _, u := range add {
+		id, err := s.cron.AddFunc(u.Crontab, s.fire(u))
 		if err != nil {
 			s.log.ErrorContext(ctx, "Error start job", slog.Any("job", u), slog.Any("error", err))
 
 			continue
 		}
 
-		s.log.InfoContext(ctx, "Started job", slog.Any("job", u), slog.Any("entry", entry))
-	}
+		s.entries[u.Name] = entry{unit: u, id: id}
 
-	s.log.InfoContext(ctx, "Scheduler started")
+		s.log.InfoContext(ctx, "Started job", slog.Any("job", u), slog.Any("entry", id))
+	}
 
-	s.cron.Start()
-	s.startMonitor(ctx)
+	if len(add) > 0 || len(remove) > 0 {
+		s.log.InfoContext(
+			ctx,
+			"shedule reloaded",
+			slog.Any("added", len(add)),
+			slog.Any("removed", len(remove)),
+		)
+	}
 
 	return nil
 }
 
-func (s *Sheduler) Stop() {
-	s.log.Info("stoping sheduler")
-	s.cron.Stop()
+func (s *Sheduler) fire(u models2.SheduleUnit) func() {
+	return func() {
+		at := time.Now().Truncate(time.Minute)
+
+		s.log.Debug("cron job executed", slog.Any("job_name", u.Name))
+
+		s.emit(u, models2.RunInfo{ScheduledAt: at})
+	}
+}
+
+// emit отправляет событие и после этого сохраняет время срабатывания.
+// Если событие не удалось отправить за emitTimeout, оно отбрасывается, а время
+// срабатывания не сохраняется, чтобы запуск был догнан после перезапуска.
+// Запуски, пропущенные из-за паузы, считаются выполненными и не догоняются.
+func (s *Sheduler) emit(u models2.SheduleUnit, run models2.RunInfo) {
+	ev := models2.NewEvent(u.Name, u.EventType)
+	ev.Run = run
+
+	go func() {
+		if !s.admit(u, run) {
+			s.saveFire(u, run.ScheduledAt)
+
+			return
+		}
+
+		timer := time.NewTimer(emitTimeout)
+		defer timer.Stop()
+
+		select {
+		case s.EventChan <- ev:
+		case <-timer.C:
+			s.log.Error(
+				"event dropped: pipeline is stuck",
+				slog.Any("job_name", u.Name),
+				slog.Any("scheduled_at", run.ScheduledAt),
+			)
+
+			return
+		}
+
+		s.saveFire(u, run.ScheduledAt)
+	}()
+}
+
+// admit проверяет паузу и пропуск следующего запуска.
+// Если состояние прочитать не удалось, запуск выполняется.
+func (s *Sheduler) admit(u models2.SheduleUnit, run models2.RunInfo) bool {
+	if s.gate == nil || run.Force {
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), saveFireTimeout)
+	defer cancel()
+
+	adm, err := s.gate.Admit(ctx, models2.ControlCron, u.Name, run.ScheduledAt)
+	if err != nil {
+		s.log.WarnContext(
+			ctx,
+			"unable to check run control, running anyway",
+			slog.Any("job_name", u.Name),
+			slog.Any("error", err),
+		)
+
+		return true
+	}
+
+	if adm != models2.AdmitRun {
+		s.log.InfoContext(
+			ctx,
+			"cron run skipped",
+			slog.Any("job_name", u.Name),
+			slog.Any("reason", adm),
+			slog.Any("scheduled_at", run.ScheduledAt),
+		)
+
+		return false
+	}
+
+	return true
+}
+
+func (s *Sheduler) saveFire(u models2.SheduleUnit, at time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), saveFireTimeout)
+	defer cancel()
+
+	if err := s.fires.SaveFire(ctx, u.Name, at); err != nil {
+		s.log.WarnContext(
+			ctx,
+			"unable to save fire time",
+			slog.Any("job_name", u.Name),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// catchUp отправляет события для запусков, пропущенных во время простоя.
+// Для расписаний без истории срабатываний сохраняется текущее время,
+// чтобы следующий простой можно было обнаружить.
+func (s *Sheduler) catchUp(ctx context.Context) {
+	if s.catchUpWindow <= 0 {
+		return
+	}
+
+	fires, err := s.fires.LastFires(ctx)
+	if err != nil {
+		s.log.ErrorContext(ctx, "unable to load last fires, skip catch-up", slog.Any("error", err))
+
+		return
+	}
+
+	now := time.Now()
+
+	s.mu.Lock()
+	units := make([]models2.SheduleUnit, 0, len(s.entries))
+	for _, e := range s.entries {
+		units = append(units, e.unit)
+	}
+	s.mu.Unlock()
+
+	for _, u := range units {
+		last, ok := fires[u.Name]
+		if !ok {
+			if err := s.fires.SaveFire(ctx, u.Name, now); err != nil {
+				s.log.WarnContext(
+					ctx,
+					"unable to save fire time",
+					slog.Any("job_name", u.Name),
+					slog.Any("error", err),
+				)
+			}
+
+			continue
+		}
+
+		missed, ok := models2.MissedFire(u.Crontab, last.In(time.Local), now, s.catchUpWindow)
+		if !ok {
+			continue
+		}
+
+		s.log.InfoContext(
+			ctx,
+			"catching up missed job",
+			slog.Any("job", u),
+			slog.Any("last_fire", last),
+			slog.Any("missed_fire", missed),
+		)
+
+		s.emit(u, models2.RunInfo{ScheduledAt: missed, CatchUp: true})
+	}
+}
+
+func (s *Sheduler) isRunning() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.running
 }
 
 func (s *Sheduler) startMonitor(ctx context.Context) {
 	s.log.DebugContext(ctx, "starting event monitor")
 
+	var poll <-chan time.Time
+
+	if s.pollInterval > 0 {
+		ticker := time.NewTicker(s.pollInterval)
+		poll = ticker.C
+
+		go func() {
+			<-ctx.Done()
+			ticker.Stop()
+		}()
+	}
+
 	go func() {
 		for {
 			select {
 			case <-ctx.Done():
+				s.Stop()
+
 				return
-			case ev := <-s.api:
-				switch ev {
-				case eventStart:
-					s.log.DebugContext(ctx, "received start event")
-					s.Stop()
-					s.Start(ctx)
-				case eventStop:
-					s.log.DebugContext(ctx, "received stop event")
-					s.Stop()
-				default:
-					s.log.DebugContext(ctx, "received unknown event", slog.Any("event", ev))
-				}
+			case payload := <-s.control:
+				s.handleControl(ctx, payload)
+			case payload := <-s.changes:
+				s.log.DebugContext(ctx, "received change notification", slog.Any("payload", payload))
+				s.reloadIfRunning(ctx)
+			case <-poll:
+				s.reloadIfRunning(ctx)
 			}
 		}
 	}()
 }
+
+// handleControl выполняет команду SheduleAPI.
+func (s *Sheduler) handleControl(ctx context.Context, payload string) {
+	// Пустой payload шлет Listener после переподключения.
+	if payload == "" {
+		return
+	}
+
+	var cmd controlCommand
+
+	if err := json.Unmarshal([]byte(payload), &cmd); err != nil {
+		s.log.WarnContext(
+			ctx,
+			"unable to parse scheduler command",
+			slog.Any("payload", payload),
+			slog.Any("error", err),
+		)
+
+		return
+	}
+
+	switch cmd.Action {
+	case actionStart:
+		s.log.DebugContext(ctx, "received start event")
+		//nolint:errcheck // error already logged
+		s.restart(ctx)
+	case actionStop:
+		s.log.DebugContext(ctx, "received stop event")
+		s.Stop()
+	case actionRun:
+		s.log.DebugContext(ctx, "received run event", slog.Any("job_name", cmd.Name))
+		s.runNow(cmd)
+	default:
+		s.log.DebugContext(ctx, "received unknown event", slog.Any("event", cmd.Action))
+	}
+}
+
+// runNow отправляет событие запуска вне очереди. В отличие от emit, пауза не
+// проверяется и время срабатывания не сохраняется.
+func (s *Sheduler) runNow(cmd controlCommand) {
+	ev := models2.NewEvent(cmd.Name, cmd.EventType)
+	ev.Run = models2.RunInfo{
+		ScheduledAt: time.Now(),
+		Trigger:     cmd.Source,
+		Force:       true,
+	}
+
+	go func() {
+		timer := time.NewTimer(emitTimeout)
+		defer timer.Stop()
+
+		select {
+		case s.EventChan <- ev:
+		case <-timer.C:
+			s.log.Error("event dropped: pipeline is stuck", slog.Any("job_name", cmd.Name))
+		}
+	}()
+}
+
+func (s *Sheduler) reloadIfRunning(ctx context.Context) {
+	if !s.isRunning() {
+		return
+	}
+
+	//nolint:errcheck // error already logged
+	s.Reload(ctx)
+}
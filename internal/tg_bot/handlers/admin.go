@@ -22,7 +22,10 @@ type AdminHandler struct {
 	userService *service.User
 	chatService *service.Chat
 	report      *service.Report
-	state       *State
+	editor      *service.ReportEditor
+	// registration — заявки на регистрацию, которые рассматривают администраторы.
+	registration *service.Registration
+	state        *State
 }
 
 func NewAdminHandler(
@@ -30,6 +33,8 @@ func NewAdminHandler(
 	userService *service.User,
 	chatService *service.Chat,
 	report *service.Report,
+	editor *service.ReportEditor,
+	registration *service.Registration,
 	state *State,
 ) *AdminHandler {
 	return &AdminHandler{
@@ -38,6 +43,9 @@ func NewAdminHandler(
 		chatService: chatService,
 		state:       state,
 		report:      report,
+		editor:      editor,
+
+		registration: registration,
 	}
 }
 
@@ -50,6 +58,7 @@ func (h *AdminHandler) StartAdmin(c tele.Context) error {
 	menu.AdminMenu.Reply(
 		menu.AdminMenu.Row(menu.ManageUsers, menu.ManageChats),
 		menu.AdminMenu.Row(menu.LoadAndShowReportUser, menu.ManageCron),
+		menu.AdminMenu.Row(menu.ManageReports, menu.AuditLog),
 	)
 	h.state.set(c.Sender().ID, menuState)
 	//nolint:errcheck
@@ -66,7 +75,7 @@ func (h *AdminHandler) loadReports(c tele.Context) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
-	rpl, err := h.report.LoadReportsWithPagination(ctx)
+	rpl, err := h.report.LoadReportsWithPagination(ctx, c.Sender().ID)
 	if err != nil {
 		return c.Send("Ошибка получения отчетов: " + err.Error())
 	}
@@ -94,7 +103,7 @@ func (h *AdminHandler) LoadReportsPage(c tele.Context) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
-	rpl, err := h.report.LoadReportByPage(ctx, page)
+	rpl, err := h.report.LoadReportByPage(ctx, c.Sender().ID, page)
 	if err != nil {
 		return c.Edit("Ошибка получения отчетов: " + err.Error())
 	}
@@ -119,52 +128,15 @@ func (h *AdminHandler) IgnoreReportPage(c tele.Context) error {
 }
 
 func (h *AdminHandler) GenerateSelectedReport(c tele.Context) error {
-	userID := c.Sender().ID
-	if h.state.get(userID) != loadReportState {
-		return c.Edit("Время на выбор отчета истекло, начните заново")
-	}
-
-	_, reportName, ok := strings.Cut(c.Data(), ";")
-	if !ok || reportName == "" {
-		return c.Respond(&tele.CallbackResponse{Text: "Не удалось определить отчет"})
-	}
-
-	if err := c.Respond(); err != nil {
-		return err
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-	defer cancel()
-
-	chat := &models.Chat{
-		ChatID:   c.Chat().ID,
-		Title:    &c.Chat().FirstName,
-		Type:     string(c.Chat().Type),
-		IsActive: true,
-	}
-
-	if err := h.report.GenerateReportByName(ctx, reportName, chat); err != nil {
-		return c.Edit("Не удалось запустить отчет: " + err.Error())
-	}
-
-	h.state.set(userID, menuState)
-
-	if err := c.Edit("Отчет запущен. Результат придет в этот чат."); err != nil {
-		if errors.Is(err, tele.ErrMessageNotModified) {
-			return nil
-		}
-
-		return err
-	}
-
-	return nil
+	return startReportRun(c, h.report, h.state)
 }
 
 // ManageUsers handles the user management menu.
 func (h *AdminHandler) ManageUsers(c tele.Context) error {
 	menu.AdminMenu.Reply(
 		menu.AdminMenu.Row(menu.AddUser, menu.RemoveUser),
-		menu.AdminMenu.Row(menu.ListUser, menu.Back))
+		menu.AdminMenu.Row(menu.ListUser, menu.Invites),
+		menu.AdminMenu.Row(menu.Back))
 	h.state.set(c.Sender().ID, menuState)
 
 	c.Delete()
@@ -190,6 +162,10 @@ func (h *AdminHandler) processAdminInput(c tele.Context) error {
 		return h.ProcessAddChat(c)
 	case removeChatState:
 		return h.ProcessRemoveChat(c)
+	case pauseUntilState:
+		return h.processPauseUntil(c)
+	case reportNameState, reportTitleState, reportCardsState, reportCronState, reportExprState:
+		return h.processReportInput(c)
 	default:
 		return nil // Если нет активного состояния — игнорируем
 	}
@@ -246,7 +222,7 @@ func (h *AdminHandler) AddUserWithUserRole(c tele.Context) error {
 
 	username := c.Data()
 
-	err := h.userService.CreateEmpty(ctx, username, false)
+	err := h.userService.CreateEmpty(ctx, username, false, actor(c))
 	if err != nil {
 		return c.Send("Не удалось добавить пользователя: " + err.Error())
 	}
@@ -266,7 +242,7 @@ func (h *AdminHandler) AddUserWithAdminRole(c tele.Context) error {
 
 	username := c.Data()
 
-	err := h.userService.CreateEmpty(ctx, username, true)
+	err := h.userService.CreateEmpty(ctx, username, true, actor(c))
 	if err != nil {
 		return c.Edit("Не удалось добавить пользователя: " + err.Error())
 	}
@@ -313,7 +289,7 @@ func (h *AdminHandler) ProcessRemoveUser(c tele.Context) error {
 	}
 
 	// Call service to remove user
-	err = h.userService.Delete(ctx, username, isPrimeReq)
+	err = h.userService.Delete(ctx, username, isPrimeReq, actor(c))
 	if err != nil {
 		return c.Send(errDeleteUser + err.Error())
 	}
@@ -381,7 +357,7 @@ func (h *AdminHandler) ProcessAddActiveChat(c tele.Context) error {
 	)
 	chatToAdd.Activate()
 
-	h.chatService.AddActive(ctx, chatToAdd)
+	h.chatService.AddActive(ctx, chatToAdd, actor(c))
 
 	return nil
 }
@@ -403,7 +379,7 @@ func (h *AdminHandler) ProcessAddChat(c tele.Context) error {
 		c.Chat().Description,
 	)
 
-	_ = h.chatService.Add(ctx, chatToSave)
+	_ = h.chatService.Add(ctx, chatToSave, actor(c))
 
 	return nil
 }
@@ -440,7 +416,7 @@ func (h *AdminHandler) ProcessRemoveChat(c tele.Context) error {
 
 	chatName := c.Text()
 
-	err := h.chatService.Remove(ctx, chatName)
+	err := h.chatService.Remove(ctx, chatName, actor(c))
 	if err != nil {
 		return c.Send("Ошибка удаления чата: " + err.Error())
 	}
@@ -482,8 +458,9 @@ func (h *AdminHandler) ListChats(c tele.Context) error {
 // ManageCron handles the chat management menu.
 func (h *AdminHandler) ManageCron(c tele.Context) error {
 	menu.AdminMenu.Reply(
-		menu.AdminMenu.Row(menu.StartCron),
-		menu.AdminMenu.Row(menu.StopCron, menu.Back))
+		menu.AdminMenu.Row(menu.StartCron, menu.FlushCache),
+		menu.AdminMenu.Row(menu.StopCron, menu.RunControl),
+		menu.AdminMenu.Row(menu.Back))
 
 	c.Delete()
 
@@ -492,20 +469,42 @@ func (h *AdminHandler) ManageCron(c tele.Context) error {
 
 // StartCronJobs перезапускает крон-задачи для уведомлений.
 func (h *AdminHandler) StartCronJobs(c tele.Context) error {
-	ans := h.startJobs()
+	ans := h.startJobs(actor(c))
 
 	return c.Send(ans)
 }
 
 // StopCronJobs перезапускает крон-задачи для уведомлений.
 func (h *AdminHandler) StopCronJobs(c tele.Context) error {
-	h.report.Stop()
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	if err := h.report.StopJobs(ctx, actor(c)); err != nil {
+		return c.Send("Не удалось остановить задачи: " + err.Error())
+	}
 
 	return c.Send("Задачи успешно остановлены")
 }
 
-func (h *AdminHandler) startJobs() string {
-	h.report.Start()
+// FlushCaches сбрасывает кэши отчетов и расписаний на всех экземплярах.
+func (h *AdminHandler) FlushCaches(c tele.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	if err := h.report.FlushCaches(ctx, actor(c)); err != nil {
+		return c.Send("Не удалось сбросить кэш: " + err.Error())
+	}
+
+	return c.Send("Кэш отчетов сброшен")
+}
+
+func (h *AdminHandler) startJobs(by string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	if err := h.report.StartJobs(ctx, by); err != nil {
+		return "Не удалось запустить задачи: " + err.Error()
+	}
 
 	return "Задачи запущены"
 }
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"time"
+
+	tele "gopkg.in/telebot.v4"
+	"support_bot/internal/tg_bot/service"
+)
+
+// AuditHandler показывает администраторам журнал действий в боте.
+type AuditHandler struct {
+	audit *service.Audit
+}
+
+func NewAuditHandler(audit *service.Audit) *AuditHandler {
+	return &AuditHandler{audit: audit}
+}
+
+// AuditLog показывает первую страницу журнала.
+func (h *AuditHandler) AuditLog(c tele.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	//nolint:errcheck
+	c.Delete()
+
+	rpl, err := h.audit.LoadPage(ctx, 1)
+	if err != nil {
+		return c.Send("Не удалось загрузить журнал: " + err.Error())
+	}
+
+	return c.Send(formatAuditPage(rpl), auditMarkup(rpl))
+}
+
+// AuditLogPage листает журнал.
+func (h *AuditHandler) AuditLogPage(c tele.Context) error {
+	page, err := strconv.Atoi(c.Data())
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "Не удалось определить страницу"})
+	}
+
+	if err := c.Respond(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	rpl, err := h.audit.LoadPage(ctx, page)
+	if err != nil {
+		return c.Edit("Не удалось загрузить журнал: " + err.Error())
+	}
+
+	return editIgnoringNotModified(c, formatAuditPage(rpl), auditMarkup(rpl))
+}
+
+// AuditLogExport присылает весь журнал файлом CSV.
+func (h *AuditHandler) AuditLogExport(c tele.Context) error {
+	if err := c.Respond(&tele.CallbackResponse{Text: "Готовлю выгрузку"}); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	data, err := h.audit.ExportCSV(ctx)
+	if err != nil {
+		return c.Send("Не удалось выгрузить журнал: " + err.Error())
+	}
+
+	return c.Send(&tele.Document{
+		File:     tele.FromReader(bytes.NewReader(data)),
+		FileName: "audit_log_" + time.Now().Format("2006-01-02") + ".csv",
+		MIME:     "text/csv",
+	})
+}
@@ -17,3 +17,8 @@ const (
 	errDeleteUser             = "Ошибка удаления пользователя: "
 	errDeleteUserCauseSuicide = errDeleteUser + "нельзя удалить себя"
 )
+
+const (
+	registrationApproved = "Вы успешно прошли регистрацию!\n напишите /start чтобы начать работу"
+	registrationDenied   = "Ваша заявка на регистрацию отклонена"
+)
@@ -2,60 +2,735 @@ package handlers
 
 import (
 	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	tele "gopkg.in/telebot.v4"
 	"support_bot/internal/models"
 )
 
+// actor описывает автора действия для журнала: Telegram ID однозначно определяет
+// пользователя, а username может быть пустым или смениться.
+func actor(c tele.Context) string {
+	user := c.Sender()
+	if user.Username == "" {
+		return strconv.FormatInt(user.ID, 10)
+	}
+
+	return fmt.Sprintf("%d (@%s)", user.ID, user.Username)
+}
+
 func mapReportRPLToMarkup(rp models.LoadReportRPL) tele.ReplyMarkup {
+	return reportPageMarkup(rp, "report", "back_report_list", "next_report_list")
+}
+
+// reportPageMarkup строит страницу списка отчетов: кнопки отчетов с unique pick
+// и кнопки перехода на соседние страницы с unique back и next.
+func reportPageMarkup(rp models.LoadReportRPL, pick, back, next string) tele.ReplyMarkup {
 	var rows [][]tele.InlineButton
 
 	for _, report := range rp.Reports {
 		rows = append(rows, []tele.InlineButton{
 			{
-				Unique: "report",
+				Unique: pick,
 				Text:   report.Title,
 				Data:   fmt.Sprintf("%d;%s", report.ID, report.Name),
 			},
 		})
 	}
 
-	var back, curr, next tele.InlineButton
+	navRow := make([]tele.InlineButton, 0, 3)
 
 	if rp.CurrentPage > 1 {
-		back = tele.InlineButton{
-			Unique: "back_report_list",
+		navRow = append(navRow, tele.InlineButton{
+			Unique: back,
 			Text:   "Back",
 			Data:   fmt.Sprintf("%d", rp.CurrentPage-1),
-		}
+		})
 	}
 
+	navRow = append(navRow, tele.InlineButton{
+		Unique: "_",
+		Text:   fmt.Sprintf("%d/%d", rp.CurrentPage, rp.PageCount),
+	})
+
 	if rp.CurrentPage < rp.PageCount {
-		next = tele.InlineButton{
-			Unique: "next_report_list",
+		navRow = append(navRow, tele.InlineButton{
+			Unique: next,
 			Text:   "Next",
 			Data:   fmt.Sprintf("%d", rp.CurrentPage+1),
+		})
+	}
+
+	rows = append(rows, navRow)
+
+	return tele.ReplyMarkup{InlineKeyboard: rows}
+}
+
+// pauseLayouts — форматы даты окончания паузы, которые принимает бот.
+var pauseLayouts = []string{"02.01.2006 15:04", "02.01.2006"}
+
+// parsePauseUntil разбирает дату окончания паузы в локальном времени.
+// Дата без времени означает начало дня.
+func parsePauseUntil(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+
+	for _, layout := range pauseLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
 		}
 	}
 
-	curr = tele.InlineButton{
+	return time.Time{}, fmt.Errorf("unexpected date %q", s)
+}
+
+func controlData(target models.ControlTarget, n int) string {
+	return fmt.Sprintf("%s;%d", target, n)
+}
+
+// parseControlData разбирает данные кнопок меню управления запусками: "target;n".
+func parseControlData(data string) (models.ControlTarget, int, bool) {
+	t, n, ok := strings.Cut(data, ";")
+	if !ok {
+		return "", 0, false
+	}
+
+	target, ok := models.ParseControlTarget(t)
+	if !ok {
+		return "", 0, false
+	}
+
+	num, err := strconv.Atoi(n)
+	if err != nil {
+		return "", 0, false
+	}
+
+	return target, num, true
+}
+
+func formatControl(c models.RunControl) string {
+	var parts []string
+
+	if c.PausedAt(time.Now()) {
+		parts = append(parts, "на паузе до "+c.PausedUntil.In(time.Local).Format(pauseLayouts[0]))
+	}
+
+	if c.SkipNext {
+		parts = append(parts, "следующий запуск будет пропущен")
+	}
+
+	if len(parts) == 0 {
+		return "запускается по расписанию"
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func formatActiveControls(controls []models.RunControl) string {
+	if len(controls) == 0 {
+		return "Все рассылки запускаются по расписанию.\n\nВыберите, чем управлять:"
+	}
+
+	var b strings.Builder
+
+	b.WriteString("Изменения расписания:\n\n")
+
+	for _, c := range controls {
+		kind := "Отчет"
+		if c.Target == models.ControlCron {
+			kind = "Расписание"
+		}
+
+		fmt.Fprintf(&b, "%s %s: %s\n", kind, c.Name, formatControl(c))
+	}
+
+	b.WriteString("\nВыберите, чем управлять:")
+
+	return b.String()
+}
+
+func controlTargetsMarkup() *tele.ReplyMarkup {
+	return &tele.ReplyMarkup{InlineKeyboard: [][]tele.InlineButton{{
+		{Unique: "rc_list", Text: "🕒 Расписания", Data: controlData(models.ControlCron, 1)},
+		{Unique: "rc_list", Text: "📄 Отчеты", Data: controlData(models.ControlReport, 1)},
+	}}}
+}
+
+func mapControlRPLToMarkup(rp models.LoadControlRPL) tele.ReplyMarkup {
+	var rows [][]tele.InlineButton
+
+	for _, item := range rp.Items {
+		text := item.Title
+		if item.Control.PausedAt(time.Now()) {
+			text = "⏸ " + text
+		} else if item.Control.SkipNext {
+			text = "⏭ " + text
+		}
+
+		rows = append(rows, []tele.InlineButton{{
+			Unique: "rc_item",
+			Text:   text,
+			Data:   controlData(rp.Target, item.ID),
+		}})
+	}
+
+	navRow := make([]tele.InlineButton, 0, 3)
+
+	if rp.CurrentPage > 1 {
+		navRow = append(navRow, tele.InlineButton{
+			Unique: "rc_list",
+			Text:   "Back",
+			Data:   controlData(rp.Target, rp.CurrentPage-1),
+		})
+	}
+
+	navRow = append(navRow, tele.InlineButton{
 		Unique: "_",
 		Text:   fmt.Sprintf("%d/%d", rp.CurrentPage, rp.PageCount),
+	})
+
+	if rp.CurrentPage < rp.PageCount {
+		navRow = append(navRow, tele.InlineButton{
+			Unique: "rc_list",
+			Text:   "Next",
+			Data:   controlData(rp.Target, rp.CurrentPage+1),
+		})
+	}
+
+	rows = append(rows, navRow)
+
+	return tele.ReplyMarkup{InlineKeyboard: rows}
+}
+
+func controlItemMarkup(item models.ControlItem) *tele.ReplyMarkup {
+	data := controlData(item.Control.Target, item.ID)
+
+	return &tele.ReplyMarkup{InlineKeyboard: [][]tele.InlineButton{
+		{
+			{Unique: "rc_pause", Text: "⏸ Пауза до даты", Data: data},
+			{Unique: "rc_skip", Text: "⏭ Пропустить следующий", Data: data},
+		},
+		{
+			{Unique: "rc_resume", Text: "▶️ Возобновить", Data: data},
+			{Unique: "rc_run", Text: "🚀 Запустить сейчас", Data: data},
+		},
+		{
+			{Unique: "rc_list", Text: "🔙 К списку", Data: controlData(item.Control.Target, 1)},
+		},
+	}}
+}
+
+func formatControlItem(item models.ControlItem) string {
+	kind := "Отчет"
+	if item.Control.Target == models.ControlCron {
+		kind = "Расписание"
+	}
+
+	return fmt.Sprintf("%s %s (%s)\nСостояние: %s", kind, item.Title, item.Name, formatControl(item.Control))
+}
+
+const (
+	reportFormatsPrompt = "Выберите форматы экспорта"
+	reportChatsPrompt   = "Выберите чаты, в которые отправляется отчет"
+)
+
+func reportFormatsMarkup(formats, selected []string) *tele.ReplyMarkup {
+	var (
+		rows [][]tele.InlineButton
+		row  []tele.InlineButton
+	)
+
+	for _, f := range formats {
+		text := f
+		if slices.Contains(selected, f) {
+			text = "✅ " + f
+		}
+
+		row = append(row, tele.InlineButton{Unique: "re_fmt", Text: text, Data: f})
+
+		if len(row) == 3 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+
+	rows = append(rows, []tele.InlineButton{{Unique: "re_fmt_done", Text: "Готово"}})
+
+	return &tele.ReplyMarkup{InlineKeyboard: rows}
+}
+
+func reportChatsMarkup(chats []models.TgChatDTO, selected []int) *tele.ReplyMarkup {
+	rows := make([][]tele.InlineButton, 0, len(chats)+1)
+
+	for _, ch := range chats {
+		text := ch.Title
+		if slices.Contains(selected, ch.ID) {
+			text = "✅ " + text
+		}
+
+		rows = append(rows, []tele.InlineButton{{
+			Unique: "re_chat",
+			Text:   text,
+			Data:   strconv.Itoa(ch.ID),
+		}})
+	}
+
+	rows = append(rows, []tele.InlineButton{{Unique: "re_chat_done", Text: "Готово"}})
+
+	return &tele.ReplyMarkup{InlineKeyboard: rows}
+}
+
+func reportCronsMarkup(crons []models.Cron) *tele.ReplyMarkup {
+	rows := make([][]tele.InlineButton, 0, len(crons)+1)
+
+	for _, c := range crons {
+		title := c.Name
+		if c.Description != nil && *c.Description != "" {
+			title = *c.Description
+		}
+
+		rows = append(rows, []tele.InlineButton{{
+			Unique: "re_cron",
+			Text:   fmt.Sprintf("%s (%s)", title, c.Cron),
+			Data:   strconv.Itoa(c.ID),
+		}})
+	}
+
+	rows = append(rows, []tele.InlineButton{{Unique: "re_cron", Text: "Без расписания"}})
+
+	return &tele.ReplyMarkup{InlineKeyboard: rows}
+}
+
+func reportExprMarkup() *tele.ReplyMarkup {
+	return &tele.ReplyMarkup{InlineKeyboard: [][]tele.InlineButton{{
+		{Unique: "re_expr_all", Text: "Отправлять всегда"},
+	}}}
+}
+
+func reportSummaryMarkup(d *reportDraft) *tele.ReplyMarkup {
+	field := func(text, step string) tele.InlineButton {
+		return tele.InlineButton{Unique: "re_field", Text: text, Data: step}
+	}
+
+	active := "✅ Включить"
+	if d.Active {
+		active = "⛔ Выключить"
+	}
+
+	subscribable := "🔔 Разрешить подписку"
+	if d.Subscribable {
+		subscribable = "🔕 Запретить подписку"
+	}
+
+	var rows [][]tele.InlineButton
+
+	if d.ID == 0 {
+		rows = append(rows, []tele.InlineButton{field("Имя", reportNameState)})
+	}
+
+	rows = append(rows,
+		[]tele.InlineButton{field("Название", reportTitleState), field("Карточки", reportCardsState)},
+		[]tele.InlineButton{field("Форматы", reportFormatsState), field("Чаты", reportChatsState)},
+		[]tele.InlineButton{field("Расписание", reportCronState), field("Условие", reportExprState)},
+		[]tele.InlineButton{
+			{Unique: "re_active", Text: active},
+			{Unique: "re_subscribable", Text: subscribable},
+		},
+		[]tele.InlineButton{
+			{Unique: "re_save", Text: "💾 Сохранить"},
+			{Unique: "re_cancel", Text: "❌ Отмена"},
+		},
+	)
+
+	return &tele.ReplyMarkup{InlineKeyboard: rows}
+}
+
+func formatCrontabs(crontabs []string) string {
+	if len(crontabs) == 0 {
+		return "без расписания"
+	}
+
+	return strings.Join(crontabs, "; ")
+}
+
+func formatCurrentCards(cards []models.Card) string {
+	if len(cards) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+
+	b.WriteString("\n\nСейчас:\n")
+
+	for _, c := range cards {
+		fmt.Fprintf(&b, "%s %s\n", c.CardUUID, c.Title)
+	}
+
+	return b.String()
+}
+
+// subscriptionData — данные кнопки подписки: "reportID;page".
+func subscriptionData(reportID, page int) string {
+	return fmt.Sprintf("%d;%d", reportID, page)
+}
+
+func parseSubscriptionData(data string) (int, int, bool) {
+	r, p, ok := strings.Cut(data, ";")
+	if !ok {
+		return 0, 0, false
+	}
+
+	reportID, err := strconv.Atoi(r)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	page, err := strconv.Atoi(p)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return reportID, page, true
+}
+
+func mapSubscriptionRPLToMarkup(rp models.LoadSubscriptionRPL) tele.ReplyMarkup {
+	rows := make([][]tele.InlineButton, 0, len(rp.Reports)+1)
+
+	for _, report := range rp.Reports {
+		text := "➕ " + report.Title
+		if report.Subscribed {
+			text = "✅ " + report.Title
+		}
+
+		rows = append(rows, []tele.InlineButton{{
+			Unique: "sub_toggle",
+			Text:   text,
+			Data:   subscriptionData(report.ReportID, rp.CurrentPage),
+		}})
 	}
 
 	navRow := make([]tele.InlineButton, 0, 3)
 
-	if back.Unique != "" {
-		navRow = append(navRow, back)
+	if rp.CurrentPage > 1 {
+		navRow = append(navRow, tele.InlineButton{
+			Unique: "sub_page",
+			Text:   "Back",
+			Data:   strconv.Itoa(rp.CurrentPage - 1),
+		})
 	}
 
-	navRow = append(navRow, curr)
+	navRow = append(navRow, tele.InlineButton{
+		Unique: "_",
+		Text:   fmt.Sprintf("%d/%d", rp.CurrentPage, rp.PageCount),
+	})
 
-	if next.Unique != "" {
-		navRow = append(navRow, next)
+	if rp.CurrentPage < rp.PageCount {
+		navRow = append(navRow, tele.InlineButton{
+			Unique: "sub_page",
+			Text:   "Next",
+			Data:   strconv.Itoa(rp.CurrentPage + 1),
+		})
 	}
 
 	rows = append(rows, navRow)
 
 	return tele.ReplyMarkup{InlineKeyboard: rows}
 }
+
+func unsubscribeMarkup(subs []models.Subscription) *tele.ReplyMarkup {
+	rows := make([][]tele.InlineButton, 0, len(subs))
+
+	for _, s := range subs {
+		rows = append(rows, []tele.InlineButton{{
+			Unique: "unsub",
+			Text:   "❌ " + s.Title,
+			Data:   strconv.Itoa(s.ReportID),
+		}})
+	}
+
+	return &tele.ReplyMarkup{InlineKeyboard: rows}
+}
+
+// formatSubscriptions группирует отчеты чата по топикам.
+// Отчеты, назначенные администратором бота, помечаются отдельно: отписаться от них из чата нельзя.
+func formatSubscriptions(subs []models.Subscription) string {
+	if len(subs) == 0 {
+		return "В этот чат не приходит ни один отчет"
+	}
+
+	var (
+		b      strings.Builder
+		thread = -1
+	)
+
+	b.WriteString("Отчеты, которые приходят в этот чат:\n")
+
+	for _, s := range subs {
+		if s.ThreadID != thread {
+			thread = s.ThreadID
+
+			if thread == 0 {
+				b.WriteString("\nВесь чат:\n")
+			} else {
+				fmt.Fprintf(&b, "\nТопик %d:\n", thread)
+			}
+		}
+
+		b.WriteString("• " + s.Title)
+
+		if !s.Subscribable {
+			b.WriteString(" (назначен администратором)")
+		}
+
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// calendarMonthLayout — формат месяца в данных кнопок листания календаря.
+const calendarMonthLayout = "2006-01"
+
+var calendarMonths = [...]string{
+	"Январь", "Февраль", "Март", "Апрель", "Май", "Июнь",
+	"Июль", "Август", "Сентябрь", "Октябрь", "Ноябрь", "Декабрь",
+}
+
+// calendarMarkup строит календарь на месяц month. Неделя начинается с понедельника.
+func calendarMarkup(month time.Time) *tele.ReplyMarkup {
+	first := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.Local)
+	empty := tele.InlineButton{Unique: "_", Text: " "}
+
+	rows := [][]tele.InlineButton{
+		{
+			{Unique: "rp_month", Text: "‹", Data: first.AddDate(0, -1, 0).Format(calendarMonthLayout)},
+			{Unique: "_", Text: fmt.Sprintf("%s %d", calendarMonths[first.Month()-1], first.Year())},
+			{Unique: "rp_month", Text: "›", Data: first.AddDate(0, 1, 0).Format(calendarMonthLayout)},
+		},
+	}
+
+	weekdays := make([]tele.InlineButton, 0, 7)
+	for _, d := range []string{"Пн", "Вт", "Ср", "Чт", "Пт", "Сб", "Вс"} {
+		weekdays = append(weekdays, tele.InlineButton{Unique: "_", Text: d})
+	}
+
+	rows = append(rows, weekdays)
+
+	week := make([]tele.InlineButton, 0, 7)
+	for range (int(first.Weekday()) + 6) % 7 {
+		week = append(week, empty)
+	}
+
+	for day := first; day.Month() == first.Month(); day = day.AddDate(0, 0, 1) {
+		week = append(week, tele.InlineButton{
+			Unique: "rp_day",
+			Text:   strconv.Itoa(day.Day()),
+			Data:   day.Format(models.ParamDateLayout),
+		})
+
+		if len(week) == 7 {
+			rows = append(rows, week)
+			week = make([]tele.InlineButton, 0, 7)
+		}
+	}
+
+	if len(week) > 0 {
+		for len(week) < 7 {
+			week = append(week, empty)
+		}
+
+		rows = append(rows, week)
+	}
+
+	rows = append(rows, []tele.InlineButton{{Unique: "rp_cancel", Text: "❌ Отмена"}})
+
+	return &tele.ReplyMarkup{InlineKeyboard: rows}
+}
+
+func paramOptionsMarkup(options []string) *tele.ReplyMarkup {
+	rows := make([][]tele.InlineButton, 0, len(options)+1)
+
+	for i, o := range options {
+		rows = append(rows, []tele.InlineButton{{Unique: "rp_opt", Text: o, Data: strconv.Itoa(i)}})
+	}
+
+	rows = append(rows, []tele.InlineButton{{Unique: "rp_cancel", Text: "❌ Отмена"}})
+
+	return &tele.ReplyMarkup{InlineKeyboard: rows}
+}
+
+func formatParamValues(values map[string]string) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	keys := slices.Sorted(maps.Keys(values))
+
+	var b strings.Builder
+
+	b.WriteString("\n\nПараметры:\n")
+
+	for _, k := range keys {
+		fmt.Fprintf(&b, "  • %s: %s\n", k, values[k])
+	}
+
+	return b.String()
+}
+
+func invitesMarkup(invites []models.Invite, now time.Time) *tele.ReplyMarkup {
+	rows := [][]tele.InlineButton{{{Unique: "inv_new", Text: "➕ Создать приглашение"}}}
+
+	for _, inv := range invites {
+		if inv.Status(now) != models.InviteActive {
+			continue
+		}
+
+		rows = append(rows, []tele.InlineButton{{
+			Unique: "inv_revoke",
+			Text:   fmt.Sprintf("🚫 Отозвать #%d", inv.ID),
+			Data:   strconv.Itoa(inv.ID),
+		}})
+	}
+
+	return &tele.ReplyMarkup{InlineKeyboard: rows}
+}
+
+func inviteRoleMarkup() *tele.ReplyMarkup {
+	return &tele.ReplyMarkup{InlineKeyboard: [][]tele.InlineButton{
+		{
+			{Unique: "inv_role", Text: "User", Data: models.UserRole},
+			{Unique: "inv_role", Text: "Admin", Data: models.AdminRole},
+		},
+		{{Unique: "inv_list", Text: "🔙 Назад"}},
+	}}
+}
+
+func inviteGroupsMarkup(groups []models.UserGroup, selected []int) *tele.ReplyMarkup {
+	rows := make([][]tele.InlineButton, 0, len(groups)+1)
+
+	for _, g := range groups {
+		text := "➕ " + g.DisplayName()
+		if slices.Contains(selected, g.ID) {
+			text = "✅ " + g.DisplayName()
+		}
+
+		rows = append(rows, []tele.InlineButton{{
+			Unique: "inv_group",
+			Text:   text,
+			Data:   strconv.Itoa(g.ID),
+		}})
+	}
+
+	rows = append(rows, []tele.InlineButton{
+		{Unique: "inv_list", Text: "❌ Отмена"},
+		{Unique: "inv_create", Text: "✅ Создать"},
+	})
+
+	return &tele.ReplyMarkup{InlineKeyboard: rows}
+}
+
+// formatInvites описывает последние приглашения: кто и когда создал, использовал или отозвал.
+func formatInvites(invites []models.Invite, now time.Time) string {
+	if len(invites) == 0 {
+		return "Приглашений пока нет"
+	}
+
+	const layout = "02.01.2006 15:04"
+
+	var b strings.Builder
+
+	b.WriteString("Последние приглашения:\n")
+
+	for _, inv := range invites {
+		fmt.Fprintf(&b, "\n#%d %s, создал %s %s", inv.ID, inv.Role, inv.CreatedBy, inv.CreatedAt.Local().Format(layout))
+
+		if len(inv.Groups) > 0 {
+			fmt.Fprintf(&b, "\n  группы: %s", strings.Join(inv.Groups, ", "))
+		}
+
+		switch inv.Status(now) {
+		case models.InviteActive:
+			fmt.Fprintf(&b, "\n  активно до %s", inv.ExpiresAt.Local().Format(layout))
+		case models.InviteUsed:
+			fmt.Fprintf(&b, "\n  использовал %s %s", inviteUser(inv), inv.UsedAt.Local().Format(layout))
+		case models.InviteRevoked:
+			fmt.Fprintf(&b, "\n  отозвал %s %s", *inv.RevokedBy, inv.RevokedAt.Local().Format(layout))
+		case models.InviteExpired:
+			fmt.Fprintf(&b, "\n  истекло %s", inv.ExpiresAt.Local().Format(layout))
+		}
+	}
+
+	return b.String()
+}
+
+func inviteUser(inv models.Invite) string {
+	if inv.UsedUsername != nil && *inv.UsedUsername != "" {
+		return "@" + *inv.UsedUsername
+	}
+
+	if inv.UsedBy != nil {
+		return "id " + strconv.FormatInt(*inv.UsedBy, 10)
+	}
+
+	return ""
+}
+
+func formatAuditPage(rp models.LoadAuditRPL) string {
+	if len(rp.Entries) == 0 {
+		return "Журнал действий пуст"
+	}
+
+	var b strings.Builder
+
+	b.WriteString("Журнал действий:\n")
+
+	for _, e := range rp.Entries {
+		fmt.Fprintf(
+			&b,
+			"\n%s %s %s: %s",
+			e.CreatedAt.Local().Format("02.01.2006 15:04"),
+			e.Actor,
+			e.Action,
+			e.Target,
+		)
+	}
+
+	return b.String()
+}
+
+func auditMarkup(rp models.LoadAuditRPL) *tele.ReplyMarkup {
+	navRow := make([]tele.InlineButton, 0, 3)
+
+	if rp.CurrentPage > 1 {
+		navRow = append(navRow, tele.InlineButton{
+			Unique: "audit_page",
+			Text:   "Back",
+			Data:   strconv.Itoa(rp.CurrentPage - 1),
+		})
+	}
+
+	navRow = append(navRow, tele.InlineButton{
+		Unique: "_",
+		Text:   fmt.Sprintf("%d/%d", rp.CurrentPage, rp.PageCount),
+	})
+
+	if rp.CurrentPage < rp.PageCount {
+		navRow = append(navRow, tele.InlineButton{
+			Unique: "audit_page",
+			Text:   "Next",
+			Data:   strconv.Itoa(rp.CurrentPage + 1),
+		})
+	}
+
+	return &tele.ReplyMarkup{InlineKeyboard: [][]tele.InlineButton{
+		navRow,
+		{{Unique: "audit_csv", Text: "📥 Выгрузить CSV"}},
+	}}
+}
@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	tele "gopkg.in/telebot.v4"
+	"support_bot/internal/errorz"
+	"support_bot/internal/models"
+	"support_bot/internal/tg_bot/service"
+)
+
+const inviteExpired = "Время на создание приглашения истекло, начните заново"
+
+// inviteDraft — приглашение, которое настраивает администратор.
+type inviteDraft struct {
+	Role   string `json:"role"`
+	Groups []int  `json:"groups"`
+
+	// Available — группы, из которых выбирает администратор.
+	Available []models.UserGroup `json:"available"`
+}
+
+// InviteHandler управляет приглашениями: администраторы создают и отзывают их,
+// пользователи регистрируются, открыв ссылку с приглашением.
+type InviteHandler struct {
+	bot     *tele.Bot
+	invites *service.Invite
+	state   *State
+}
+
+func NewInviteHandler(bot *tele.Bot, invites *service.Invite, state *State) *InviteHandler {
+	return &InviteHandler{
+		bot:     bot,
+		invites: invites,
+		state:   state,
+	}
+}
+
+// Invites показывает последние приглашения и кнопки для создания и отзыва.
+func (h *InviteHandler) Invites(c tele.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	//nolint:errcheck
+	c.Delete()
+
+	invites, err := h.invites.List(ctx)
+	if err != nil {
+		return c.Send("Не удалось загрузить приглашения: " + err.Error())
+	}
+
+	return c.Send(formatInvites(invites, time.Now()), invitesMarkup(invites, time.Now()))
+}
+
+// InvitesPage перерисовывает список приглашений.
+func (h *InviteHandler) InvitesPage(c tele.Context) error {
+	if err := c.Respond(); err != nil {
+		return err
+	}
+
+	return h.showInvites(c, "")
+}
+
+func (h *InviteHandler) showInvites(c tele.Context, header string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	invites, err := h.invites.List(ctx)
+	if err != nil {
+		return c.Edit("Не удалось загрузить приглашения: " + err.Error())
+	}
+
+	return editIgnoringNotModified(
+		c,
+		header+formatInvites(invites, time.Now()),
+		invitesMarkup(invites, time.Now()),
+	)
+}
+
+// NewInvite начинает создание приглашения с выбора роли.
+func (h *InviteHandler) NewInvite(c tele.Context) error {
+	if err := c.Respond(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	groups, err := h.invites.Groups(ctx)
+	if err != nil {
+		return c.Edit("Не удалось загрузить группы: " + err.Error())
+	}
+
+	userID := c.Sender().ID
+	h.state.setInvite(userID, &inviteDraft{Available: groups})
+	h.state.set(userID, inviteState)
+
+	return editIgnoringNotModified(c, "Выберите роль для приглашенного пользователя", inviteRoleMarkup())
+}
+
+func (h *InviteHandler) draft(c tele.Context) (*inviteDraft, bool) {
+	userID := c.Sender().ID
+	if h.state.get(userID) != inviteState {
+		return nil, false
+	}
+
+	return h.state.getInvite(userID)
+}
+
+// InviteRole принимает роль и переходит к выбору групп.
+func (h *InviteHandler) InviteRole(c tele.Context) error {
+	d, ok := h.draft(c)
+	if !ok {
+		return c.Edit(inviteExpired)
+	}
+
+	role := c.Data()
+	if role != models.UserRole && role != models.AdminRole {
+		return c.Respond(&tele.CallbackResponse{Text: "Не удалось определить роль"})
+	}
+
+	if err := c.Respond(); err != nil {
+		return err
+	}
+
+	d.Role = role
+	h.state.setInvite(c.Sender().ID, d)
+
+	return h.promptGroups(c, d)
+}
+
+func (h *InviteHandler) promptGroups(c tele.Context, d *inviteDraft) error {
+	text := fmt.Sprintf("Роль: %s\nВыберите группы, в которые попадет пользователь", d.Role)
+	if len(d.Available) == 0 {
+		text = fmt.Sprintf("Роль: %s\nГрупп пользователей пока нет", d.Role)
+	}
+
+	return editIgnoringNotModified(c, text, inviteGroupsMarkup(d.Available, d.Groups))
+}
+
+// InviteToggleGroup добавляет группу в приглашение или убирает ее.
+func (h *InviteHandler) InviteToggleGroup(c tele.Context) error {
+	d, ok := h.draft(c)
+	if !ok {
+		return c.Edit(inviteExpired)
+	}
+
+	id, err := strconv.Atoi(c.Data())
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "Не удалось определить группу"})
+	}
+
+	if err := c.Respond(); err != nil {
+		return err
+	}
+
+	if i := slices.Index(d.Groups, id); i >= 0 {
+		d.Groups = slices.Delete(d.Groups, i, i+1)
+	} else {
+		d.Groups = append(d.Groups, id)
+	}
+
+	h.state.setInvite(c.Sender().ID, d)
+
+	return h.promptGroups(c, d)
+}
+
+// InviteCreate сохраняет приглашение и показывает ссылку на него.
+func (h *InviteHandler) InviteCreate(c tele.Context) error {
+	d, ok := h.draft(c)
+	if !ok || d.Role == "" {
+		return c.Edit(inviteExpired)
+	}
+
+	if err := c.Respond(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	inv, err := h.invites.Create(ctx, d.Role, d.Groups, actor(c))
+	if err != nil {
+		return c.Edit("Не удалось создать приглашение: " + err.Error())
+	}
+
+	h.state.set(c.Sender().ID, menuState)
+
+	return editIgnoringNotModified(c, fmt.Sprintf(
+		"Приглашение #%d создано. Ссылка одноразовая и действует до %s:\n%s",
+		inv.ID,
+		inv.ExpiresAt.Local().Format("02.01.2006 15:04"),
+		service.InviteLink(h.bot.Me.Username, inv.Token),
+	))
+}
+
+// InviteRevoke отзывает приглашение.
+func (h *InviteHandler) InviteRevoke(c tele.Context) error {
+	id, err := strconv.Atoi(c.Data())
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "Не удалось определить приглашение"})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	err = h.invites.Revoke(ctx, id, actor(c))
+	if errors.Is(err, errorz.ErrNotFound) {
+		return c.Respond(&tele.CallbackResponse{Text: "Приглашение уже использовано или отозвано"})
+	}
+
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "Не удалось отозвать приглашение: " + err.Error()})
+	}
+
+	if err := c.Respond(); err != nil {
+		return err
+	}
+
+	return h.showInvites(c, fmt.Sprintf("Приглашение #%d отозвано\n\n", id))
+}
+
+// AcceptInvite регистрирует отправителя /start <token> по приглашению.
+func (h *InviteHandler) AcceptInvite(c tele.Context) error {
+	if c.Chat().Type != tele.ChatPrivate {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	user := models.NewUser(
+		c.Sender().ID,
+		c.Sender().Username,
+		c.Sender().FirstName,
+		&c.Sender().LastName,
+		false,
+	)
+
+	inv, err := h.invites.Redeem(ctx, c.Message().Payload, user)
+
+	switch {
+	case errors.Is(err, errorz.ErrNotFound):
+		return c.Send("Приглашение не найдено. Попросите администратора прислать новую ссылку")
+	case errors.Is(err, models.ErrInviteInactive):
+		return c.Send(inactiveInviteMessage(inv.Status(time.Now())))
+	case err != nil:
+		return c.Send("Не удалось принять приглашение, попробуйте позже")
+	}
+
+	if inv.Role == models.AdminRole {
+		return c.Send("Приглашение принято, вы зарегистрированы как администратор.\nНапишите /admin чтобы начать работу")
+	}
+
+	return c.Send(registrationApproved)
+}
+
+func inactiveInviteMessage(status models.InviteStatus) string {
+	switch status {
+	case models.InviteUsed:
+		return "Это приглашение уже использовано"
+	case models.InviteRevoked:
+		return "Это приглашение отозвано администратором"
+	default:
+		return "Срок действия приглашения истек. Попросите администратора прислать новую ссылку"
+	}
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	tele "gopkg.in/telebot.v4"
+	"support_bot/internal/errorz"
+	"support_bot/internal/models"
+)
+
+// ApproveRegistration одобряет заявку на регистрацию из уведомления администратору.
+func (h *AdminHandler) ApproveRegistration(c tele.Context) error {
+	return h.decideRegistration(c, models.RegistrationApproved)
+}
+
+// DenyRegistration отклоняет заявку на регистрацию.
+func (h *AdminHandler) DenyRegistration(c tele.Context) error {
+	return h.decideRegistration(c, models.RegistrationDenied)
+}
+
+func (h *AdminHandler) decideRegistration(c tele.Context, status models.RegistrationStatus) error {
+	id, err := strconv.Atoi(c.Data())
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "Не удалось определить заявку"})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	by := actor(c)
+
+	var req models.RegistrationRequest
+
+	if status == models.RegistrationApproved {
+		req, err = h.registration.Approve(ctx, id, by)
+	} else {
+		req, err = h.registration.Deny(ctx, id, by)
+	}
+
+	if errors.Is(err, errorz.ErrNotFound) {
+		if err := c.Respond(&tele.CallbackResponse{Text: "Заявка уже рассмотрена"}); err != nil {
+			return err
+		}
+
+		return editIgnoringNotModified(c, "Заявка уже рассмотрена другим администратором")
+	}
+
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "Не удалось обработать заявку: " + err.Error()})
+	}
+
+	if err := c.Respond(); err != nil {
+		return err
+	}
+
+	answer, result := registrationDenied, "отклонена"
+	if status == models.RegistrationApproved {
+		answer, result = registrationApproved, "одобрена"
+	}
+
+	//nolint:errcheck
+	h.bot.Send(tele.ChatID(req.TelegramID), answer)
+
+	return editIgnoringNotModified(c, fmt.Sprintf("Заявка %s %s администратором %s", req.DisplayName(), result, "@"+c.Sender().Username))
+}
@@ -0,0 +1,617 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"support_bot/internal/models"
+	"support_bot/internal/tg_bot/menu"
+
+	tele "gopkg.in/telebot.v4"
+)
+
+const (
+	reportDraftExpired = "Время на редактирование отчета истекло, начните заново"
+	// alwaysSendExpr — CEL-условие «отправлять всегда», см. evaluator.AlwaysTrueExpr.
+	alwaysSendExpr = "[*]"
+)
+
+// reportSteps — шаги создания отчета по порядку.
+var reportSteps = []string{
+	reportNameState,
+	reportTitleState,
+	reportCardsState,
+	reportFormatsState,
+	reportChatsState,
+	reportCronState,
+	reportExprState,
+	reportSummaryState,
+}
+
+type reportDraft struct {
+	models.ReportDraft
+	// Wizard — отчет создается по шагам: после каждого шага открывается следующий.
+	// После первого показа сводки отдельные поля редактируются с возвратом к ней.
+	Wizard bool `json:"wizard"`
+}
+
+// ManageReports открывает меню управления отчетами.
+func (h *AdminHandler) ManageReports(c tele.Context) error {
+	menu.AdminMenu.Reply(
+		menu.AdminMenu.Row(menu.CreateReport, menu.EditReport),
+		menu.AdminMenu.Row(menu.Back))
+	h.state.set(c.Sender().ID, menuState)
+	//nolint:errcheck
+	c.Delete()
+
+	return c.Send("Управление отчетами", menu.AdminMenu)
+}
+
+// CreateReport начинает создание нового отчета.
+func (h *AdminHandler) CreateReport(c tele.Context) error {
+	h.state.setDraft(c.Sender().ID, &reportDraft{
+		ReportDraft: models.ReportDraft{Evaluation: alwaysSendExpr},
+		Wizard:      true,
+	})
+
+	return h.promptReportStep(c, reportNameState)
+}
+
+// EditReport показывает список отчетов для редактирования.
+func (h *AdminHandler) EditReport(c tele.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	rpl, err := h.editor.LoadReportsPage(ctx, 1)
+	if err != nil {
+		return c.Send("Ошибка получения отчетов: " + err.Error())
+	}
+
+	h.state.set(c.Sender().ID, reportPickState)
+
+	mark := reportPageMarkup(rpl, "re_pick", "re_list", "re_list")
+
+	return c.Send("Выберите отчет", &mark)
+}
+
+// EditReportPage переключает страницу списка отчетов для редактирования.
+func (h *AdminHandler) EditReportPage(c tele.Context) error {
+	userID := c.Sender().ID
+	if h.state.get(userID) != reportPickState {
+		return c.Edit(reportDraftExpired)
+	}
+
+	page, err := strconv.Atoi(c.Data())
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "Не удалось определить страницу"})
+	}
+
+	if err := c.Respond(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	rpl, err := h.editor.LoadReportsPage(ctx, page)
+	if err != nil {
+		return c.Edit("Ошибка получения отчетов: " + err.Error())
+	}
+
+	h.state.set(userID, reportPickState)
+
+	mark := reportPageMarkup(rpl, "re_pick", "re_list", "re_list")
+
+	return editIgnoringNotModified(c, "Выберите отчет", &mark)
+}
+
+// EditReportPick загружает выбранный отчет и показывает сводку.
+func (h *AdminHandler) EditReportPick(c tele.Context) error {
+	userID := c.Sender().ID
+	if h.state.get(userID) != reportPickState {
+		return c.Edit(reportDraftExpired)
+	}
+
+	idStr, _, _ := strings.Cut(c.Data(), ";")
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "Не удалось определить отчет"})
+	}
+
+	if err := c.Respond(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	d, err := h.editor.Load(ctx, id)
+	if err != nil {
+		return c.Edit("Не удалось загрузить отчет: " + err.Error())
+	}
+
+	h.state.setDraft(userID, &reportDraft{ReportDraft: d})
+
+	//nolint:errcheck
+	c.Delete()
+
+	return h.promptReportStep(c, reportSummaryState)
+}
+
+// processReportInput обрабатывает текстовые шаги редактирования отчета.
+func (h *AdminHandler) processReportInput(c tele.Context) error {
+	userID := c.Sender().ID
+	state := h.state.get(userID)
+
+	d, ok := h.state.getDraft(userID)
+	if !ok {
+		return c.Send(reportDraftExpired)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	text := strings.TrimSpace(c.Text())
+
+	switch state {
+	case reportNameState:
+		if err := h.editor.CheckName(ctx, text); err != nil {
+			return c.Send("Имя не подходит: " + err.Error())
+		}
+
+		d.Name = text
+	case reportTitleState:
+		if err := models.ValidateReportTitle(text); err != nil {
+			return c.Send("Название не подходит: " + err.Error())
+		}
+
+		d.Title = text
+	case reportCardsState:
+		cards, err := models.ParseDraftCards(text)
+		if err != nil {
+			return c.Send("Не удалось разобрать карточки: " + err.Error())
+		}
+
+		d.Cards = cards
+	case reportCronState:
+		if _, err := models.NewCron(text); err != nil {
+			return c.Send("Неверное cron-выражение, нужно 5 полей, например: 0 9 * * 1-5")
+		}
+
+		d.Crontabs = []string{text}
+	case reportExprState:
+		if err := h.editor.ValidateExpr(text); err != nil {
+			return c.Send("Условие не компилируется: " + err.Error())
+		}
+
+		d.Evaluation = text
+	default:
+		return nil
+	}
+
+	h.state.setDraft(userID, d)
+
+	return h.nextReportStep(c, d, state)
+}
+
+// ReportToggleFormat включает или выключает формат экспорта.
+func (h *AdminHandler) ReportToggleFormat(c tele.Context) error {
+	d, ok := h.reportDraft(c, reportFormatsState)
+	if !ok {
+		return c.Edit(reportDraftExpired)
+	}
+
+	d.Formats = toggle(d.Formats, c.Data())
+	h.state.setDraft(c.Sender().ID, d)
+
+	if err := c.Respond(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	formats, err := h.editor.Formats(ctx)
+	if err != nil {
+		return c.Edit("Ошибка получения форматов: " + err.Error())
+	}
+
+	return editIgnoringNotModified(c, reportFormatsPrompt, reportFormatsMarkup(formats, d.Formats))
+}
+
+// ReportFormatsDone завершает выбор форматов.
+func (h *AdminHandler) ReportFormatsDone(c tele.Context) error {
+	d, ok := h.reportDraft(c, reportFormatsState)
+	if !ok {
+		return c.Edit(reportDraftExpired)
+	}
+
+	if len(d.Formats) == 0 {
+		return c.Respond(&tele.CallbackResponse{Text: "Выберите хотя бы один формат", ShowAlert: true})
+	}
+
+	if err := c.Respond(); err != nil {
+		return err
+	}
+
+	//nolint:errcheck
+	c.Edit("Форматы: " + strings.Join(d.Formats, ", "))
+
+	return h.nextReportStep(c, d, reportFormatsState)
+}
+
+// ReportToggleChat включает или выключает отправку отчета в чат.
+func (h *AdminHandler) ReportToggleChat(c tele.Context) error {
+	d, ok := h.reportDraft(c, reportChatsState)
+	if !ok {
+		return c.Edit(reportDraftExpired)
+	}
+
+	id, err := strconv.Atoi(c.Data())
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "Не удалось определить чат"})
+	}
+
+	d.ChatIDs = toggle(d.ChatIDs, id)
+	h.state.setDraft(c.Sender().ID, d)
+
+	if err := c.Respond(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	chats, err := h.editor.Chats(ctx)
+	if err != nil {
+		return c.Edit("Ошибка получения чатов: " + err.Error())
+	}
+
+	return editIgnoringNotModified(c, reportChatsPrompt, reportChatsMarkup(chats, d.ChatIDs))
+}
+
+// ReportChatsDone завершает выбор чатов.
+func (h *AdminHandler) ReportChatsDone(c tele.Context) error {
+	d, ok := h.reportDraft(c, reportChatsState)
+	if !ok {
+		return c.Edit(reportDraftExpired)
+	}
+
+	if len(d.ChatIDs) == 0 && d.OtherRecipients == 0 {
+		return c.Respond(&tele.CallbackResponse{Text: "Выберите хотя бы один чат", ShowAlert: true})
+	}
+
+	if err := c.Respond(); err != nil {
+		return err
+	}
+
+	//nolint:errcheck
+	c.Edit(fmt.Sprintf("Выбрано чатов: %d", len(d.ChatIDs)))
+
+	return h.nextReportStep(c, d, reportChatsState)
+}
+
+// ReportPickCron выбирает существующее расписание или отключает расписание.
+func (h *AdminHandler) ReportPickCron(c tele.Context) error {
+	d, ok := h.reportDraft(c, reportCronState)
+	if !ok {
+		return c.Edit(reportDraftExpired)
+	}
+
+	d.Crontabs = nil
+
+	if c.Data() != "" {
+		id, err := strconv.Atoi(c.Data())
+		if err != nil {
+			return c.Respond(&tele.CallbackResponse{Text: "Не удалось определить расписание"})
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+
+		cron, err := h.editor.Cron(ctx, id)
+		if err != nil {
+			return c.Respond(&tele.CallbackResponse{Text: "Расписание не найдено"})
+		}
+
+		d.Crontabs = []string{string(cron.Cron)}
+	}
+
+	h.state.setDraft(c.Sender().ID, d)
+
+	if err := c.Respond(); err != nil {
+		return err
+	}
+
+	//nolint:errcheck
+	c.Edit("Расписание: " + formatCrontabs(d.Crontabs))
+
+	return h.nextReportStep(c, d, reportCronState)
+}
+
+// ReportAlwaysSend ставит условие «отправлять всегда».
+func (h *AdminHandler) ReportAlwaysSend(c tele.Context) error {
+	d, ok := h.reportDraft(c, reportExprState)
+	if !ok {
+		return c.Edit(reportDraftExpired)
+	}
+
+	d.Evaluation = alwaysSendExpr
+	h.state.setDraft(c.Sender().ID, d)
+
+	if err := c.Respond(); err != nil {
+		return err
+	}
+
+	//nolint:errcheck
+	c.Edit("Условие: " + alwaysSendExpr)
+
+	return h.nextReportStep(c, d, reportExprState)
+}
+
+// ReportEditField открывает шаг редактирования поля из сводки.
+func (h *AdminHandler) ReportEditField(c tele.Context) error {
+	d, ok := h.reportDraft(c, reportSummaryState)
+	if !ok {
+		return c.Edit(reportDraftExpired)
+	}
+
+	step := c.Data()
+	if !slices.Contains(reportSteps, step) || step == reportSummaryState ||
+		(step == reportNameState && d.ID != 0) {
+		return c.Respond(&tele.CallbackResponse{Text: "Это поле нельзя изменить"})
+	}
+
+	if err := c.Respond(); err != nil {
+		return err
+	}
+
+	//nolint:errcheck
+	c.Delete()
+
+	return h.promptReportStep(c, step)
+}
+
+// ReportToggleActive включает или выключает отчет в черновике.
+func (h *AdminHandler) ReportToggleActive(c tele.Context) error {
+	d, ok := h.reportDraft(c, reportSummaryState)
+	if !ok {
+		return c.Edit(reportDraftExpired)
+	}
+
+	d.Active = !d.Active
+	h.state.setDraft(c.Sender().ID, d)
+
+	if err := c.Respond(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	return editIgnoringNotModified(c, h.formatDraft(ctx, d), reportSummaryMarkup(d))
+}
+
+// ReportToggleSubscribable разрешает или запрещает чатам подписываться на отчет.
+func (h *AdminHandler) ReportToggleSubscribable(c tele.Context) error {
+	d, ok := h.reportDraft(c, reportSummaryState)
+	if !ok {
+		return c.Edit(reportDraftExpired)
+	}
+
+	d.Subscribable = !d.Subscribable
+	h.state.setDraft(c.Sender().ID, d)
+
+	if err := c.Respond(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	return editIgnoringNotModified(c, h.formatDraft(ctx, d), reportSummaryMarkup(d))
+}
+
+// ReportSave сохраняет отчет.
+func (h *AdminHandler) ReportSave(c tele.Context) error {
+	userID := c.Sender().ID
+
+	d, ok := h.reportDraft(c, reportSummaryState)
+	if !ok {
+		return c.Edit(reportDraftExpired)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	if _, err := h.editor.Save(ctx, d.ReportDraft, actor(c)); err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "Не удалось сохранить: " + err.Error(), ShowAlert: true})
+	}
+
+	if err := c.Respond(); err != nil {
+		return err
+	}
+
+	h.state.delete(userID)
+	h.state.set(userID, menuState)
+
+	return c.Edit(fmt.Sprintf("Отчет %s (%s) сохранен", d.Title, d.Name))
+}
+
+// ReportCancel отменяет редактирование отчета.
+func (h *AdminHandler) ReportCancel(c tele.Context) error {
+	userID := c.Sender().ID
+
+	h.state.delete(userID)
+	h.state.set(userID, menuState)
+
+	if err := c.Respond(); err != nil {
+		return err
+	}
+
+	return c.Edit("Изменения отчета отменены")
+}
+
+// nextReportStep открывает следующий шаг создания отчета или возвращает к сводке.
+func (h *AdminHandler) nextReportStep(c tele.Context, d *reportDraft, done string) error {
+	if !d.Wizard {
+		return h.promptReportStep(c, reportSummaryState)
+	}
+
+	i := slices.Index(reportSteps, done)
+	if i < 0 || i+1 >= len(reportSteps) {
+		return h.promptReportStep(c, reportSummaryState)
+	}
+
+	return h.promptReportStep(c, reportSteps[i+1])
+}
+
+// promptReportStep переводит пользователя на шаг step и отправляет подсказку к нему.
+func (h *AdminHandler) promptReportStep(c tele.Context, step string) error {
+	userID := c.Sender().ID
+
+	d, ok := h.state.getDraft(userID)
+	if !ok {
+		return c.Send(reportDraftExpired)
+	}
+
+	h.state.set(userID, step)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	switch step {
+	case reportNameState:
+		return c.Send("Пришлите имя отчета: латинские буквы в нижнем регистре, цифры и _, например daily_sales")
+	case reportTitleState:
+		return c.Send("Пришлите название отчета, оно показывается в меню и подписях")
+	case reportCardsState:
+		return c.Send(
+			"Пришлите карточки Metabase, по одной на строку:\n<uuid публичной карточки> <ключ>\n\n" +
+				"Ключ используется в шаблоне и условии отправки: report.<ключ>" +
+				formatCurrentCards(d.Cards),
+		)
+	case reportFormatsState:
+		formats, err := h.editor.Formats(ctx)
+		if err != nil {
+			return c.Send("Ошибка получения форматов: " + err.Error())
+		}
+
+		return c.Send(reportFormatsPrompt, reportFormatsMarkup(formats, d.Formats))
+	case reportChatsState:
+		chats, err := h.editor.Chats(ctx)
+		if err != nil {
+			return c.Send("Ошибка получения чатов: " + err.Error())
+		}
+
+		if len(chats) == 0 && d.OtherRecipients == 0 {
+			return c.Send("Нет активных чатов. Добавьте бота в чат и выполните в нем /sub, затем повторите выбор.",
+				reportChatsMarkup(chats, d.ChatIDs))
+		}
+
+		return c.Send(reportChatsPrompt, reportChatsMarkup(chats, d.ChatIDs))
+	case reportCronState:
+		crons, err := h.editor.Crons(ctx)
+		if err != nil {
+			return c.Send("Ошибка получения расписаний: " + err.Error())
+		}
+
+		return c.Send(
+			"Выберите расписание или пришлите cron-выражение из 5 полей, например: 0 9 * * 1-5\n"+
+				"Сейчас: "+formatCrontabs(d.Crontabs),
+			reportCronsMarkup(crons),
+		)
+	case reportExprState:
+		return c.Send(
+			"Пришлите CEL-условие отправки, например: report.sales.size() > 0\n"+
+				"[*] — отправлять всегда, [!*] — никогда.\nСейчас: "+d.Evaluation,
+			reportExprMarkup(),
+		)
+	case reportSummaryState:
+		d.Wizard = false
+		h.state.setDraft(userID, d)
+
+		return c.Send(h.formatDraft(ctx, d), reportSummaryMarkup(d))
+	default:
+		return nil
+	}
+}
+
+// reportDraft возвращает черновик пользователя, если он находится на одном из шагов states.
+func (h *AdminHandler) reportDraft(c tele.Context, states ...string) (*reportDraft, bool) {
+	userID := c.Sender().ID
+
+	if !slices.Contains(states, h.state.get(userID)) {
+		return nil, false
+	}
+
+	return h.state.getDraft(userID)
+}
+
+func (h *AdminHandler) formatDraft(ctx context.Context, d *reportDraft) string {
+	var b strings.Builder
+
+	if d.ID == 0 {
+		fmt.Fprintf(&b, "Новый отчет %s (%s)\n", d.Title, d.Name)
+	} else {
+		fmt.Fprintf(&b, "Отчет %s (%s)\n", d.Title, d.Name)
+	}
+
+	if d.Active {
+		b.WriteString("Состояние: включен\n")
+	} else {
+		b.WriteString("Состояние: выключен\n")
+	}
+
+	if d.Subscribable {
+		b.WriteString("Подписка из чатов: разрешена\n")
+	} else {
+		b.WriteString("Подписка из чатов: запрещена\n")
+	}
+
+	b.WriteString("\nКарточки:\n")
+
+	for _, c := range d.Cards {
+		fmt.Fprintf(&b, "  • %s — %s\n", c.Title, c.CardUUID)
+	}
+
+	fmt.Fprintf(&b, "Форматы: %s\n", strings.Join(d.Formats, ", "))
+
+	titles := make([]string, 0, len(d.ChatIDs))
+
+	if chats, err := h.editor.Chats(ctx); err == nil {
+		for _, ch := range chats {
+			if slices.Contains(d.ChatIDs, ch.ID) {
+				titles = append(titles, ch.Title)
+			}
+		}
+	}
+
+	fmt.Fprintf(&b, "Чаты: %s\n", strings.Join(titles, ", "))
+
+	if d.OtherRecipients > 0 {
+		fmt.Fprintf(&b, "Других получателей: %d (редактируются в базе)\n", d.OtherRecipients)
+	}
+
+	fmt.Fprintf(&b, "Расписание: %s\n", formatCrontabs(d.Crontabs))
+	fmt.Fprintf(&b, "Условие: %s\n", d.Evaluation)
+
+	if d.NeedsTemplate() {
+		b.WriteString("\n⚠️ Для форматов text, html, png и pdf нужен шаблон: привяжите его в report_templates.\n")
+	}
+
+	return b.String()
+}
+
+func toggle[T comparable](s []T, v T) []T {
+	if i := slices.Index(s, v); i >= 0 {
+		return slices.Delete(s, i, i+1)
+	}
+
+	return append(s, v)
+}
@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tele "gopkg.in/telebot.v4"
+	"support_bot/internal/models"
+	"support_bot/internal/tg_bot/service"
+)
+
+const reportParamsExpired = "Время на выбор параметров истекло, начните заново"
+
+// reportRun — ручной запуск отчета, для которого бот по очереди запрашивает параметры.
+type reportRun struct {
+	Report string               `json:"report"`
+	Params []models.ReportParam `json:"params"`
+	Values map[string]string    `json:"values"`
+
+	Step int `json:"step"`
+	// RangeFrom — выбранное начало периода, пока пользователь выбирает конец.
+	RangeFrom string `json:"range_from,omitempty"`
+}
+
+func (r *reportRun) current() models.ReportParam {
+	return r.Params[r.Step]
+}
+
+// startReportRun запускает отчет из списка. Если у отчета есть параметры,
+// сначала запрашивает их значения.
+func startReportRun(c tele.Context, report *service.Report, state *State) error {
+	userID := c.Sender().ID
+	if state.get(userID) != loadReportState {
+		return c.Edit("Время на выбор отчета истекло, начните заново")
+	}
+
+	// Имя отчета из callback не используется: его можно подделать, имя берется по проверенному id.
+	id, _, ok := strings.Cut(c.Data(), ";")
+	if !ok {
+		return c.Respond(&tele.CallbackResponse{Text: "Не удалось определить отчет"})
+	}
+
+	reportID, err := strconv.Atoi(id)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "Не удалось определить отчет"})
+	}
+
+	if err := c.Respond(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	reportName, err := report.CheckAccess(ctx, userID, reportID)
+	if err != nil {
+		if errors.Is(err, models.ErrForbidden) {
+			return c.Edit("Этот отчет вам недоступен")
+		}
+
+		return c.Edit("Не удалось проверить доступ к отчету: " + err.Error())
+	}
+
+	params, err := report.ReportParams(ctx, reportID)
+	if err != nil {
+		return c.Edit("Не удалось загрузить параметры отчета: " + err.Error())
+	}
+
+	if len(params) == 0 {
+		return runReport(c, report, state, reportName, nil)
+	}
+
+	run := &reportRun{
+		Report: reportName,
+		Params: params,
+		Values: make(map[string]string, len(params)),
+	}
+
+	state.setRun(userID, run)
+	state.set(userID, reportParamsState)
+
+	return promptReportParam(c, run, time.Now())
+}
+
+func runReport(
+	c tele.Context,
+	report *service.Report,
+	state *State,
+	reportName string,
+	values map[string]string,
+) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	chat := &models.Chat{
+		ChatID:   c.Chat().ID,
+		Title:    &c.Chat().FirstName,
+		Type:     string(c.Chat().Type),
+		IsActive: true,
+	}
+
+	if err := report.GenerateReportByName(ctx, reportName, chat, values); err != nil {
+		return c.Edit("Не удалось запустить отчет: " + err.Error())
+	}
+
+	state.set(c.Sender().ID, menuState)
+
+	return editIgnoringNotModified(c, "Отчет запущен. Результат придет в этот чат."+formatParamValues(values))
+}
+
+// promptReportParam запрашивает текущий параметр. month — месяц, который показывает календарь.
+func promptReportParam(c tele.Context, run *reportRun, month time.Time) error {
+	p := run.current()
+
+	switch p.Type {
+	case models.ParamDate:
+		return editIgnoringNotModified(c, "Выберите дату: "+p.Title, calendarMarkup(month))
+	case models.ParamDateRange:
+		if run.RangeFrom == "" {
+			return editIgnoringNotModified(c, "Выберите начало периода: "+p.Title, calendarMarkup(month))
+		}
+
+		return editIgnoringNotModified(
+			c,
+			fmt.Sprintf("Выберите конец периода: %s\nНачало: %s", p.Title, run.RangeFrom),
+			calendarMarkup(month),
+		)
+	default:
+		return editIgnoringNotModified(c, "Выберите значение: "+p.Title, paramOptionsMarkup(p.Options))
+	}
+}
+
+// setReportParam сохраняет значение текущего параметра и переходит к следующему
+// или запускает отчет, если все параметры заданы.
+func (h *UserHandler) setReportParam(c tele.Context, run *reportRun, value string) error {
+	p := run.current()
+
+	if err := p.Validate(value); err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "Некорректное значение"})
+	}
+
+	if err := c.Respond(); err != nil {
+		return err
+	}
+
+	run.Values[p.Name] = value
+	run.RangeFrom = ""
+	run.Step++
+
+	if run.Step < len(run.Params) {
+		h.state.setRun(c.Sender().ID, run)
+		h.state.set(c.Sender().ID, reportParamsState)
+
+		return promptReportParam(c, run, time.Now())
+	}
+
+	return runReport(c, h.report, h.state, run.Report, run.Values)
+}
+
+func (h *UserHandler) reportRun(c tele.Context) (*reportRun, bool) {
+	userID := c.Sender().ID
+	if h.state.get(userID) != reportParamsState {
+		return nil, false
+	}
+
+	return h.state.getRun(userID)
+}
+
+// ReportParamMonth листает календарь.
+func (h *UserHandler) ReportParamMonth(c tele.Context) error {
+	run, ok := h.reportRun(c)
+	if !ok {
+		return c.Edit(reportParamsExpired)
+	}
+
+	month, err := time.ParseInLocation(calendarMonthLayout, c.Data(), time.Local)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "Не удалось определить месяц"})
+	}
+
+	if err := c.Respond(); err != nil {
+		return err
+	}
+
+	return promptReportParam(c, run, month)
+}
+
+// ReportParamDay принимает дату из календаря.
+func (h *UserHandler) ReportParamDay(c tele.Context) error {
+	run, ok := h.reportRun(c)
+	if !ok {
+		return c.Edit(reportParamsExpired)
+	}
+
+	day, err := time.ParseInLocation(models.ParamDateLayout, c.Data(), time.Local)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "Не удалось определить дату"})
+	}
+
+	p := run.current()
+
+	switch p.Type {
+	case models.ParamDate:
+		return h.setReportParam(c, run, c.Data())
+	case models.ParamDateRange:
+		if run.RangeFrom == "" {
+			run.RangeFrom = c.Data()
+			h.state.setRun(c.Sender().ID, run)
+
+			if err := c.Respond(); err != nil {
+				return err
+			}
+
+			return promptReportParam(c, run, day)
+		}
+
+		from, _ := time.ParseInLocation(models.ParamDateLayout, run.RangeFrom, time.Local)
+		if day.Before(from) {
+			return c.Respond(&tele.CallbackResponse{Text: "Конец периода раньше начала"})
+		}
+
+		return h.setReportParam(c, run, models.DateRange(from, day))
+	default:
+		return c.Respond(&tele.CallbackResponse{Text: "Выберите значение из списка"})
+	}
+}
+
+// ReportParamOption принимает значение параметра-списка.
+func (h *UserHandler) ReportParamOption(c tele.Context) error {
+	run, ok := h.reportRun(c)
+	if !ok {
+		return c.Edit(reportParamsExpired)
+	}
+
+	i, err := strconv.Atoi(c.Data())
+
+	p := run.current()
+	if err != nil || p.Type != models.ParamEnum || i < 0 || i >= len(p.Options) {
+		return c.Respond(&tele.CallbackResponse{Text: "Не удалось определить значение"})
+	}
+
+	return h.setReportParam(c, run, p.Options[i])
+}
+
+func (h *UserHandler) ReportParamCancel(c tele.Context) error {
+	if err := c.Respond(); err != nil {
+		return err
+	}
+
+	h.state.set(c.Sender().ID, menuState)
+
+	return editIgnoringNotModified(c, "Запуск отчета отменен")
+}
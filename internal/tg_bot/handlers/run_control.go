@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"support_bot/internal/errorz"
+	"support_bot/internal/models"
+
+	tele "gopkg.in/telebot.v4"
+)
+
+const runControlExpired = "Время на управление рассылками истекло, начните заново"
+
+// RunControls показывает действующие паузы и предлагает выбрать расписание или отчет.
+func (h *AdminHandler) RunControls(c tele.Context) error {
+	h.state.set(c.Sender().ID, runControlState)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	controls, err := h.report.ActiveControls(ctx)
+	if err != nil {
+		return c.Send("Ошибка получения состояния рассылок: " + err.Error())
+	}
+
+	return c.Send(formatActiveControls(controls), controlTargetsMarkup())
+}
+
+// RunControlList показывает страницу расписаний или отчетов.
+func (h *AdminHandler) RunControlList(c tele.Context) error {
+	userID := c.Sender().ID
+	if !h.inRunControl(userID) {
+		return c.Edit(runControlExpired)
+	}
+
+	target, page, ok := parseControlData(c.Data())
+	if !ok {
+		return c.Respond(&tele.CallbackResponse{Text: "Не удалось определить страницу"})
+	}
+
+	if err := c.Respond(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	rpl, err := h.report.LoadControlPage(ctx, target, page)
+	if err != nil {
+		return c.Edit("Ошибка получения списка: " + err.Error())
+	}
+
+	mark := mapControlRPLToMarkup(rpl)
+
+	h.state.set(userID, runControlState)
+
+	return editIgnoringNotModified(c, "Выберите расписание или отчет", &mark)
+}
+
+// RunControlItem показывает состояние расписания или отчета и доступные действия.
+func (h *AdminHandler) RunControlItem(c tele.Context) error {
+	userID := c.Sender().ID
+	if !h.inRunControl(userID) {
+		return c.Edit(runControlExpired)
+	}
+
+	if err := c.Respond(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	item, err := h.controlItem(ctx, c.Data())
+	if err != nil {
+		return c.Edit("Не удалось найти расписание или отчет: " + err.Error())
+	}
+
+	h.state.set(userID, runControlState)
+
+	return editIgnoringNotModified(c, formatControlItem(item), controlItemMarkup(item))
+}
+
+// RunControlPause запрашивает дату окончания паузы.
+func (h *AdminHandler) RunControlPause(c tele.Context) error {
+	userID := c.Sender().ID
+	if !h.inRunControl(userID) {
+		return c.Edit(runControlExpired)
+	}
+
+	if err := c.Respond(); err != nil {
+		return err
+	}
+
+	h.state.set(userID, pauseUntilState)
+	h.state.setMsgData(userID, c.Data())
+
+	return c.Edit(
+		"Пришлите дату окончания паузы в формате ДД.ММ.ГГГГ или ДД.ММ.ГГГГ ЧЧ:ММ.\n" +
+			"Запуски возобновятся в указанный момент.",
+	)
+}
+
+// processPauseUntil ставит паузу до присланной даты.
+func (h *AdminHandler) processPauseUntil(c tele.Context) error {
+	userID := c.Sender().ID
+
+	data, ok := h.state.getMsgData(userID)
+	if !ok {
+		return c.Send(runControlExpired)
+	}
+
+	until, err := parsePauseUntil(c.Text())
+	if err != nil {
+		return c.Send("Не удалось разобрать дату, пришлите ее в формате ДД.ММ.ГГГГ или ДД.ММ.ГГГГ ЧЧ:ММ")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	item, err := h.controlItem(ctx, data)
+	if err != nil {
+		return c.Send("Не удалось найти расписание или отчет: " + err.Error())
+	}
+
+	if err := h.report.PauseItem(ctx, item, until, actor(c)); err != nil {
+		return c.Send("Не удалось поставить паузу: " + err.Error())
+	}
+
+	h.state.set(userID, runControlState)
+
+	item, err = h.controlItem(ctx, data)
+	if err != nil {
+		return c.Send("Пауза установлена")
+	}
+
+	return c.Send("Пауза установлена\n\n"+formatControlItem(item), controlItemMarkup(item))
+}
+
+// RunControlSkip пропускает следующий запуск.
+func (h *AdminHandler) RunControlSkip(c tele.Context) error {
+	return h.applyRunControl(c, "Следующий запуск будет пропущен", h.report.SkipNextItem)
+}
+
+// RunControlResume снимает паузу и пропуск следующего запуска.
+func (h *AdminHandler) RunControlResume(c tele.Context) error {
+	return h.applyRunControl(c, "Запуски возобновлены", h.report.ResumeItem)
+}
+
+// RunControlRun запускает расписание или отчет сейчас, даже если он на паузе.
+func (h *AdminHandler) RunControlRun(c tele.Context) error {
+	return h.applyRunControl(c, "Запуск начат", h.report.RunItemNow)
+}
+
+func (h *AdminHandler) applyRunControl(
+	c tele.Context,
+	done string,
+	apply func(ctx context.Context, item models.ControlItem, by string) error,
+) error {
+	userID := c.Sender().ID
+	if !h.inRunControl(userID) {
+		return c.Edit(runControlExpired)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	item, err := h.controlItem(ctx, c.Data())
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "Не удалось найти расписание или отчет"})
+	}
+
+	if err := apply(ctx, item, actor(c)); err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "Ошибка: " + err.Error(), ShowAlert: true})
+	}
+
+	if err := c.Respond(&tele.CallbackResponse{Text: done}); err != nil {
+		return err
+	}
+
+	h.state.set(userID, runControlState)
+
+	item, err = h.controlItem(ctx, c.Data())
+	if err != nil {
+		return c.Edit(done)
+	}
+
+	return editIgnoringNotModified(c, done+"\n\n"+formatControlItem(item), controlItemMarkup(item))
+}
+
+func (h *AdminHandler) inRunControl(userID int64) bool {
+	state := h.state.get(userID)
+
+	return state == runControlState || state == pauseUntilState
+}
+
+func (h *AdminHandler) controlItem(ctx context.Context, data string) (models.ControlItem, error) {
+	target, id, ok := parseControlData(data)
+	if !ok {
+		return models.ControlItem{}, errorz.ErrNotFound
+	}
+
+	return h.report.GetControlItem(ctx, target, id)
+}
+
+func editIgnoringNotModified(c tele.Context, what any, opts ...any) error {
+	if err := c.Edit(what, opts...); err != nil {
+		if errors.Is(err, tele.ErrMessageNotModified) || isMessageNotModified(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	return nil
+}
@@ -1,8 +1,11 @@
 package handlers
 
 import (
-	"sync"
+	"context"
+	"log/slog"
 	"time"
+
+	"support_bot/internal/tg_bot/state"
 )
 
 const (
@@ -17,88 +20,140 @@ const (
 	menuState = "menu"
 
 	loadReportState = "load_report"
+
+	runControlState = "run_control"
+	pauseUntilState = "pause_until"
+
+	reportPickState    = "report_pick"
+	reportNameState    = "report_name"
+	reportTitleState   = "report_title"
+	reportCardsState   = "report_cards"
+	reportFormatsState = "report_formats"
+	reportChatsState   = "report_chats"
+	reportCronState    = "report_cron"
+	reportExprState    = "report_expr"
+	reportSummaryState = "report_summary"
+
+	reportParamsState = "report_params"
+
+	inviteState = "invite"
 )
 
+// Session — состояние диалога пользователя: текущий шаг и данные, введенные на предыдущих шагах.
+type Session struct {
+	Step string `json:"step"`
+	// Msg — произвольная строка шага, например выбранное расписание для паузы.
+	Msg    *string      `json:"msg,omitempty"`
+	Draft  *reportDraft `json:"draft,omitempty"`
+	Run    *reportRun   `json:"run,omitempty"`
+	Invite *inviteDraft `json:"invite,omitempty"`
+}
+
+// State читает и изменяет состояние диалогов в хранилище. Каждое изменение
+// продлевает жизнь состояния на cleanUpTime.
 type State struct {
-	s           map[int64]string
-	msg         map[int64]string
-	timers      map[int64]*time.Timer // Храним таймеры для каждого чата
-	mu          sync.RWMutex
+	store       state.Store[Session]
 	cleanUpTime time.Duration
+	log         *slog.Logger
 }
 
-func NewState(cleanUpTime time.Duration) *State {
+func NewState(store state.Store[Session], cleanUpTime time.Duration, log *slog.Logger) *State {
+	l := log.With(slog.Any("module", "tg_bot.handlers.state"))
+
 	return &State{
-		s:           make(map[int64]string),
-		msg:         make(map[int64]string),
-		timers:      make(map[int64]*time.Timer),
-		mu:          sync.RWMutex{},
+		store:       store,
 		cleanUpTime: cleanUpTime,
+		log:         l,
 	}
 }
 
-func (s *State) set(chatID int64, state string) {
-	s.mu.Lock()
-	s.s[chatID] = state
-	s.mu.Unlock()
-	s.cleanUpAfter(chatID, s.cleanUpTime)
+// storeTimeout — время на одно обращение к хранилищу состояния.
+const storeTimeout = 5 * time.Second
+
+// load возвращает состояние пользователя. Ошибка хранилища логируется,
+// и диалог продолжается с пустым состоянием.
+func (s *State) load(chatID int64) Session {
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	sess, _, err := s.store.Get(ctx, chatID)
+	if err != nil {
+		s.log.ErrorContext(ctx, "unable to load bot session", slog.Any("chat", chatID), slog.Any("error", err))
+	}
+
+	return sess
 }
 
-func (s *State) get(chatID int64) string {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+// update изменяет состояние пользователя атомарно. Если состояние не удалось прочитать,
+// оно не перезаписывается, чтобы не потерять черновик.
+func (s *State) update(chatID int64, fn func(*Session)) {
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	if err := s.store.Update(ctx, chatID, fn, s.cleanUpTime); err != nil {
+		s.log.ErrorContext(ctx, "unable to update bot session", slog.Any("chat", chatID), slog.Any("error", err))
+	}
+}
+
+func (s *State) set(chatID int64, step string) {
+	s.update(chatID, func(sess *Session) { sess.Step = step })
+}
 
-	return s.s[chatID]
+func (s *State) get(chatID int64) string {
+	return s.load(chatID).Step
 }
 
 func (s *State) setMsgData(chatID int64, msg string) {
-	s.mu.Lock()
-	s.msg[chatID] = msg
-	s.mu.Unlock()
-	s.cleanUpAfter(chatID, s.cleanUpTime)
+	s.update(chatID, func(sess *Session) { sess.Msg = &msg })
 }
 
 func (s *State) getMsgData(chatID int64) (string, bool) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	msg := s.load(chatID).Msg
+	if msg == nil {
+		return "", false
+	}
 
-	data, ok := s.msg[chatID]
+	return *msg, true
+}
 
-	return data, ok
+// setDraft сохраняет черновик отчета, который редактирует пользователь.
+func (s *State) setDraft(chatID int64, d *reportDraft) {
+	s.update(chatID, func(sess *Session) { sess.Draft = d })
 }
 
-func (s *State) delete(chatID int64) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+func (s *State) getDraft(chatID int64) (*reportDraft, bool) {
+	d := s.load(chatID).Draft
 
-	delete(s.s, chatID)
-	delete(s.msg, chatID)
+	return d, d != nil
+}
 
-	if timer, exists := s.timers[chatID]; exists {
-		timer.Stop()
-		delete(s.timers, chatID)
-	}
+// setRun сохраняет параметры ручного запуска отчета, которые вводит пользователь.
+func (s *State) setRun(chatID int64, r *reportRun) {
+	s.update(chatID, func(sess *Session) { sess.Run = r })
 }
 
-// Очистка с отменой старого таймера.
-func (s *State) cleanUpAfter(chatID int64, d time.Duration) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+func (s *State) getRun(chatID int64) (*reportRun, bool) {
+	r := s.load(chatID).Run
 
-	// Отменяем старый таймер, если он существует
-	if oldTimer, exists := s.timers[chatID]; exists {
-		oldTimer.Stop()
-	}
+	return r, r != nil
+}
 
-	// Создаем новый таймер
-	timer := time.AfterFunc(d, func() {
-		s.mu.Lock()
-		delete(s.s, chatID)
-		delete(s.msg, chatID)
-		delete(s.timers, chatID)
-		s.mu.Unlock()
-	})
-
-	// Сохраняем таймер в map
-	s.timers[chatID] = timer
+// setInvite сохраняет приглашение, которое настраивает администратор.
+func (s *State) setInvite(chatID int64, d *inviteDraft) {
+	s.update(chatID, func(sess *Session) { sess.Invite = d })
+}
+
+func (s *State) getInvite(chatID int64) (*inviteDraft, bool) {
+	d := s.load(chatID).Invite
+
+	return d, d != nil
+}
+
+func (s *State) delete(chatID int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	if err := s.store.Delete(ctx, chatID); err != nil {
+		s.log.ErrorContext(ctx, "unable to delete bot session", slog.Any("chat", chatID), slog.Any("error", err))
+	}
 }
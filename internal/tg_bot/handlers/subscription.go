@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	tele "gopkg.in/telebot.v4"
+	"support_bot/internal/errorz"
+	"support_bot/internal/models"
+	"support_bot/internal/tg_bot/service"
+)
+
+const msgSubscribe = `Отчеты, на которые можно подписать этот чат. ✅ — чат уже подписан, нажмите, чтобы отписаться`
+
+// SubscriptionHandler управляет подписками чата на отчеты.
+// Права проверяет middleware: в личном чате — пользователь бота, в группе — зарегистрированный администратор группы.
+type SubscriptionHandler struct {
+	bot  *tele.Bot
+	subs *service.Subscription
+}
+
+func NewSubscriptionHandler(bot *tele.Bot, subs *service.Subscription) *SubscriptionHandler {
+	return &SubscriptionHandler{
+		bot:  bot,
+		subs: subs,
+	}
+}
+
+// Subscribe показывает отчеты, доступные для подписки текущего чата и топика.
+func (h *SubscriptionHandler) Subscribe(c tele.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	rpl, err := h.subs.LoadPage(ctx, c.Sender().ID, c.Chat().ID, topicID(c), 1)
+	if err != nil {
+		return c.Send("Нет отчетов, доступных для подписки")
+	}
+
+	mark := mapSubscriptionRPLToMarkup(rpl)
+
+	return c.Send(msgSubscribe, &mark)
+}
+
+func (h *SubscriptionHandler) SubscribePage(c tele.Context) error {
+	page, err := strconv.Atoi(c.Data())
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "Не удалось определить страницу"})
+	}
+
+	if err := c.Respond(); err != nil {
+		return err
+	}
+
+	return h.editPage(c, page)
+}
+
+// ToggleSubscription подписывает чат на отчет или отменяет подписку.
+func (h *SubscriptionHandler) ToggleSubscription(c tele.Context) error {
+	reportID, page, ok := parseSubscriptionData(c.Data())
+	if !ok {
+		return c.Respond(&tele.CallbackResponse{Text: "Не удалось определить отчет"})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	subscribed, err := h.subs.Toggle(ctx, c.Sender().ID, chatDTO(c), topicID(c), reportID, actor(c))
+	if err != nil {
+		if errors.Is(err, errorz.ErrNotFound) {
+			return c.Respond(&tele.CallbackResponse{Text: "Отчет больше недоступен для подписки"})
+		}
+
+		return c.Respond(&tele.CallbackResponse{Text: "Не удалось изменить подписку"})
+	}
+
+	text := "Подписка отменена"
+	if subscribed {
+		text = "Чат подписан на отчет"
+	}
+
+	if err := c.Respond(&tele.CallbackResponse{Text: text}); err != nil {
+		return err
+	}
+
+	return h.editPage(c, page)
+}
+
+// Unsubscribe показывает подписки текущего чата и топика, от которых можно отписаться.
+func (h *SubscriptionHandler) Unsubscribe(c tele.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	subs, err := h.subs.Unsubscribable(ctx, c.Chat().ID, topicID(c))
+	if err != nil {
+		return c.Send("Ошибка получения подписок: " + err.Error())
+	}
+
+	if len(subs) == 0 {
+		return c.Send("Здесь нет подписок, которые можно отменить")
+	}
+
+	return c.Send("Выберите отчет, от которого нужно отписаться", unsubscribeMarkup(subs))
+}
+
+func (h *SubscriptionHandler) UnsubscribeReport(c tele.Context) error {
+	reportID, err := strconv.Atoi(c.Data())
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "Не удалось определить отчет"})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	chatID, threadID := c.Chat().ID, topicID(c)
+
+	err = h.subs.Unsubscribe(ctx, chatID, threadID, reportID, actor(c))
+	if err != nil && !errors.Is(err, errorz.ErrNotFound) {
+		return c.Respond(&tele.CallbackResponse{Text: "Не удалось отменить подписку"})
+	}
+
+	if err := c.Respond(&tele.CallbackResponse{Text: "Подписка отменена"}); err != nil {
+		return err
+	}
+
+	subs, err := h.subs.Unsubscribable(ctx, chatID, threadID)
+	if err != nil || len(subs) == 0 {
+		return editIgnoringNotModified(c, "Подписок, которые можно отменить, больше нет")
+	}
+
+	return editIgnoringNotModified(c, "Выберите отчет, от которого нужно отписаться", unsubscribeMarkup(subs))
+}
+
+// Subscriptions показывает все отчеты, которые приходят в чат.
+func (h *SubscriptionHandler) Subscriptions(c tele.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	subs, err := h.subs.List(ctx, c.Chat().ID)
+	if err != nil {
+		return c.Send("Ошибка получения подписок: " + err.Error())
+	}
+
+	return c.Send(formatSubscriptions(subs))
+}
+
+func (h *SubscriptionHandler) editPage(c tele.Context, page int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	rpl, err := h.subs.LoadPage(ctx, c.Sender().ID, c.Chat().ID, topicID(c), page)
+	if err != nil {
+		return editIgnoringNotModified(c, "Нет отчетов, доступных для подписки")
+	}
+
+	mark := mapSubscriptionRPLToMarkup(rpl)
+
+	return editIgnoringNotModified(c, msgSubscribe, &mark)
+}
+
+// topicID возвращает топик форума, из которого пришло сообщение.
+// ThreadID ответов в обычных группах топиком не считается.
+func topicID(c tele.Context) int {
+	if m := c.Message(); m != nil && m.TopicMessage {
+		return m.ThreadID
+	}
+
+	return 0
+}
+
+// chatDTO описывает текущий чат для сохранения в chats.
+func chatDTO(c tele.Context) models.TgChatDTO {
+	chat := c.Chat()
+
+	title := chat.Title
+	if title == "" {
+		title = chat.FirstName
+		if chat.Username != "" {
+			title = "@" + chat.Username
+		}
+	}
+
+	return *models.NewTgChatDTO(chat.ID, title, string(chat.Type), chat.Description)
+}
@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -15,11 +14,12 @@ import (
 )
 
 type UserHandler struct {
-	bot         *tele.Bot
-	chatService *service.Chat
-	userService *service.User
-	report      *service.Report
-	state       *State
+	bot          *tele.Bot
+	chatService  *service.Chat
+	userService  *service.User
+	report       *service.Report
+	registration *service.Registration
+	state        *State
 }
 
 func NewUserHandler(
@@ -27,14 +27,16 @@ func NewUserHandler(
 	chatService *service.Chat,
 	userService *service.User,
 	reportService *service.Report,
+	registration *service.Registration,
 	state *State,
 ) *UserHandler {
 	return &UserHandler{
-		bot:         bot,
-		chatService: chatService,
-		userService: userService,
-		report:      reportService,
-		state:       state,
+		bot:          bot,
+		chatService:  chatService,
+		userService:  userService,
+		report:       reportService,
+		registration: registration,
+		state:        state,
 	}
 }
 
@@ -63,6 +65,7 @@ func (h *UserHandler) StartUser(c tele.Context) error {
 
 	menu.UserMenu.Reply(
 		menu.UserMenu.Row(menu.LoadAndShowReportUser),
+		menu.UserMenu.Row(menu.Subscriptions),
 	)
 	//nolint:errcheck
 	c.Delete()
@@ -79,7 +82,9 @@ func (h *UserHandler) RegisterUser(c tele.Context) error {
 		return nil
 	}
 
-	ctx := context.Background()
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
 	snd := models.NewUser(
 		c.Sender().ID,
 		c.Sender().Username,
@@ -87,19 +92,20 @@ func (h *UserHandler) RegisterUser(c tele.Context) error {
 		&c.Sender().LastName,
 		false,
 	)
-	err := h.userService.AddUserComplete(ctx, &snd)
-	// formatedString := fmt.Sprintf(
-	//	"Пользователь с ником @%s успешно прошел регистрацию",
-	//	c.Sender().Username,
-	//)
-	//nolint:errcheck
-	// h.notify.SendAdminNotify(ctx, formatedString)
 
-	if err == nil {
-		return c.Send("Вы успешно прошли регистрацию!\n напишите /start чтобы начать работу")
+	status, err := h.registration.Register(ctx, snd)
+	if err != nil {
+		return c.Send("Не удалось отправить заявку на регистрацию, попробуйте позже")
 	}
 
-	return nil
+	switch status {
+	case models.RegistrationApproved:
+		return c.Send(registrationApproved)
+	case models.RegistrationDenied:
+		return c.Send(registrationDenied)
+	default:
+		return c.Send("Заявка на регистрацию отправлена администраторам. Мы сообщим, когда ее рассмотрят")
+	}
 }
 
 func (h *UserHandler) LoadReports(c tele.Context) error {
@@ -110,7 +116,7 @@ func (h *UserHandler) LoadReports(c tele.Context) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
-	rpl, err := h.report.LoadReportsWithPagination(ctx)
+	rpl, err := h.report.LoadReportsWithPagination(ctx, c.Sender().ID)
 	if err != nil {
 		return c.Send("Ошибка получения отчетов: " + err.Error())
 	}
@@ -138,7 +144,7 @@ func (h *UserHandler) LoadReportsPage(c tele.Context) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
-	rpl, err := h.report.LoadReportByPage(ctx, page)
+	rpl, err := h.report.LoadReportByPage(ctx, c.Sender().ID, page)
 	if err != nil {
 		return c.Edit("Ошибка получения отчетов: " + err.Error())
 	}
@@ -163,45 +169,7 @@ func (h *UserHandler) IgnoreReportPage(c tele.Context) error {
 }
 
 func (h *UserHandler) GenerateSelectedReport(c tele.Context) error {
-	userID := c.Sender().ID
-	if h.state.get(userID) != loadReportState {
-		return c.Edit("Время на выбор отчета истекло, начните заново")
-	}
-
-	_, reportName, ok := strings.Cut(c.Data(), ";")
-	if !ok || reportName == "" {
-		return c.Respond(&tele.CallbackResponse{Text: "Не удалось определить отчет"})
-	}
-
-	if err := c.Respond(); err != nil {
-		return err
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-	defer cancel()
-
-	chat := &models.Chat{
-		ChatID:   c.Chat().ID,
-		Title:    &c.Chat().FirstName,
-		Type:     string(c.Chat().Type),
-		IsActive: true,
-	}
-
-	if err := h.report.GenerateReportByName(ctx, reportName, chat); err != nil {
-		return c.Edit("Не удалось запустить отчет: " + err.Error())
-	}
-
-	h.state.set(userID, menuState)
-
-	if err := c.Edit("Отчет запущен. Результат придет в этот чат."); err != nil {
-		if errors.Is(err, tele.ErrMessageNotModified) {
-			return nil
-		}
-
-		return err
-	}
-
-	return nil
+	return startReportRun(c, h.report, h.state)
 }
 
 func isMessageNotModified(err error) bool {
@@ -8,7 +8,10 @@ var (
 	Selector  = &telebot.ReplyMarkup{}
 )
 
-var LoadAndShowReportUser = UserMenu.Text("Отчеты")
+var (
+	LoadAndShowReportUser = UserMenu.Text("Отчеты")
+	Subscriptions         = UserMenu.Text("🔔 Подписки")
+)
 
 var (
 	ManageUsers = AdminMenu.Text("👥 Управление пользователями")
@@ -16,10 +19,18 @@ var (
 	ManageCron  = AdminMenu.Text("🔄 Управление рассылками")
 	StartCron   = AdminMenu.Text("🔄 Перезапустить рассылки")
 	StopCron    = AdminMenu.Text("🔄 Выключить рассылку")
+	FlushCache  = AdminMenu.Text("🧹 Сбросить кэш отчетов")
+	RunControl  = AdminMenu.Text("⏯ Паузы и ручной запуск")
+	AuditLog    = AdminMenu.Text("📜 Журнал действий")
+
+	ManageReports = AdminMenu.Text("📊 Управление отчетами")
+	CreateReport  = AdminMenu.Text("➕ Новый отчет")
+	EditReport    = AdminMenu.Text("✏️ Изменить отчет")
 
 	ListUser   = AdminMenu.Text("📋 Список пользователей")
 	AddUser    = AdminMenu.Text("➕ Добавить пользователя")
 	RemoveUser = AdminMenu.Text("➖ Удалить пользователя")
+	Invites    = AdminMenu.Text("🎟 Приглашения")
 
 	ListChats  = AdminMenu.Text("📋 Список чатов")
 	RemoveChat = AdminMenu.Text("➖ Удалить чат")
@@ -34,6 +45,10 @@ var (
 	AddChat         = "/add"
 	AddActiveChat   = "/sub"
 	RegisterCommand = "/register"
+
+	SubscribeCommand     = "/subscribe"
+	UnsubscribeCommand   = "/unsubscribe"
+	SubscriptionsCommand = "/subscriptions"
 )
 
 var MsgHelloReport = `Выберите нужный отчет и он придет в данный чат`
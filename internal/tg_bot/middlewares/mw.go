@@ -11,6 +11,7 @@ import (
 
 type UserProvider interface {
 	IsAllowed(ctx context.Context, id int64) (string, error)
+	Link(ctx context.Context, user models.User) (string, error)
 }
 
 type Mw struct {
@@ -44,7 +45,7 @@ func (mw *Mw) UserAuthMiddleware(next telebot.HandlerFunc) telebot.HandlerFunc {
 			slog.Any("chat_name", c.Chat().Title),
 		)
 
-		role, err := mw.userPr.IsAllowed(ctx, user.ID)
+		role, err := mw.role(ctx, user)
 
 		if err != nil || role == models.Denied {
 			mw.l.InfoContext(
@@ -82,7 +83,7 @@ func (mw *Mw) AdminAuthMiddleware(next telebot.HandlerFunc) telebot.HandlerFunc
 			slog.Any("chat_name", c.Chat().Title),
 		)
 
-		role, err := mw.userPr.IsAllowed(ctx, user.ID)
+		role, err := mw.role(ctx, user)
 
 		if err != nil || role == models.Denied || role == models.UserRole {
 			mw.l.InfoContext(
@@ -103,3 +104,78 @@ func (mw *Mw) AdminAuthMiddleware(next telebot.HandlerFunc) telebot.HandlerFunc
 		return next(c)
 	}
 }
+
+// SubscriberMiddleware пропускает к управлению подписками чата только
+// зарегистрированных пользователей. В группе пользователь дополнительно должен быть
+// администратором группы, если он не администратор бота.
+func (mw *Mw) SubscriberMiddleware(next telebot.HandlerFunc) telebot.HandlerFunc {
+	return func(c telebot.Context) error {
+		user := c.Sender()
+
+		ctx := logger.AppendCtx(context.Background(),
+			slog.Any("userID", user.ID),
+			slog.Any("username", user.Username),
+			slog.Any("from_id", c.Chat().ID),
+			slog.Any("chat_name", c.Chat().Title),
+		)
+
+		role, err := mw.role(ctx, user)
+		if err != nil {
+			mw.l.InfoContext(ctx, "error check user", slog.Any("error", err))
+
+			role = models.Denied
+		}
+
+		// Администратор группы без регистрации в боте подписки не получает.
+		allowed := role != models.Denied
+
+		isBotAdmin := role == models.AdminRole || role == models.PrimaryAdminRole
+		if allowed && c.Chat().Type != telebot.ChatPrivate && !isBotAdmin {
+			allowed = mw.isChatAdmin(ctx, c)
+		}
+
+		if !allowed {
+			mw.l.InfoContext(ctx, "unauthorized subscription attempt")
+
+			if c.Callback() != nil {
+				return c.Respond(&telebot.CallbackResponse{Text: "Подписками управляют зарегистрированные администраторы чата"})
+			}
+
+			return nil
+		}
+
+		c.Set("role", role)
+
+		return next(c)
+	}
+}
+
+func (mw *Mw) isChatAdmin(ctx context.Context, c telebot.Context) bool {
+	member, err := c.Bot().ChatMemberOf(c.Chat(), c.Sender())
+	if err != nil {
+		mw.l.InfoContext(ctx, "error check chat member", slog.Any("error", err))
+
+		return false
+	}
+
+	return member.Role == telebot.Creator || member.Role == telebot.Administrator
+}
+
+// role возвращает роль пользователя. Пользователь, которого администратор добавил
+// по username, при первом обращении к боту привязывается к своему Telegram ID.
+func (mw *Mw) role(ctx context.Context, user *telebot.User) (string, error) {
+	role, err := mw.userPr.IsAllowed(ctx, user.ID)
+	if err == nil || user.Username == "" {
+		return role, err
+	}
+
+	linked, lerr := mw.userPr.Link(
+		ctx,
+		models.NewUser(user.ID, user.Username, user.FirstName, &user.LastName, false),
+	)
+	if lerr != nil {
+		return role, err
+	}
+
+	return linked, nil
+}
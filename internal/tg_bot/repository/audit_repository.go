@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/jmoiron/sqlx"
+	"support_bot/internal/models"
+)
+
+// AuditRepository хранит журнал действий в боте.
+type AuditRepository struct {
+	db  *sqlx.DB
+	log *slog.Logger
+}
+
+func NewAuditRepository(db *sqlx.DB, log *slog.Logger) *AuditRepository {
+	l := log.With(slog.Any("module", "tg_bot.repository.audit"))
+
+	return &AuditRepository{db: db, log: l}
+}
+
+const auditColumns = `id, actor, action, target, before::text as before, after::text as after, created_at`
+
+func (r *AuditRepository) Write(ctx context.Context, e models.AuditEntry) error {
+	const query = `insert into audit_log(actor, action, target, before, after)
+values ($1, $2, $3, $4::jsonb, $5::jsonb)`
+
+	_, err := r.db.ExecContext(ctx, query, e.Actor, e.Action, e.Target, e.Before, e.After)
+	if err != nil {
+		return fmt.Errorf("write audit entry: %w", err)
+	}
+
+	return nil
+}
+
+func (r *AuditRepository) Count(ctx context.Context) (int, error) {
+	const query = `select count(*) from audit_log`
+
+	var count int
+
+	if err := r.db.GetContext(ctx, &count, query); err != nil {
+		return 0, fmt.Errorf("count audit entries: %w", err)
+	}
+
+	return count, nil
+}
+
+// Load возвращает страницу page журнала, новые записи первыми.
+func (r *AuditRepository) Load(ctx context.Context, page, limit int) ([]models.AuditEntry, error) {
+	const query = `select ` + auditColumns + ` from audit_log order by id desc limit $1 offset $2`
+
+	var entries []models.AuditEntry
+
+	if err := r.db.SelectContext(ctx, &entries, query, limit, (page-1)*limit); err != nil {
+		return nil, fmt.Errorf("load audit entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+// All возвращает весь журнал в порядке записи.
+func (r *AuditRepository) All(ctx context.Context) ([]models.AuditEntry, error) {
+	const query = `select ` + auditColumns + ` from audit_log order by id`
+
+	var entries []models.AuditEntry
+
+	if err := r.db.SelectContext(ctx, &entries, query); err != nil {
+		return nil, fmt.Errorf("load audit log: %w", err)
+	}
+
+	return entries, nil
+}
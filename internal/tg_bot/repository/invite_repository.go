@@ -0,0 +1,299 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"support_bot/internal/errorz"
+	"support_bot/internal/models"
+)
+
+// InviteRepository хранит приглашения в бота.
+type InviteRepository struct {
+	db  *sqlx.DB
+	log *slog.Logger
+}
+
+func NewInviteRepository(db *sqlx.DB, log *slog.Logger) *InviteRepository {
+	l := log.With(slog.Any("module", "tg_bot.repository.invite"))
+
+	return &InviteRepository{db: db, log: l}
+}
+
+type invite struct {
+	ID           int        `db:"id"`
+	Token        string     `db:"token"`
+	Role         string     `db:"role"`
+	CreatedBy    string     `db:"created_by"`
+	CreatedAt    time.Time  `db:"created_at"`
+	ExpiresAt    time.Time  `db:"expires_at"`
+	UsedBy       *int64     `db:"used_by"`
+	UsedUsername *string    `db:"used_username"`
+	UsedAt       *time.Time `db:"used_at"`
+	RevokedBy    *string    `db:"revoked_by"`
+	RevokedAt    *time.Time `db:"revoked_at"`
+	// Groups — JSON-массив названий групп.
+	Groups string `db:"groups"`
+}
+
+func (i invite) toModel() (models.Invite, error) {
+	m := models.Invite{
+		ID:           i.ID,
+		Token:        i.Token,
+		Role:         i.Role,
+		CreatedBy:    i.CreatedBy,
+		CreatedAt:    i.CreatedAt,
+		ExpiresAt:    i.ExpiresAt,
+		UsedBy:       i.UsedBy,
+		UsedUsername: i.UsedUsername,
+		UsedAt:       i.UsedAt,
+		RevokedBy:    i.RevokedBy,
+		RevokedAt:    i.RevokedAt,
+	}
+
+	if err := json.Unmarshal([]byte(i.Groups), &m.Groups); err != nil {
+		return models.Invite{}, fmt.Errorf("unmarshal invite groups: %w", err)
+	}
+
+	return m, nil
+}
+
+const inviteColumns = `i.id, i.token, i.role, i.created_by, i.created_at, i.expires_at,
+       i.used_by, i.used_username, i.used_at, i.revoked_by, i.revoked_at,
+       coalesce((select json_agg(coalesce(nullif(g.title, ''), g.name) order by g.name)
+                 from invite_groups ig
+                          join user_groups g on g.id = ig.group_id
+                 where ig.invite_id = i.id), '[]')::text as groups`
+
+// Groups возвращает все группы пользователей.
+func (r *InviteRepository) Groups(ctx context.Context) ([]models.UserGroup, error) {
+	const query = `select id, name, title from user_groups order by name`
+
+	var groups []models.UserGroup
+
+	if err := r.db.SelectContext(ctx, &groups, query); err != nil {
+		return nil, fmt.Errorf("load user groups: %w", err)
+	}
+
+	return groups, nil
+}
+
+// Create сохраняет приглашение вместе с группами.
+func (r *InviteRepository) Create(
+	ctx context.Context,
+	inv models.Invite,
+	groupIDs []int,
+) (models.Invite, error) {
+	const (
+		inviteQuery = `insert into invites(token, role, created_by, expires_at)
+values ($1, $2, $3, $4)
+returning id`
+		groupQuery = `insert into invite_groups(invite_id, group_id) values ($1, $2)`
+		getQuery   = `select ` + inviteColumns + ` from invites i where i.id = $1`
+	)
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return models.Invite{}, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var id int
+
+	err = tx.GetContext(ctx, &id, inviteQuery, inv.Token, inv.Role, inv.CreatedBy, inv.ExpiresAt)
+	if err != nil {
+		return models.Invite{}, fmt.Errorf("create invite: %w", err)
+	}
+
+	for _, g := range groupIDs {
+		if _, err := tx.ExecContext(ctx, groupQuery, id, g); err != nil {
+			return models.Invite{}, fmt.Errorf("add invite group %d: %w", g, err)
+		}
+	}
+
+	var row invite
+
+	if err := tx.GetContext(ctx, &row, getQuery, id); err != nil {
+		return models.Invite{}, fmt.Errorf("get invite: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Invite{}, fmt.Errorf("commit invite: %w", err)
+	}
+
+	return row.toModel()
+}
+
+// List возвращает последние limit приглашений, новые первыми.
+func (r *InviteRepository) List(ctx context.Context, limit int) ([]models.Invite, error) {
+	const query = `select ` + inviteColumns + ` from invites i order by i.id desc limit $1`
+
+	var rows []invite
+
+	if err := r.db.SelectContext(ctx, &rows, query, limit); err != nil {
+		return nil, fmt.Errorf("list invites: %w", err)
+	}
+
+	invites := make([]models.Invite, 0, len(rows))
+
+	for _, row := range rows {
+		inv, err := row.toModel()
+		if err != nil {
+			return nil, err
+		}
+
+		invites = append(invites, inv)
+	}
+
+	return invites, nil
+}
+
+// Revoke отзывает неиспользованное приглашение id.
+// Возвращает ErrNotFound, если приглашения нет, оно уже использовано или отозвано.
+func (r *InviteRepository) Revoke(ctx context.Context, id int, by string) error {
+	const query = `update invites
+set revoked_by = $2, revoked_at = now()
+where id = $1 and used_at is null and revoked_at is null`
+
+	res, err := r.db.ExecContext(ctx, query, id, by)
+	if err != nil {
+		return fmt.Errorf("revoke invite: %w", err)
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errorz.ErrNotFound
+	}
+
+	return nil
+}
+
+// Redeem регистрирует пользователя по приглашению token и помечает приглашение использованным.
+// Пользователь, добавленный по username, привязывается к Telegram ID. Роль уже
+// зарегистрированного пользователя только повышается до роли приглашения, группы добавляются.
+// Возвращает ErrNotFound для неизвестного токена и ErrInviteInactive вместе с приглашением,
+// если оно использовано, отозвано или истекло.
+func (r *InviteRepository) Redeem(
+	ctx context.Context,
+	token string,
+	user models.User,
+) (models.Invite, error) {
+	const (
+		getQuery  = `select ` + inviteColumns + ` from invites i where i.token = $1 for update`
+		linkQuery = `update users
+set telegram_id    = $2,
+    first_name     = $3,
+    last_name      = $4,
+    role           = case when role = 'user' then $5 else role end,
+    is_placeholder = false
+where id = (
+    select id from users
+    where lower(username) = lower($1) and is_placeholder
+    order by id
+    limit 1
+)
+returning id`
+		upsertQuery = `insert into users(telegram_id, username, first_name, last_name, role)
+values ($1, $2, $3, $4, $5)
+on conflict (telegram_id) do update
+    set role = case when users.role = 'user' then excluded.role else users.role end
+returning id`
+		groupsQuery = `insert into user_group_members(group_id, user_id)
+select ig.group_id, $2 from invite_groups ig
+where ig.invite_id = $1
+  and not exists(select 1 from user_group_members m where m.group_id = ig.group_id and m.user_id = $2)`
+		useQuery = `update invites
+set used_by = $2, used_username = $3, used_at = now()
+where id = $1
+returning used_at`
+		requestQuery = `update registration_requests
+set status = 'approved', decided_by = $2, decided_at = now()
+where telegram_id = $1 and status = 'pending'`
+	)
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return models.Invite{}, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var row invite
+
+	err = tx.GetContext(ctx, &row, getQuery, token)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Invite{}, errorz.ErrNotFound
+	}
+
+	if err != nil {
+		return models.Invite{}, fmt.Errorf("get invite: %w", err)
+	}
+
+	inv, err := row.toModel()
+	if err != nil {
+		return models.Invite{}, err
+	}
+
+	if inv.Status(time.Now()) != models.InviteActive {
+		return inv, models.ErrInviteInactive
+	}
+
+	var userID int
+
+	err = sql.ErrNoRows
+	if user.Username != "" {
+		err = tx.GetContext(
+			ctx,
+			&userID,
+			linkQuery,
+			user.Username,
+			user.TelegramID,
+			user.FirstName,
+			user.LastName,
+			inv.Role,
+		)
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		err = tx.GetContext(
+			ctx,
+			&userID,
+			upsertQuery,
+			user.TelegramID,
+			user.Username,
+			user.FirstName,
+			user.LastName,
+			inv.Role,
+		)
+	}
+
+	if err != nil {
+		return models.Invite{}, fmt.Errorf("register invited user: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, groupsQuery, inv.ID, userID); err != nil {
+		return models.Invite{}, fmt.Errorf("add invited user to groups: %w", err)
+	}
+
+	if err := tx.GetContext(ctx, &inv.UsedAt, useQuery, inv.ID, user.TelegramID, user.Username); err != nil {
+		return models.Invite{}, fmt.Errorf("mark invite used: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, requestQuery, user.TelegramID, fmt.Sprintf("invite #%d", inv.ID))
+	if err != nil {
+		return models.Invite{}, fmt.Errorf("close registration request: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Invite{}, fmt.Errorf("commit invite redeem: %w", err)
+	}
+
+	inv.UsedBy = &user.TelegramID
+	inv.UsedUsername = &user.Username
+
+	return inv, nil
+}
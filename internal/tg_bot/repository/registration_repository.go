@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jmoiron/sqlx"
+	"support_bot/internal/errorz"
+	"support_bot/internal/models"
+)
+
+// RegistrationRepository хранит заявки на регистрацию.
+type RegistrationRepository struct {
+	db  *sqlx.DB
+	log *slog.Logger
+}
+
+func NewRegistrationRepository(db *sqlx.DB, log *slog.Logger) *RegistrationRepository {
+	l := log.With(slog.Any("module", "tg_bot.repository.registration"))
+
+	return &RegistrationRepository{db: db, log: l}
+}
+
+// Get возвращает заявку пользователя с Telegram ID tgID.
+func (r *RegistrationRepository) Get(ctx context.Context, tgID int64) (models.RegistrationRequest, error) {
+	const query = `select * from registration_requests where telegram_id = $1`
+
+	var req models.RegistrationRequest
+
+	err := r.db.GetContext(ctx, &req, query, tgID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.RegistrationRequest{}, errorz.ErrNotFound
+	}
+
+	if err != nil {
+		return models.RegistrationRequest{}, fmt.Errorf("get registration request: %w", err)
+	}
+
+	return req, nil
+}
+
+// Create сохраняет новую заявку. Закрытая заявка того же пользователя открывается заново:
+// так повторно регистрируется пользователь, которого удалили после одобрения.
+func (r *RegistrationRepository) Create(
+	ctx context.Context,
+	user models.User,
+) (models.RegistrationRequest, error) {
+	const query = `insert into registration_requests(telegram_id, username, first_name, last_name)
+values ($1, $2, $3, $4)
+on conflict (telegram_id) do update
+    set username   = excluded.username,
+        first_name = excluded.first_name,
+        last_name  = excluded.last_name,
+        status     = 'pending',
+        decided_by = null,
+        decided_at = null,
+        created_at = now()
+returning *`
+
+	var req models.RegistrationRequest
+
+	err := r.db.GetContext(
+		ctx,
+		&req,
+		query,
+		user.TelegramID,
+		user.Username,
+		user.FirstName,
+		user.LastName,
+	)
+	if err != nil {
+		return models.RegistrationRequest{}, fmt.Errorf("create registration request: %w", err)
+	}
+
+	return req, nil
+}
+
+// Decide закрывает ожидающую заявку id. При одобрении пользователь создается с ролью user,
+// а если администратор уже добавил его по username, привязывается существующая запись.
+// Возвращает ErrNotFound, если заявки нет или по ней уже принято решение.
+func (r *RegistrationRepository) Decide(
+	ctx context.Context,
+	id int,
+	status models.RegistrationStatus,
+	by string,
+) (models.RegistrationRequest, error) {
+	const (
+		decideQuery = `update registration_requests
+set status = $2, decided_by = $3, decided_at = now()
+where id = $1 and status = 'pending'
+returning *`
+		linkQuery = `update users
+set telegram_id = $2, first_name = $3, last_name = $4, is_placeholder = false
+where id = (
+    select id from users
+    where lower(username) = lower($1) and is_placeholder
+    order by id
+    limit 1
+)`
+		createQuery = `insert into users(telegram_id, username, first_name, last_name, role)
+values ($1, $2, $3, $4, 'user')
+on conflict (telegram_id) do nothing`
+	)
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return models.RegistrationRequest{}, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var req models.RegistrationRequest
+
+	err = tx.GetContext(ctx, &req, decideQuery, id, status, by)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.RegistrationRequest{}, errorz.ErrNotFound
+	}
+
+	if err != nil {
+		return models.RegistrationRequest{}, fmt.Errorf("decide registration request: %w", err)
+	}
+
+	if status == models.RegistrationApproved {
+		res, err := tx.ExecContext(ctx, linkQuery, req.Username, req.TelegramID, req.FirstName, req.LastName)
+		if err != nil {
+			return models.RegistrationRequest{}, fmt.Errorf("link user: %w", err)
+		}
+
+		if n, _ := res.RowsAffected(); n == 0 {
+			_, err = tx.ExecContext(ctx, createQuery, req.TelegramID, req.Username, req.FirstName, req.LastName)
+			if err != nil {
+				return models.RegistrationRequest{}, fmt.Errorf("create user: %w", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.RegistrationRequest{}, fmt.Errorf("commit registration decision: %w", err)
+	}
+
+	return req, nil
+}
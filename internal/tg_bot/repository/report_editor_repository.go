@@ -0,0 +1,552 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jmoiron/sqlx"
+	"support_bot/internal/errorz"
+	"support_bot/internal/models"
+)
+
+// ReportEditorRepository читает и сохраняет отчеты, которые редактируются из админ-меню.
+// Редактируются только Telegram-получатели и расписания генерации отчета,
+// остальные получатели и расписания удаления сообщений не затрагиваются.
+type ReportEditorRepository struct {
+	db  *sqlx.DB
+	log *slog.Logger
+}
+
+func NewReportEditorRepository(db *sqlx.DB, log *slog.Logger) *ReportEditorRepository {
+	l := log.With(slog.Any("module", "tg_bot.repository.report_editor"))
+
+	return &ReportEditorRepository{db: db, log: l}
+}
+
+func (r *ReportEditorRepository) Exists(ctx context.Context, name string) (bool, error) {
+	const query = `select exists(select 1 from reports where name = $1)`
+
+	var ok bool
+
+	if err := r.db.GetContext(ctx, &ok, query, name); err != nil {
+		return false, fmt.Errorf("check report exists: %w", err)
+	}
+
+	return ok, nil
+}
+
+func (r *ReportEditorRepository) LoadReports(
+	ctx context.Context,
+	page int,
+	limit int,
+) ([]models.ReportForTgLK, error) {
+	const query = `select id, name, title from reports order by id limit $1 offset $2`
+
+	if page <= 0 {
+		page = 1
+	}
+
+	var reports []report
+
+	if err := r.db.SelectContext(ctx, &reports, query, limit, (page-1)*limit); err != nil {
+		return nil, fmt.Errorf("load reports: %w", err)
+	}
+
+	res := make([]models.ReportForTgLK, 0, len(reports))
+	for _, rp := range reports {
+		res = append(res, models.ReportForTgLK{ID: rp.ID, Name: rp.Name, Title: rp.Title})
+	}
+
+	return res, nil
+}
+
+func (r *ReportEditorRepository) GetReportsCount(ctx context.Context) (int, error) {
+	const query = `select count(*) from reports`
+
+	var count int
+
+	if err := r.db.GetContext(ctx, &count, query); err != nil {
+		return 0, fmt.Errorf("count reports: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *ReportEditorRepository) ExportFormats(ctx context.Context) ([]string, error) {
+	const query = `select format from export_formats order by id`
+
+	var formats []string
+
+	if err := r.db.SelectContext(ctx, &formats, query); err != nil {
+		return nil, fmt.Errorf("load export formats: %w", err)
+	}
+
+	return formats, nil
+}
+
+func (r *ReportEditorRepository) ActiveChats(ctx context.Context) ([]models.TgChatDTO, error) {
+	const query = `select id, chat_id, title, type, description, is_active
+from chats
+where is_active = true
+order by title`
+
+	var chats []models.TgChatDTO
+
+	if err := r.db.SelectContext(ctx, &chats, query); err != nil {
+		return nil, fmt.Errorf("load chats: %w", err)
+	}
+
+	return chats, nil
+}
+
+// Crons возвращает активные расписания генерации отчетов.
+func (r *ReportEditorRepository) Crons(ctx context.Context) ([]models.Cron, error) {
+	const query = `select id, cron, name, description
+from crons
+where is_active = true and event_type = 0
+order by id`
+
+	var crons []models.Cron
+
+	if err := r.db.SelectContext(ctx, &crons, query); err != nil {
+		return nil, fmt.Errorf("load crons: %w", err)
+	}
+
+	return crons, nil
+}
+
+func (r *ReportEditorRepository) CronByID(ctx context.Context, id int) (models.Cron, error) {
+	const query = `select id, cron, name, description from crons where id = $1`
+
+	var c models.Cron
+
+	err := r.db.GetContext(ctx, &c, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Cron{}, errorz.ErrNotFound
+	}
+
+	if err != nil {
+		return models.Cron{}, fmt.Errorf("load cron: %w", err)
+	}
+
+	return c, nil
+}
+
+// LoadDraft загружает отчет id для редактирования.
+func (r *ReportEditorRepository) LoadDraft(ctx context.Context, id int) (models.ReportDraft, error) {
+	const (
+		reportQuery = `select r.id, r.name, r.title, r.active, r.subscribable, coalesce(e.expr, '') as expr,
+       exists(select 1 from report_templates rt where rt.report_id = r.id) as has_template
+from reports r
+left join evaluate e on e.id = r.eval_id
+where r.id = $1`
+		cardsQuery = `select q.card_uuid, q.title
+from report_queries rq
+join queries q on q.id = rq.query_id
+where rq.report_id = $1
+order by q.id`
+		formatsQuery = `select ef.format
+from reports_export re
+join export_formats ef on ef.id = re.format_id
+where re.report_id = $1
+order by ef.id`
+		chatsQuery = `select distinct r.chat_id
+from reports_recipients rr
+join recipients r on r.id = rr.recipient_id
+where rr.report_id = $1 and r.type = 'tg' and r.chat_id is not null`
+		othersQuery = `select count(*)
+from reports_recipients rr
+join recipients r on r.id = rr.recipient_id
+where rr.report_id = $1 and (r.type <> 'tg' or r.chat_id is null)`
+		cronsQuery = `select c.cron
+from report_crons rc
+join crons c on c.id = rc.cron_id
+where rc.report_id = $1 and c.event_type = 0
+order by c.id`
+	)
+
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return models.ReportDraft{}, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var row struct {
+		ID           int    `db:"id"`
+		Name         string `db:"name"`
+		Title        string `db:"title"`
+		Active       bool   `db:"active"`
+		Subscribable bool   `db:"subscribable"`
+		Expr         string `db:"expr"`
+		HasTemplate  bool   `db:"has_template"`
+	}
+
+	err = tx.GetContext(ctx, &row, reportQuery, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ReportDraft{}, errorz.ErrNotFound
+	}
+
+	if err != nil {
+		return models.ReportDraft{}, fmt.Errorf("load report: %w", err)
+	}
+
+	d := models.ReportDraft{
+		ID:           row.ID,
+		Name:         row.Name,
+		Title:        row.Title,
+		Active:       row.Active,
+		Subscribable: row.Subscribable,
+		Evaluation:   row.Expr,
+		HasTemplate:  row.HasTemplate,
+	}
+
+	var cards []card
+
+	if err := tx.SelectContext(ctx, &cards, cardsQuery, id); err != nil {
+		return models.ReportDraft{}, fmt.Errorf("load cards: %w", err)
+	}
+
+	for _, c := range cards {
+		d.Cards = append(d.Cards, models.Card{CardUUID: c.CardUUID, Title: c.Title})
+	}
+
+	if err := tx.SelectContext(ctx, &d.Formats, formatsQuery, id); err != nil {
+		return models.ReportDraft{}, fmt.Errorf("load formats: %w", err)
+	}
+
+	if err := tx.SelectContext(ctx, &d.ChatIDs, chatsQuery, id); err != nil {
+		return models.ReportDraft{}, fmt.Errorf("load chats: %w", err)
+	}
+
+	if err := tx.GetContext(ctx, &d.OtherRecipients, othersQuery, id); err != nil {
+		return models.ReportDraft{}, fmt.Errorf("count recipients: %w", err)
+	}
+
+	if err := tx.SelectContext(ctx, &d.Crontabs, cronsQuery, id); err != nil {
+		return models.ReportDraft{}, fmt.Errorf("load crons: %w", err)
+	}
+
+	return d, nil
+}
+
+type card struct {
+	CardUUID string `db:"card_uuid"`
+	Title    string `db:"title"`
+}
+
+// SaveDraft создает или обновляет отчет в одной транзакции и возвращает его id.
+// Общие строки (условия, карточки, получатели, расписания) переиспользуются,
+// если совпадают, и не изменяются.
+func (r *ReportEditorRepository) SaveDraft(ctx context.Context, d models.ReportDraft) (int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	evalID, err := r.saveEvaluation(ctx, tx, d.Evaluation)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := r.saveReport(ctx, tx, d, evalID)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := r.saveCards(ctx, tx, id, d.Cards); err != nil {
+		return 0, err
+	}
+
+	if err := r.saveFormats(ctx, tx, id, d.Name, d.Formats); err != nil {
+		return 0, err
+	}
+
+	if err := r.saveChats(ctx, tx, id, d.ChatIDs); err != nil {
+		return 0, err
+	}
+
+	if err := r.saveCrons(ctx, tx, id, d); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit report: %w", err)
+	}
+
+	return id, nil
+}
+
+func (r *ReportEditorRepository) saveEvaluation(ctx context.Context, tx *sqlx.Tx, expr string) (int, error) {
+	const query = `with e as (
+    select id from evaluate where expr = $1 order by id limit 1
+), ins as (
+    insert into evaluate(expr)
+    select $1
+    where not exists(select 1 from e)
+    returning id
+)
+select id from e
+union all
+select id from ins`
+
+	var id int
+
+	if err := tx.GetContext(ctx, &id, query, expr); err != nil {
+		return 0, fmt.Errorf("save evaluation: %w", err)
+	}
+
+	return id, nil
+}
+
+func (r *ReportEditorRepository) saveReport(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	d models.ReportDraft,
+	evalID int,
+) (int, error) {
+	const (
+		insertQuery = `insert into reports(name, title, active, eval_id, subscribable)
+values ($1, $2, $3, $4, $5)
+returning id`
+		updateQuery = `update reports
+set title = $2, active = $3, eval_id = $4, subscribable = $5
+where id = $1`
+	)
+
+	if d.ID == 0 {
+		var id int
+
+		if err := tx.GetContext(ctx, &id, insertQuery, d.Name, d.Title, d.Active, evalID, d.Subscribable); err != nil {
+			return 0, fmt.Errorf("create report: %w", err)
+		}
+
+		return id, nil
+	}
+
+	res, err := tx.ExecContext(ctx, updateQuery, d.ID, d.Title, d.Active, evalID, d.Subscribable)
+	if err != nil {
+		return 0, fmt.Errorf("update report: %w", err)
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return 0, errorz.ErrNotFound
+	}
+
+	return d.ID, nil
+}
+
+func (r *ReportEditorRepository) saveCards(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	reportID int,
+	cards []models.Card,
+) error {
+	const (
+		unlinkQuery = `delete from report_queries where report_id = $1`
+		queryQuery  = `with q as (
+    select id from queries where card_uuid = $1 and title = $2 order by id limit 1
+), ins as (
+    insert into queries(card_uuid, title)
+    select $1, $2
+    where not exists(select 1 from q)
+    returning id
+)
+select id from q
+union all
+select id from ins`
+		linkQuery = `insert into report_queries(report_id, query_id) values ($1, $2) on conflict do nothing`
+	)
+
+	if _, err := tx.ExecContext(ctx, unlinkQuery, reportID); err != nil {
+		return fmt.Errorf("unlink cards: %w", err)
+	}
+
+	for _, c := range cards {
+		var queryID int
+
+		if err := tx.GetContext(ctx, &queryID, queryQuery, c.CardUUID, c.Title); err != nil {
+			return fmt.Errorf("save card %s: %w", c.Title, err)
+		}
+
+		if _, err := tx.ExecContext(ctx, linkQuery, reportID, queryID); err != nil {
+			return fmt.Errorf("link card %s: %w", c.Title, err)
+		}
+	}
+
+	return nil
+}
+
+// saveFormats оставляет настройки (имя файла, сортировку) у форматов, которые уже были выбраны.
+func (r *ReportEditorRepository) saveFormats(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	reportID int,
+	name string,
+	formats []string,
+) error {
+	const (
+		removeQuery = `delete from reports_export
+where report_id = $1
+  and format_id not in (select id from export_formats where format = any($2))`
+		addQuery = `insert into reports_export(report_id, format_id, file_name)
+select $1, ef.id, $3
+from export_formats ef
+where ef.format = any($2)
+  and not exists(select 1 from reports_export re where re.report_id = $1 and re.format_id = ef.id)`
+	)
+
+	if _, err := tx.ExecContext(ctx, removeQuery, reportID, formats); err != nil {
+		return fmt.Errorf("remove formats: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, addQuery, reportID, formats, name); err != nil {
+		return fmt.Errorf("add formats: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ReportEditorRepository) saveChats(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	reportID int,
+	chatIDs []int,
+) error {
+	const (
+		removeQuery = `delete from reports_recipients rr
+using recipients r
+where r.id = rr.recipient_id
+  and rr.report_id = $1
+  and r.type = 'tg'
+  and r.chat_id is not null
+  and not (r.chat_id = any($2))`
+		recipientQuery = `with r as (
+    select id
+    from recipients
+    where type = 'tg' and chat_id = $1 and thread_id is null and remote_path is null
+    order by id
+    limit 1
+), ins as (
+    insert into recipients(name, chat_id, type)
+    select coalesce(c.title, c.chat_id::text), c.id, 'tg'
+    from chats c
+    where c.id = $1 and not exists(select 1 from r)
+    returning id
+)
+select id from r
+union all
+select id from ins`
+		linkQuery = `insert into reports_recipients(report_id, recipient_id)
+select $1, $2
+where not exists(
+    select 1
+    from reports_recipients rr
+    join recipients r on r.id = rr.recipient_id
+    where rr.report_id = $1 and r.type = 'tg' and r.chat_id = $3
+)`
+	)
+
+	if _, err := tx.ExecContext(ctx, removeQuery, reportID, chatIDs); err != nil {
+		return fmt.Errorf("remove recipients: %w", err)
+	}
+
+	for _, chatID := range chatIDs {
+		var recipientID int
+
+		err := tx.GetContext(ctx, &recipientID, recipientQuery, chatID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("chat %d: %w", chatID, errorz.ErrNotFound)
+		}
+
+		if err != nil {
+			return fmt.Errorf("save recipient for chat %d: %w", chatID, err)
+		}
+
+		if _, err := tx.ExecContext(ctx, linkQuery, reportID, recipientID, chatID); err != nil {
+			return fmt.Errorf("link recipient for chat %d: %w", chatID, err)
+		}
+	}
+
+	return nil
+}
+
+// saveCrons привязывает отчет к расписаниям d.Crontabs. Расписание с тем же выражением
+// переиспользуется, иначе создается новое расписание report_<name> или report_<name>_<n>,
+// если имя занято. Существующие расписания не меняются: их могут использовать другие отчеты.
+func (r *ReportEditorRepository) saveCrons(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	reportID int,
+	d models.ReportDraft,
+) error {
+	const (
+		unlinkQuery = `delete from report_crons rc
+using crons c
+where c.id = rc.cron_id and rc.report_id = $1 and c.event_type = 0`
+		cronQuery = `select id from crons where cron = $1 and event_type = 0 and is_active = true order by id limit 1`
+		linkQuery = `insert into report_crons(report_id, cron_id) values ($1, $2) on conflict do nothing`
+	)
+
+	if _, err := tx.ExecContext(ctx, unlinkQuery, reportID); err != nil {
+		return fmt.Errorf("unlink crons: %w", err)
+	}
+
+	for _, crontab := range d.Crontabs {
+		var cronID int
+
+		err := tx.GetContext(ctx, &cronID, cronQuery, crontab)
+		if errors.Is(err, sql.ErrNoRows) {
+			cronID, err = r.insertCron(ctx, tx, crontab, d)
+		}
+
+		if err != nil {
+			return fmt.Errorf("save cron %q: %w", crontab, err)
+		}
+
+		if _, err := tx.ExecContext(ctx, linkQuery, reportID, cronID); err != nil {
+			return fmt.Errorf("link cron %q: %w", crontab, err)
+		}
+	}
+
+	return nil
+}
+
+// insertCron создает расписание отчета d с первым свободным именем:
+// report_<name>, report_<name>_2, report_<name>_3 и т.д.
+func (r *ReportEditorRepository) insertCron(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	crontab string,
+	d models.ReportDraft,
+) (int, error) {
+	const query = `insert into crons(cron, name, description, is_active)
+values ($1, $2, $3, true)
+on conflict (name) do nothing
+returning id`
+
+	base := "report_" + d.Name
+
+	for n := 1; ; n++ {
+		name := base
+		if n > 1 {
+			name = fmt.Sprintf("%s_%d", base, n)
+		}
+
+		var id int
+
+		err := tx.GetContext(ctx, &id, query, crontab, name, d.Title)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+
+		if err != nil {
+			return 0, err
+		}
+
+		return id, nil
+	}
+}
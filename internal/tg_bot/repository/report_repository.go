@@ -2,9 +2,15 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/jmoiron/sqlx"
+	"support_bot/internal/errorz"
 	"support_bot/internal/models"
 )
 
@@ -19,18 +25,38 @@ func NewReportRepository(db *sqlx.DB, log *slog.Logger) *ReportRepository {
 	return &ReportRepository{db: db, log: l}
 }
 
+// reportGrantFilter — условие выдачи отчета r пользователю с telegram_id $1.
+// Отчет без выдач группам доступен всем, администраторам доступны все отчеты.
+const reportGrantFilter = `(
+    not exists(select 1 from report_group_grants g where g.report_id = r.id)
+    or exists(select 1 from users u where u.telegram_id = $1 and u.role in ('admin', 'primary'))
+    or exists(
+        select 1
+        from report_group_grants g
+        join user_group_members m on m.group_id = g.group_id
+        join users u on u.id = m.user_id
+        where g.report_id = r.id and u.telegram_id = $1
+    )
+)`
+
+// reportAccessFilter — условие ручного запуска отчета r пользователем с telegram_id $1.
+const reportAccessFilter = `r.access_from_lk = true and ` + reportGrantFilter
+
 type report struct {
 	ID    int    `db:"id"`
 	Name  string `db:"name"`
 	Title string `db:"title"`
 }
 
+// LoadReports возвращает страницу отчетов, доступных пользователю userID.
 func (r *ReportRepository) LoadReports(
 	ctx context.Context,
+	userID int64,
 	page int,
 ) ([]models.ReportForTgLK, error) {
 	const (
-		query = `select id, name, title from reports where access_from_lk = true order by id limit $1 offset $2`
+		query = `select r.id, r.name, r.title from reports r where ` + reportAccessFilter +
+			` order by r.id limit $2 offset $3`
 		limit = 5
 	)
 
@@ -42,7 +68,7 @@ func (r *ReportRepository) LoadReports(
 
 	var reports []report
 
-	err := r.db.SelectContext(ctx, &reports, query, limit, offset)
+	err := r.db.SelectContext(ctx, &reports, query, userID, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -60,15 +86,184 @@ func (r *ReportRepository) LoadReports(
 	return reportLK, nil
 }
 
-func (r *ReportRepository) GetReportsCount(ctx context.Context) (int, error) {
-	const query = `select count(*) from reports where access_from_lk = true`
+// LoadParams загружает параметры, которые бот запрашивает перед запуском отчета reportID.
+func (r *ReportRepository) LoadParams(ctx context.Context, reportID int) ([]models.ReportParam, error) {
+	const query = `select name, title, type, coalesce(array_to_json(options), '[]')::text as options
+from report_params
+where report_id = $1
+order by position, id`
+
+	var rows []struct {
+		Name    string `db:"name"`
+		Title   string `db:"title"`
+		Type    string `db:"type"`
+		Options string `db:"options"`
+	}
+
+	if err := r.db.SelectContext(ctx, &rows, query, reportID); err != nil {
+		return nil, fmt.Errorf("load report params: %w", err)
+	}
+
+	params := make([]models.ReportParam, 0, len(rows))
+
+	for _, row := range rows {
+		var options []string
+
+		if err := json.Unmarshal([]byte(row.Options), &options); err != nil {
+			return nil, fmt.Errorf("param %s options: %w", row.Name, err)
+		}
+
+		params = append(params, models.ReportParam{
+			Name:    row.Name,
+			Title:   row.Title,
+			Type:    models.ParamType(row.Type),
+			Options: options,
+		})
+	}
+
+	return params, nil
+}
+
+func (r *ReportRepository) GetReportsCount(ctx context.Context, userID int64) (int, error) {
+	const query = `select count(*) from reports r where ` + reportAccessFilter
 
 	var count int
 
-	err := r.db.GetContext(ctx, &count, query)
+	err := r.db.GetContext(ctx, &count, query, userID)
 	if err != nil {
 		return 0, err
 	}
 
 	return count, nil
 }
+
+// AccessibleName возвращает имя отчета reportID, если он доступен пользователю userID.
+// ok = false, если отчета нет или он недоступен.
+func (r *ReportRepository) AccessibleName(ctx context.Context, userID int64, reportID int) (string, bool, error) {
+	const query = `select r.name from reports r where r.id = $2 and ` + reportAccessFilter
+
+	var name string
+
+	err := r.db.GetContext(ctx, &name, query, userID, reportID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+
+	if err != nil {
+		return "", false, fmt.Errorf("check report access: %w", err)
+	}
+
+	return name, true, nil
+}
+
+// FlushCaches рассылает пустое уведомление об изменении отчетов,
+// по которому все экземпляры сбрасывают кэши целиком.
+func (r *ReportRepository) FlushCaches(ctx context.Context) error {
+	const query = `select pg_notify('reports_changed', '')`
+
+	_, err := r.db.ExecContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("flush caches: %w", err)
+	}
+
+	return nil
+}
+
+// controlItemsQuery — активные расписания и отчеты с их состоянием ручного управления.
+const controlItemsQuery = `select i.id, i.name, i.title, i.event_type, rc.paused_until, coalesce(rc.skip_next, false) as skip_next
+from (select id, name, coalesce(description, name) as title, event_type, 'cron' as target
+      from crons
+      where is_active = true
+      union all
+      select id, name, title, 0, 'report'
+      from reports
+      where active = true) i
+         left join run_controls rc on rc.target = i.target and rc.name = i.name
+where i.target = $1`
+
+type controlItem struct {
+	ID          int        `db:"id"`
+	Name        string     `db:"name"`
+	Title       string     `db:"title"`
+	EventType   int        `db:"event_type"`
+	PausedUntil *time.Time `db:"paused_until"`
+	SkipNext    bool       `db:"skip_next"`
+}
+
+func (c controlItem) toModel(target models.ControlTarget) models.ControlItem {
+	return models.ControlItem{
+		ID:        c.ID,
+		Name:      c.Name,
+		Title:     c.Title,
+		EventType: c.EventType,
+		Control: models.RunControl{
+			Target:      target,
+			Name:        c.Name,
+			PausedUntil: c.PausedUntil,
+			SkipNext:    c.SkipNext,
+		},
+	}
+}
+
+func (r *ReportRepository) LoadControlItems(
+	ctx context.Context,
+	target models.ControlTarget,
+	page int,
+	limit int,
+) ([]models.ControlItem, error) {
+	const query = controlItemsQuery + ` order by i.id limit $2 offset $3`
+
+	if page <= 0 {
+		page = 1
+	}
+
+	var items []controlItem
+
+	err := r.db.SelectContext(ctx, &items, query, target, limit, (page-1)*limit)
+	if err != nil {
+		return nil, fmt.Errorf("load control items: %w", err)
+	}
+
+	res := make([]models.ControlItem, 0, len(items))
+	for _, it := range items {
+		res = append(res, it.toModel(target))
+	}
+
+	return res, nil
+}
+
+func (r *ReportRepository) GetControlItemsCount(
+	ctx context.Context,
+	target models.ControlTarget,
+) (int, error) {
+	const query = `select count(*) from (` + controlItemsQuery + `) c`
+
+	var count int
+
+	if err := r.db.GetContext(ctx, &count, query, target); err != nil {
+		return 0, fmt.Errorf("count control items: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *ReportRepository) GetControlItem(
+	ctx context.Context,
+	target models.ControlTarget,
+	id int,
+) (models.ControlItem, error) {
+	const query = controlItemsQuery + ` and i.id = $2`
+
+	var item controlItem
+
+	err := r.db.GetContext(ctx, &item, query, target, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ControlItem{}, errorz.ErrNotFound
+	}
+
+	if err != nil {
+		return models.ControlItem{}, fmt.Errorf("get control item: %w", err)
+	}
+
+	return item.toModel(target), nil
+}
@@ -0,0 +1,258 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/jmoiron/sqlx"
+	"support_bot/internal/errorz"
+	"support_bot/internal/models"
+)
+
+// SubscriptionRepository хранит подписки чатов на отчеты.
+// Подписка — Telegram-получатель чата и топика, привязанный к отчету.
+// threadID 0 означает чат без топика и хранится как null.
+type SubscriptionRepository struct {
+	db  *sqlx.DB
+	log *slog.Logger
+}
+
+func NewSubscriptionRepository(db *sqlx.DB, log *slog.Logger) *SubscriptionRepository {
+	l := log.With(slog.Any("module", "tg_bot.repository.subscription"))
+
+	return &SubscriptionRepository{db: db, log: l}
+}
+
+type subscription struct {
+	ReportID     int    `db:"report_id"`
+	Name         string `db:"name"`
+	Title        string `db:"title"`
+	ThreadID     *int   `db:"thread_id"`
+	Subscribed   bool   `db:"subscribed"`
+	Subscribable bool   `db:"subscribable"`
+}
+
+func (s subscription) toModel() models.Subscription {
+	var thread int
+	if s.ThreadID != nil {
+		thread = *s.ThreadID
+	}
+
+	return models.Subscription{
+		ReportID:     s.ReportID,
+		Name:         s.Name,
+		Title:        s.Title,
+		ThreadID:     thread,
+		Subscribed:   s.Subscribed,
+		Subscribable: s.Subscribable,
+	}
+}
+
+// subscribableFilter — отчет r доступен для подписки пользователю с telegram_id $1:
+// включен, разрешен для подписки и выдан пользователю, если выдан группам.
+const subscribableFilter = `r.active = true and r.subscribable = true and ` + reportGrantFilter
+
+// LoadSubscribable возвращает страницу активных отчетов, на которые пользователь userID
+// может подписать чат, с отметкой, подписан ли на них чат chatID в топике threadID.
+func (r *SubscriptionRepository) LoadSubscribable(
+	ctx context.Context,
+	userID int64,
+	chatID int64,
+	threadID int,
+	page int,
+	limit int,
+) ([]models.Subscription, error) {
+	const query = `select r.id as report_id, r.name, r.title, true as subscribable,
+       exists(
+           select 1
+           from reports_recipients rr
+           join recipients rc on rc.id = rr.recipient_id
+           join chats c on c.id = rc.chat_id
+           where rr.report_id = r.id
+             and rc.type = 'tg'
+             and c.chat_id = $2
+             and coalesce(rc.thread_id, 0) = $3
+       ) as subscribed
+from reports r
+where ` + subscribableFilter + `
+order by r.title
+limit $4 offset $5`
+
+	if page <= 0 {
+		page = 1
+	}
+
+	var subs []subscription
+
+	err := r.db.SelectContext(ctx, &subs, query, userID, chatID, threadID, limit, (page-1)*limit)
+	if err != nil {
+		return nil, fmt.Errorf("load subscribable reports: %w", err)
+	}
+
+	res := make([]models.Subscription, 0, len(subs))
+	for _, s := range subs {
+		res = append(res, s.toModel())
+	}
+
+	return res, nil
+}
+
+func (r *SubscriptionRepository) GetSubscribableCount(ctx context.Context, userID int64) (int, error) {
+	const query = `select count(*) from reports r where ` + subscribableFilter
+
+	var count int
+
+	if err := r.db.GetContext(ctx, &count, query, userID); err != nil {
+		return 0, fmt.Errorf("count subscribable reports: %w", err)
+	}
+
+	return count, nil
+}
+
+// Subscriptions возвращает все отчеты, которые приходят в чат chatID, по всем топикам.
+func (r *SubscriptionRepository) Subscriptions(
+	ctx context.Context,
+	chatID int64,
+) ([]models.Subscription, error) {
+	const query = `select distinct rp.id as report_id, rp.name, rp.title, r.thread_id,
+       true as subscribed, rp.subscribable
+from reports_recipients rr
+join reports rp on rp.id = rr.report_id
+join recipients r on r.id = rr.recipient_id
+join chats c on c.id = r.chat_id
+where r.type = 'tg' and c.chat_id = $1
+order by r.thread_id nulls first, rp.title`
+
+	var subs []subscription
+
+	if err := r.db.SelectContext(ctx, &subs, query, chatID); err != nil {
+		return nil, fmt.Errorf("load subscriptions: %w", err)
+	}
+
+	res := make([]models.Subscription, 0, len(subs))
+	for _, s := range subs {
+		res = append(res, s.toModel())
+	}
+
+	return res, nil
+}
+
+// Subscribe подписывает чат и топик на отчет reportID от имени пользователя userID.
+// Чат сохраняется в chats, если его там еще нет. Повторная подписка ничего не меняет.
+func (r *SubscriptionRepository) Subscribe(
+	ctx context.Context,
+	userID int64,
+	chat models.TgChatDTO,
+	threadID int,
+	reportID int,
+) error {
+	const (
+		reportQuery = `select exists(select 1 from reports r where r.id = $2 and ` + subscribableFilter + `)`
+		chatQuery   = `insert into chats(chat_id, title, type, description, is_active)
+values ($1, $2, $3, $4, true)
+on conflict (chat_id) do update set title = excluded.title
+returning id`
+		recipientQuery = `with r as (
+    select id
+    from recipients
+    where type = 'tg'
+      and chat_id = $1
+      and coalesce(thread_id, 0) = $2
+      and remote_path is null
+    order by id
+    limit 1
+), ins as (
+    insert into recipients(name, chat_id, thread_id, type)
+    select $3, $1, nullif($2, 0), 'tg'
+    where not exists(select 1 from r)
+    returning id
+)
+select id from r
+union all
+select id from ins`
+		linkQuery = `insert into reports_recipients(report_id, recipient_id)
+select $1, $2
+where not exists(select 1 from reports_recipients where report_id = $1 and recipient_id = $2)`
+	)
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var ok bool
+
+	if err := tx.GetContext(ctx, &ok, reportQuery, userID, reportID); err != nil {
+		return fmt.Errorf("check report: %w", err)
+	}
+
+	if !ok {
+		return fmt.Errorf("report %d: %w", reportID, errorz.ErrNotFound)
+	}
+
+	var id int
+
+	err = tx.GetContext(ctx, &id, chatQuery, chat.ChatID, chat.Title, chat.Type, chat.Description)
+	if err != nil {
+		return fmt.Errorf("save chat: %w", err)
+	}
+
+	name := chat.Title
+	if threadID != 0 {
+		name = fmt.Sprintf("%s #%d", chat.Title, threadID)
+	}
+
+	var recipientID int
+
+	if err := tx.GetContext(ctx, &recipientID, recipientQuery, id, threadID, name); err != nil {
+		return fmt.Errorf("save recipient: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, linkQuery, reportID, recipientID); err != nil {
+		return fmt.Errorf("link recipient: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit subscription: %w", err)
+	}
+
+	return nil
+}
+
+// Unsubscribe отписывает чат и топик от отчета, доступного для подписки.
+// Получатель остается: он может быть привязан к другим отчетам.
+func (r *SubscriptionRepository) Unsubscribe(
+	ctx context.Context,
+	chatID int64,
+	threadID int,
+	reportID int,
+) error {
+	const query = `delete from reports_recipients rr
+using recipients r, chats c, reports rp
+where r.id = rr.recipient_id
+  and c.id = r.chat_id
+  and rp.id = rr.report_id
+  and rr.report_id = $3
+  and rp.subscribable = true
+  and r.type = 'tg'
+  and c.chat_id = $1
+  and coalesce(r.thread_id, 0) = $2`
+
+	res, err := r.db.ExecContext(ctx, query, chatID, threadID, reportID)
+	if err != nil {
+		return fmt.Errorf("unsubscribe: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("unsubscribe: %w", err)
+	}
+
+	if n == 0 {
+		return fmt.Errorf("subscription to report %d: %w", reportID, errorz.ErrNotFound)
+	}
+
+	return nil
+}
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
 
@@ -23,8 +25,8 @@ func NewUserRepository(db *sqlx.DB, log *slog.Logger) *UserRepository {
 
 func (u *UserRepository) Create(ctx context.Context, user *models.User) error {
 	const query = `INSERT INTO users (
-    telegram_id, username, first_name, last_name, role
-) VALUES ( $1,$2,$3,$4, $5)
+    telegram_id, username, first_name, last_name, role, is_placeholder
+) VALUES ( $1,$2,$3,$4, $5, $6)
 RETURNING *;`
 
 	if err := ctx.Err(); err != nil {
@@ -39,6 +41,7 @@ RETURNING *;`
 		user.FirstName,
 		user.LastName,
 		user.Role,
+		user.Placeholder,
 	)
 	if err != nil {
 		return fmt.Errorf("creating user: %w", err)
@@ -77,6 +80,40 @@ func (u *UserRepository) Update(ctx context.Context, user *models.User) error {
 	return nil
 }
 
+// LinkPlaceholder привязывает пользователя, добавленного по username, к настоящему Telegram ID.
+// Возвращает ErrNotFound, если такого пользователя нет.
+func (u *UserRepository) LinkPlaceholder(ctx context.Context, user models.User) (*models.User, error) {
+	const query = `UPDATE users
+    SET telegram_id = $2,
+        first_name = $3,
+        last_name = $4,
+        is_placeholder = false
+    WHERE id = (
+        SELECT id FROM users
+        WHERE lower(username) = lower($1) AND is_placeholder
+        ORDER BY id
+        LIMIT 1
+    )
+RETURNING *;`
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("user repository link placeholder: %w", err)
+	}
+
+	linked := &models.User{}
+
+	err := u.db.GetContext(ctx, linked, query, user.Username, user.TelegramID, user.FirstName, user.LastName)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, models.ErrNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("link placeholder: %w", err)
+	}
+
+	return linked, nil
+}
+
 func (u *UserRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
 	const query = `SELECT * FROM users
 WHERE username = $1
@@ -16,6 +16,9 @@ type Router struct {
 	adminHl *handlers.AdminHandler
 	textHl  *handlers.TextHandler
 	userHl  *handlers.UserHandler
+	subsHl  *handlers.SubscriptionHandler
+	invHl   *handlers.InviteHandler
+	auditHl *handlers.AuditHandler
 	mw      *middlewares.Mw
 }
 
@@ -24,6 +27,9 @@ func NewRouter(
 	admin *handlers.AdminHandler,
 	user *handlers.UserHandler,
 	text *handlers.TextHandler,
+	subs *handlers.SubscriptionHandler,
+	inv *handlers.InviteHandler,
+	audit *handlers.AuditHandler,
 	mw *middlewares.Mw,
 ) *Router {
 	return &Router{
@@ -31,6 +37,9 @@ func NewRouter(
 		adminHl: admin,
 		userHl:  user,
 		textHl:  text,
+		subsHl:  subs,
+		invHl:   inv,
+		auditHl: audit,
 		mw:      mw,
 	}
 }
@@ -46,6 +55,7 @@ func (r *Router) Setup() {
 	}))
 	register := r.bot.Group()
 	register.Handle(menu.RegisterCommand, r.userHl.RegisterUser)
+	register.Handle(menu.UserStart, r.start)
 
 	text := r.bot.Group()
 	text.Handle(telebot.OnText, r.textHl.ProcessTextInput, r.mw.UserAuthMiddleware)
@@ -54,12 +64,25 @@ func (r *Router) Setup() {
 
 	userOnly.Use(r.mw.UserAuthMiddleware)
 
-	userOnly.Handle(menu.UserStart, r.userHl.StartUser)
 	userOnly.Handle(&menu.LoadAndShowReportUser, r.userHl.LoadReports)
 	userOnly.Handle(&telebot.InlineButton{Unique: "back_report_list"}, r.userHl.LoadReportsPage)
 	userOnly.Handle(&telebot.InlineButton{Unique: "next_report_list"}, r.userHl.LoadReportsPage)
 	userOnly.Handle(&telebot.InlineButton{Unique: "_"}, r.userHl.IgnoreReportPage)
 	userOnly.Handle(&telebot.InlineButton{Unique: "report"}, r.userHl.GenerateSelectedReport)
+	userOnly.Handle(&telebot.InlineButton{Unique: "rp_month"}, r.userHl.ReportParamMonth)
+	userOnly.Handle(&telebot.InlineButton{Unique: "rp_day"}, r.userHl.ReportParamDay)
+	userOnly.Handle(&telebot.InlineButton{Unique: "rp_opt"}, r.userHl.ReportParamOption)
+	userOnly.Handle(&telebot.InlineButton{Unique: "rp_cancel"}, r.userHl.ReportParamCancel)
+
+	subscriber := r.bot.Group()
+	subscriber.Use(r.mw.SubscriberMiddleware)
+	subscriber.Handle(menu.SubscribeCommand, r.subsHl.Subscribe)
+	subscriber.Handle(&menu.Subscriptions, r.subsHl.Subscribe)
+	subscriber.Handle(menu.UnsubscribeCommand, r.subsHl.Unsubscribe)
+	subscriber.Handle(menu.SubscriptionsCommand, r.subsHl.Subscriptions)
+	subscriber.Handle(&telebot.InlineButton{Unique: "sub_page"}, r.subsHl.SubscribePage)
+	subscriber.Handle(&telebot.InlineButton{Unique: "sub_toggle"}, r.subsHl.ToggleSubscription)
+	subscriber.Handle(&telebot.InlineButton{Unique: "unsub"}, r.subsHl.UnsubscribeReport)
 
 	adminOnly := r.bot.Group()
 
@@ -79,6 +102,42 @@ func (r *Router) Setup() {
 	adminOnly.Handle(&menu.StartCron, r.adminHl.StartCronJobs)
 	adminOnly.Handle(&menu.ManageCron, r.adminHl.ManageCron)
 	adminOnly.Handle(&menu.StopCron, r.adminHl.StopCronJobs)
+	adminOnly.Handle(&menu.FlushCache, r.adminHl.FlushCaches)
+	adminOnly.Handle(&menu.ManageReports, r.adminHl.ManageReports)
+	adminOnly.Handle(&menu.CreateReport, r.adminHl.CreateReport)
+	adminOnly.Handle(&menu.EditReport, r.adminHl.EditReport)
+	adminOnly.Handle(&telebot.InlineButton{Unique: "re_list"}, r.adminHl.EditReportPage)
+	adminOnly.Handle(&telebot.InlineButton{Unique: "re_pick"}, r.adminHl.EditReportPick)
+	adminOnly.Handle(&telebot.InlineButton{Unique: "re_fmt"}, r.adminHl.ReportToggleFormat)
+	adminOnly.Handle(&telebot.InlineButton{Unique: "re_fmt_done"}, r.adminHl.ReportFormatsDone)
+	adminOnly.Handle(&telebot.InlineButton{Unique: "re_chat"}, r.adminHl.ReportToggleChat)
+	adminOnly.Handle(&telebot.InlineButton{Unique: "re_chat_done"}, r.adminHl.ReportChatsDone)
+	adminOnly.Handle(&telebot.InlineButton{Unique: "re_cron"}, r.adminHl.ReportPickCron)
+	adminOnly.Handle(&telebot.InlineButton{Unique: "re_expr_all"}, r.adminHl.ReportAlwaysSend)
+	adminOnly.Handle(&telebot.InlineButton{Unique: "re_field"}, r.adminHl.ReportEditField)
+	adminOnly.Handle(&telebot.InlineButton{Unique: "re_active"}, r.adminHl.ReportToggleActive)
+	adminOnly.Handle(&telebot.InlineButton{Unique: "re_subscribable"}, r.adminHl.ReportToggleSubscribable)
+	adminOnly.Handle(&telebot.InlineButton{Unique: "re_save"}, r.adminHl.ReportSave)
+	adminOnly.Handle(&telebot.InlineButton{Unique: "re_cancel"}, r.adminHl.ReportCancel)
+	adminOnly.Handle(&telebot.InlineButton{Unique: "reg_approve"}, r.adminHl.ApproveRegistration)
+	adminOnly.Handle(&telebot.InlineButton{Unique: "reg_deny"}, r.adminHl.DenyRegistration)
+	adminOnly.Handle(&menu.Invites, r.invHl.Invites)
+	adminOnly.Handle(&telebot.InlineButton{Unique: "inv_list"}, r.invHl.InvitesPage)
+	adminOnly.Handle(&telebot.InlineButton{Unique: "inv_new"}, r.invHl.NewInvite)
+	adminOnly.Handle(&telebot.InlineButton{Unique: "inv_role"}, r.invHl.InviteRole)
+	adminOnly.Handle(&telebot.InlineButton{Unique: "inv_group"}, r.invHl.InviteToggleGroup)
+	adminOnly.Handle(&telebot.InlineButton{Unique: "inv_create"}, r.invHl.InviteCreate)
+	adminOnly.Handle(&telebot.InlineButton{Unique: "inv_revoke"}, r.invHl.InviteRevoke)
+	adminOnly.Handle(&menu.AuditLog, r.auditHl.AuditLog)
+	adminOnly.Handle(&telebot.InlineButton{Unique: "audit_page"}, r.auditHl.AuditLogPage)
+	adminOnly.Handle(&telebot.InlineButton{Unique: "audit_csv"}, r.auditHl.AuditLogExport)
+	adminOnly.Handle(&menu.RunControl, r.adminHl.RunControls)
+	adminOnly.Handle(&telebot.InlineButton{Unique: "rc_list"}, r.adminHl.RunControlList)
+	adminOnly.Handle(&telebot.InlineButton{Unique: "rc_item"}, r.adminHl.RunControlItem)
+	adminOnly.Handle(&telebot.InlineButton{Unique: "rc_pause"}, r.adminHl.RunControlPause)
+	adminOnly.Handle(&telebot.InlineButton{Unique: "rc_skip"}, r.adminHl.RunControlSkip)
+	adminOnly.Handle(&telebot.InlineButton{Unique: "rc_resume"}, r.adminHl.RunControlResume)
+	adminOnly.Handle(&telebot.InlineButton{Unique: "rc_run"}, r.adminHl.RunControlRun)
 	adminOnly.Handle(
 		&telebot.InlineButton{Unique: "add_admin"},
 		r.adminHl.AddUserWithAdminRole,
@@ -92,3 +151,14 @@ func (r *Router) Setup() {
 	adminOnly.Handle(&telebot.InlineButton{Unique: "_"}, r.adminHl.IgnoreReportPage)
 	adminOnly.Handle(&telebot.InlineButton{Unique: "report"}, r.adminHl.GenerateSelectedReport)
 }
+
+// start открывает меню пользователя. /start с токеном приглашения (deep link
+// t.me/<бот>?start=<token>) регистрирует отправителя, поэтому проверка доступа
+// выполняется только для обычного /start.
+func (r *Router) start(c telebot.Context) error {
+	if c.Message() != nil && c.Message().Payload != "" {
+		return r.invHl.AcceptInvite(c)
+	}
+
+	return r.mw.UserAuthMiddleware(r.userHl.StartUser)(c)
+}
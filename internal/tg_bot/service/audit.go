@@ -0,0 +1,155 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"support_bot/internal/models"
+	"support_bot/internal/tg_bot/repository"
+)
+
+const auditPageSize = 10
+
+// Audit записывает в журнал изменения, сделанные из бота.
+type Audit struct {
+	repo *repository.AuditRepository
+
+	log *slog.Logger
+}
+
+func NewAudit(repo *repository.AuditRepository, log *slog.Logger) *Audit {
+	l := log.With(slog.Any("module", "tg_bot.service.audit"))
+
+	return &Audit{
+		repo: repo,
+		log:  l,
+	}
+}
+
+// Record записывает действие action пользователя by над target. before и after —
+// состояние объекта до и после изменения, nil — состояния нет.
+// Ошибка записи только логируется: журнал не должен мешать самому действию.
+func (a *Audit) Record(
+	ctx context.Context,
+	by string,
+	action models.AuditAction,
+	target string,
+	before, after any,
+) {
+	e := models.AuditEntry{
+		Actor:  by,
+		Action: action,
+		Target: target,
+		Before: a.marshal(ctx, before),
+		After:  a.marshal(ctx, after),
+	}
+
+	if err := a.repo.Write(ctx, e); err != nil {
+		a.log.ErrorContext(
+			ctx,
+			"unable to write audit entry",
+			slog.Any("action", action),
+			slog.Any("target", target),
+			slog.Any("by", by),
+			slog.Any("error", err),
+		)
+	}
+}
+
+func (a *Audit) marshal(ctx context.Context, v any) *string {
+	if v == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		a.log.ErrorContext(ctx, "unable to marshal audit state", slog.Any("error", err))
+
+		return nil
+	}
+
+	s := string(data)
+
+	return &s
+}
+
+func (a *Audit) LoadPage(ctx context.Context, page int) (models.LoadAuditRPL, error) {
+	count, err := a.repo.Count(ctx)
+	if err != nil {
+		return models.LoadAuditRPL{}, err
+	}
+
+	pageCount := max((count+auditPageSize-1)/auditPageSize, 1)
+	page = max(min(page, pageCount), 1)
+
+	entries, err := a.repo.Load(ctx, page, auditPageSize)
+	if err != nil {
+		return models.LoadAuditRPL{}, err
+	}
+
+	return models.LoadAuditRPL{
+		PageCount:   pageCount,
+		CurrentPage: page,
+		Entries:     entries,
+	}, nil
+}
+
+// ExportCSV выгружает весь журнал в CSV.
+func (a *Audit) ExportCSV(ctx context.Context) ([]byte, error) {
+	entries, err := a.repo.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+
+	w := csv.NewWriter(&buf)
+
+	//nolint:errcheck
+	w.Write([]string{"id", "created_at", "actor", "action", "target", "before", "after"})
+
+	for _, e := range entries {
+		//nolint:errcheck
+		w.Write([]string{
+			fmt.Sprint(e.ID),
+			e.CreatedAt.Format(time.RFC3339),
+			csvCell(e.Actor),
+			csvCell(string(e.Action)),
+			csvCell(e.Target),
+			csvCell(deref(e.Before)),
+			csvCell(deref(e.After)),
+		})
+	}
+
+	w.Flush()
+
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("write audit csv: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// csvCell экранирует значение, которое табличный редактор принял бы за формулу:
+// названия чатов и отчетов вводят пользователи.
+func csvCell(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+
+	return s
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
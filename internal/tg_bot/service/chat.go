@@ -18,20 +18,22 @@ type ChatProvider interface {
 type Chat struct {
 	repo   ChatProvider
 	notify *Notify
+	audit  *Audit
 	log    *slog.Logger
 }
 
-func NewChat(repo ChatProvider, notify *Notify, log *slog.Logger) *Chat {
+func NewChat(repo ChatProvider, notify *Notify, audit *Audit, log *slog.Logger) *Chat {
 	l := log.With(slog.Any("module", "tg_bot.service.chat"))
 
 	return &Chat{
 		repo:   repo,
 		notify: notify,
+		audit:  audit,
 		log:    l,
 	}
 }
 
-func (c *Chat) AddActive(ctx context.Context, chat *models.TgChatDTO) error {
+func (c *Chat) AddActive(ctx context.Context, chat *models.TgChatDTO, by string) error {
 	ch, _ := c.repo.GetByTitle(ctx, chat.Title)
 	if ch != nil {
 		c.notify.SendAdminNotify(ctx, newAddNewChatErrorTemplate(*chat, models.ErrAlreadyExist))
@@ -44,12 +46,13 @@ func (c *Chat) AddActive(ctx context.Context, chat *models.TgChatDTO) error {
 		return fmt.Errorf("%w %w", models.ErrInternal, err)
 	}
 
+	c.audit.Record(ctx, by, models.AuditChatAdd, chat.Title, nil, chat)
 	c.notify.SendAdminNotify(ctx, newAddNewChatSuccessTemplate(*chat))
 
 	return nil
 }
 
-func (c *Chat) Add(ctx context.Context, chat *models.TgChatDTO) error {
+func (c *Chat) Add(ctx context.Context, chat *models.TgChatDTO, by string) error {
 	ch, _ := c.repo.GetByTitle(ctx, chat.Title)
 	if ch != nil {
 		c.notify.SendAdminNotify(ctx, newAddNewChatErrorTemplate(*chat, models.ErrAlreadyExist))
@@ -62,11 +65,13 @@ func (c *Chat) Add(ctx context.Context, chat *models.TgChatDTO) error {
 		return fmt.Errorf("%w %w", models.ErrInternal, err)
 	}
 
+	c.audit.Record(ctx, by, models.AuditChatAdd, chat.Title, nil, chat)
 	c.notify.SendAdminNotify(ctx, newAddNewChatSuccessTemplate(*chat))
+
 	return nil
 }
 
-func (c *Chat) Remove(ctx context.Context, title string) error {
+func (c *Chat) Remove(ctx context.Context, title string, by string) error {
 	ch, err := c.repo.GetByTitle(ctx, title)
 	if err != nil {
 		return err
@@ -74,7 +79,13 @@ func (c *Chat) Remove(ctx context.Context, title string) error {
 
 	chID := ch.ChatID
 
-	return c.repo.Delete(ctx, chID)
+	if err := c.repo.Delete(ctx, chID); err != nil {
+		return err
+	}
+
+	c.audit.Record(ctx, by, models.AuditChatRemove, title, ch, nil)
+
+	return nil
 }
 
 func (c *Chat) GetAll(ctx context.Context) ([]models.TgChatDTO, error) {
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"support_bot/internal/models"
+	"support_bot/internal/tg_bot/repository"
+)
+
+// invitesListLimit — сколько последних приглашений показывать администратору.
+const invitesListLimit = 15
+
+// Invite выдает одноразовые приглашения в бота.
+type Invite struct {
+	repo  *repository.InviteRepository
+	ttl   time.Duration
+	audit *Audit
+
+	log *slog.Logger
+}
+
+func NewInvite(
+	repo *repository.InviteRepository,
+	ttl time.Duration,
+	audit *Audit,
+	log *slog.Logger,
+) *Invite {
+	l := log.With(slog.Any("module", "tg_bot.service.invite"))
+
+	return &Invite{
+		repo:  repo,
+		ttl:   ttl,
+		audit: audit,
+		log:   l,
+	}
+}
+
+func inviteTarget(id int) string {
+	return fmt.Sprintf("invite #%d", id)
+}
+
+// InviteLink возвращает deep link, открывающий бота bot с приглашением token.
+func InviteLink(bot, token string) string {
+	return fmt.Sprintf("https://t.me/%s?start=%s", bot, token)
+}
+
+func (i *Invite) Groups(ctx context.Context) ([]models.UserGroup, error) {
+	return i.repo.Groups(ctx)
+}
+
+// Create создает приглашение с ролью role и группами groupIDs, действующее ttl.
+func (i *Invite) Create(
+	ctx context.Context,
+	role string,
+	groupIDs []int,
+	by string,
+) (models.Invite, error) {
+	token, err := newInviteToken()
+	if err != nil {
+		return models.Invite{}, err
+	}
+
+	inv, err := i.repo.Create(ctx, models.Invite{
+		Token:     token,
+		Role:      role,
+		CreatedBy: by,
+		ExpiresAt: time.Now().Add(i.ttl),
+	}, groupIDs)
+	if err != nil {
+		i.log.ErrorContext(ctx, "unable to create invite", slog.Any("error", err))
+
+		return models.Invite{}, err
+	}
+
+	i.audit.Record(ctx, by, models.AuditInviteCreate, inviteTarget(inv.ID), nil, map[string]any{
+		"role":       inv.Role,
+		"groups":     inv.Groups,
+		"expires_at": inv.ExpiresAt,
+	})
+
+	i.log.InfoContext(
+		ctx,
+		"invite created",
+		slog.Any("invite", inv.ID),
+		slog.Any("role", role),
+		slog.Any("groups", inv.Groups),
+		slog.Any("by", by),
+	)
+
+	return inv, nil
+}
+
+func (i *Invite) List(ctx context.Context) ([]models.Invite, error) {
+	return i.repo.List(ctx, invitesListLimit)
+}
+
+// Revoke отзывает приглашение id.
+func (i *Invite) Revoke(ctx context.Context, id int, by string) error {
+	if err := i.repo.Revoke(ctx, id, by); err != nil {
+		return err
+	}
+
+	i.audit.Record(ctx, by, models.AuditInviteRevoke, inviteTarget(id), nil, nil)
+	i.log.InfoContext(ctx, "invite revoked", slog.Any("invite", id), slog.Any("by", by))
+
+	return nil
+}
+
+// Redeem регистрирует пользователя по приглашению token.
+func (i *Invite) Redeem(ctx context.Context, token string, user models.User) (models.Invite, error) {
+	inv, err := i.repo.Redeem(ctx, token, user)
+	if err != nil {
+		i.log.InfoContext(
+			ctx,
+			"invite not redeemed",
+			slog.Any("user", user.TelegramID),
+			slog.Any("invite", inv.ID),
+			slog.Any("error", err),
+		)
+
+		return inv, err
+	}
+
+	i.log.InfoContext(
+		ctx,
+		"invite redeemed",
+		slog.Any("invite", inv.ID),
+		slog.Any("user", user.TelegramID),
+		slog.Any("username", user.Username),
+		slog.Any("role", inv.Role),
+	)
+
+	return inv, nil
+}
+
+// newInviteToken возвращает случайный токен из символов, допустимых в параметре start.
+func newInviteToken() (string, error) {
+	b := make([]byte, 18)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate invite token: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
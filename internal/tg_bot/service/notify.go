@@ -4,6 +4,7 @@ import (
 	"context"
 	"log/slog"
 
+	"gopkg.in/telebot.v4"
 	"support_bot/internal/delivery/telegram"
 	"support_bot/internal/models"
 	"support_bot/internal/tg_bot/repository"
@@ -20,6 +21,11 @@ func NewNotify(tg *telegram.ChatAdaptor, user *repository.UserRepository, log *s
 }
 
 func (n *Notify) SendAdminNotify(ctx context.Context, msg string) {
+	n.SendAdminMarkup(ctx, msg, nil)
+}
+
+// SendAdminMarkup рассылает администраторам сообщение с инлайн-клавиатурой.
+func (n *Notify) SendAdminMarkup(ctx context.Context, msg string, markup *telebot.ReplyMarkup) {
 	admins, err := n.user.GetAllAdmins(ctx)
 	if err != nil {
 		n.log.ErrorContext(
@@ -37,7 +43,7 @@ func (n *Notify) SendAdminNotify(ctx context.Context, msg string) {
 	}
 
 	for _, admin := range admins {
-		_, err := n.tg.SendText(ctx, models.TgChat{ChatID: admin.TelegramID}, msg)
+		_, err := n.tg.SendTextWithMarkup(ctx, models.TgChat{ChatID: admin.TelegramID}, msg, markup)
 		if err != nil {
 			n.log.ErrorContext(
 				ctx,
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+
+	"gopkg.in/telebot.v4"
+	"support_bot/internal/errorz"
+	"support_bot/internal/models"
+	"support_bot/internal/tg_bot/repository"
+)
+
+// Registration обрабатывает заявки на регистрацию: пользователь отправляет /register,
+// администраторы получают заявку с кнопками «Одобрить» и «Отклонить».
+type Registration struct {
+	repo   *repository.RegistrationRepository
+	users  *repository.UserRepository
+	notify *Notify
+	audit  *Audit
+
+	log *slog.Logger
+}
+
+func NewRegistration(
+	repo *repository.RegistrationRepository,
+	users *repository.UserRepository,
+	notify *Notify,
+	audit *Audit,
+	log *slog.Logger,
+) *Registration {
+	l := log.With(slog.Any("module", "tg_bot.service.registration"))
+
+	return &Registration{
+		repo:   repo,
+		users:  users,
+		notify: notify,
+		audit:  audit,
+		log:    l,
+	}
+}
+
+// Register регистрирует пользователя. Пользователь, которого администратор уже
+// добавил по username, привязывается сразу. Для остальных создается заявка,
+// о которой уведомляются администраторы. Возвращает статус заявки.
+func (r *Registration) Register(ctx context.Context, user models.User) (models.RegistrationStatus, error) {
+	if _, err := r.users.GetByTgID(ctx, user.TelegramID); err == nil {
+		return models.RegistrationApproved, nil
+	}
+
+	if user.Username != "" {
+		_, err := r.users.LinkPlaceholder(ctx, user)
+		if err == nil {
+			r.log.InfoContext(ctx, "placeholder user linked", slog.Any("username", user.Username))
+
+			return models.RegistrationApproved, nil
+		}
+
+		if !errors.Is(err, models.ErrNotFound) {
+			return "", err
+		}
+	}
+
+	req, err := r.repo.Get(ctx, user.TelegramID)
+	if err == nil && req.Status != models.RegistrationApproved {
+		return req.Status, nil
+	}
+
+	if err != nil && !errors.Is(err, errorz.ErrNotFound) {
+		return "", err
+	}
+
+	req, err = r.repo.Create(ctx, user)
+	if err != nil {
+		r.log.ErrorContext(ctx, "unable to create registration request", slog.Any("error", err))
+
+		return "", err
+	}
+
+	r.notify.SendAdminMarkup(ctx, newRegistrationRequestTemplate(req), registrationMarkup(req.ID))
+
+	return models.RegistrationPending, nil
+}
+
+// Approve одобряет заявку id и создает пользователя.
+func (r *Registration) Approve(ctx context.Context, id int, by string) (models.RegistrationRequest, error) {
+	return r.decide(ctx, id, models.RegistrationApproved, by)
+}
+
+func (r *Registration) Deny(ctx context.Context, id int, by string) (models.RegistrationRequest, error) {
+	return r.decide(ctx, id, models.RegistrationDenied, by)
+}
+
+func (r *Registration) decide(
+	ctx context.Context,
+	id int,
+	status models.RegistrationStatus,
+	by string,
+) (models.RegistrationRequest, error) {
+	req, err := r.repo.Decide(ctx, id, status, by)
+	if err != nil {
+		return models.RegistrationRequest{}, err
+	}
+
+	action := models.AuditRegistrationDeny
+	if status == models.RegistrationApproved {
+		action = models.AuditRegistrationApprove
+	}
+
+	r.audit.Record(ctx, by, action, req.DisplayName(), nil, req)
+
+	r.log.InfoContext(
+		ctx,
+		"registration request decided",
+		slog.Any("request", id),
+		slog.Any("user", req.TelegramID),
+		slog.Any("status", status),
+		slog.Any("by", by),
+	)
+
+	return req, nil
+}
+
+func registrationMarkup(id int) *telebot.ReplyMarkup {
+	data := strconv.Itoa(id)
+
+	return &telebot.ReplyMarkup{InlineKeyboard: [][]telebot.InlineButton{{
+		{Unique: "reg_approve", Text: "✅ Одобрить", Data: data},
+		{Unique: "reg_deny", Text: "❌ Отклонить", Data: data},
+	}}}
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"time"
 
+	"support_bot/internal/control"
 	eventcreator "support_bot/internal/event_creator"
 	models2 "support_bot/internal/models"
 	"support_bot/internal/sheduler"
@@ -15,17 +17,26 @@ type Report struct {
 	*sheduler.SheduleAPI
 	*eventcreator.EventAPI
 
-	repo *repository.ReportRepository
+	repo     *repository.ReportRepository
+	controls *control.Repository
+	audit    *Audit
 
 	log *slog.Logger
 }
 
-const reportsPageSize = 5
+const (
+	reportsPageSize  = 5
+	controlsPageSize = 8
+	// triggerSourceBot — источник ручных запусков из бота в RunInfo.Trigger.
+	triggerSourceBot = "bot"
+)
 
 func NewReportService(
 	shd *sheduler.SheduleAPI,
 	eventAPI *eventcreator.EventAPI,
 	repo *repository.ReportRepository,
+	controls *control.Repository,
+	audit *Audit,
 	log *slog.Logger,
 ) *Report {
 	l := log.With(slog.Any("module", "tg_bot.service.report"))
@@ -34,16 +45,26 @@ func NewReportService(
 		SheduleAPI: shd,
 		EventAPI:   eventAPI,
 		repo:       repo,
+		controls:   controls,
+		audit:      audit,
 		log:        l,
 	}
 }
 
-func (r *Report) LoadReportsWithPagination(ctx context.Context) (models2.LoadReportRPL, error) {
-	return r.LoadReportByPage(ctx, 1)
+func (r *Report) LoadReportsWithPagination(
+	ctx context.Context,
+	userID int64,
+) (models2.LoadReportRPL, error) {
+	return r.LoadReportByPage(ctx, userID, 1)
 }
 
-func (r *Report) LoadReportByPage(ctx context.Context, page int) (models2.LoadReportRPL, error) {
-	rCount, err := r.repo.GetReportsCount(ctx)
+// LoadReportByPage возвращает страницу отчетов, которые пользователь userID может запустить.
+func (r *Report) LoadReportByPage(
+	ctx context.Context,
+	userID int64,
+	page int,
+) (models2.LoadReportRPL, error) {
+	rCount, err := r.repo.GetReportsCount(ctx, userID)
 	if err != nil {
 		return models2.LoadReportRPL{}, err
 	}
@@ -62,7 +83,7 @@ func (r *Report) LoadReportByPage(ctx context.Context, page int) (models2.LoadRe
 		page = pageCount
 	}
 
-	reports, err := r.repo.LoadReports(ctx, page)
+	reports, err := r.repo.LoadReports(ctx, userID, page)
 	if err != nil {
 		return models2.LoadReportRPL{}, err
 	}
@@ -77,18 +98,246 @@ func (r *Report) LoadReportByPage(ctx context.Context, page int) (models2.LoadRe
 	return rpl, nil
 }
 
+// CheckAccess возвращает имя отчета reportID или ErrForbidden, если пользователю userID
+// отчет недоступен. Запускать нужно отчет с этим именем, а не с именем из callback:
+// данные callback присылает клиент.
+func (r *Report) CheckAccess(ctx context.Context, userID int64, reportID int) (string, error) {
+	name, ok, err := r.repo.AccessibleName(ctx, userID, reportID)
+	if err != nil {
+		return "", err
+	}
+
+	if !ok {
+		r.log.WarnContext(
+			ctx,
+			"report access denied",
+			slog.Any("user", userID),
+			slog.Any("report", reportID),
+		)
+
+		return "", models2.ErrForbidden
+	}
+
+	return name, nil
+}
+
+func (r *Report) ReportParams(ctx context.Context, reportID int) ([]models2.ReportParam, error) {
+	return r.repo.LoadParams(ctx, reportID)
+}
+
+// GenerateReportByName запускает отчет в чат chat. params — значения параметров
+// отчета, они передаются в запросы Metabase и доступны в шаблонах как .run.Params.
 func (r *Report) GenerateReportByName(
 	ctx context.Context,
 	reportName string,
 	chat *models2.Chat,
+	params map[string]string,
 ) error {
 	rcpt := models2.Recipient{
-		Name:                    "SpetialTGRcpt",
-		Chat:                    chat,
-		Type:                    models2.TelegramRecipient,
-		NeedDeleteAfterEndOfDay: false,
+		Name: "SpetialTGRcpt",
+		Chat: chat,
+		Type: models2.TelegramRecipient,
+	}
+	var run models2.RunInfo
+	if len(params) > 0 {
+		run = models2.RunInfo{Trigger: triggerSourceBot, Params: params}
+	}
+
+	r.ProduceSpecialEvent(ctx, reportName, rcpt, run)
+
+	return nil
+}
+
+func (r *Report) FlushCaches(ctx context.Context, by string) error {
+	err := r.repo.FlushCaches(ctx)
+	if err != nil {
+		r.log.ErrorContext(ctx, "flush caches", slog.Any("error", err))
+
+		return err
+	}
+
+	r.audit.Record(ctx, by, models2.AuditCacheFlush, "reports", nil, nil)
+
+	return nil
+}
+
+// StartJobs перезапускает cron-рассылки.
+func (r *Report) StartJobs(ctx context.Context, by string) error {
+	if err := r.SheduleAPI.Start(ctx); err != nil {
+		r.log.ErrorContext(ctx, "start jobs", slog.Any("error", err))
+
+		return err
+	}
+
+	r.audit.Record(ctx, by, models2.AuditScheduleStart, "scheduler", nil, nil)
+
+	return nil
+}
+
+// StopJobs останавливает cron-рассылки.
+func (r *Report) StopJobs(ctx context.Context, by string) error {
+	if err := r.SheduleAPI.Stop(ctx); err != nil {
+		r.log.ErrorContext(ctx, "stop jobs", slog.Any("error", err))
+
+		return err
+	}
+
+	r.audit.Record(ctx, by, models2.AuditScheduleStop, "scheduler", nil, nil)
+
+	return nil
+}
+
+func controlTarget(item models2.ControlItem) string {
+	return fmt.Sprintf("%s %s", item.Control.Target, item.Name)
+}
+
+func (r *Report) LoadControlPage(
+	ctx context.Context,
+	target models2.ControlTarget,
+	page int,
+) (models2.LoadControlRPL, error) {
+	count, err := r.repo.GetControlItemsCount(ctx, target)
+	if err != nil {
+		return models2.LoadControlRPL{}, err
+	}
+
+	if count <= 0 {
+		return models2.LoadControlRPL{}, fmt.Errorf("%s not found", target)
 	}
-	r.ProduceSpecialEvent(ctx, reportName, rcpt)
+
+	pageCount := (count + controlsPageSize - 1) / controlsPageSize
+	page = max(min(page, pageCount), 1)
+
+	items, err := r.repo.LoadControlItems(ctx, target, page, controlsPageSize)
+	if err != nil {
+		return models2.LoadControlRPL{}, err
+	}
+
+	return models2.LoadControlRPL{
+		Target:      target,
+		PageCount:   pageCount,
+		CurrentPage: page,
+		Items:       items,
+	}, nil
+}
+
+func (r *Report) GetControlItem(
+	ctx context.Context,
+	target models2.ControlTarget,
+	id int,
+) (models2.ControlItem, error) {
+	return r.repo.GetControlItem(ctx, target, id)
+}
+
+// ActiveControls возвращает действующие паузы и пропуски.
+func (r *Report) ActiveControls(ctx context.Context) ([]models2.RunControl, error) {
+	return r.controls.List(ctx)
+}
+
+func (r *Report) PauseItem(
+	ctx context.Context,
+	item models2.ControlItem,
+	until time.Time,
+	by string,
+) error {
+	if !until.After(time.Now()) {
+		return fmt.Errorf("pause end %s is in the past", until.Format(time.DateTime))
+	}
+
+	err := r.controls.Pause(ctx, item.Control.Target, item.Name, until, by)
+	if err != nil {
+		return err
+	}
+
+	r.audit.Record(
+		ctx,
+		by,
+		models2.AuditControlPause,
+		controlTarget(item),
+		item.Control,
+		map[string]any{"paused_until": until},
+	)
+
+	r.log.InfoContext(
+		ctx,
+		"runs paused",
+		slog.Any("target", item.Control.Target),
+		slog.Any("name", item.Name),
+		slog.Any("until", until),
+		slog.Any("by", by),
+	)
+
+	return nil
+}
+
+func (r *Report) SkipNextItem(ctx context.Context, item models2.ControlItem, by string) error {
+	err := r.controls.SkipNext(ctx, item.Control.Target, item.Name, by)
+	if err != nil {
+		return err
+	}
+
+	r.audit.Record(ctx, by, models2.AuditControlSkip, controlTarget(item), item.Control, nil)
+
+	r.log.InfoContext(
+		ctx,
+		"next run will be skipped",
+		slog.Any("target", item.Control.Target),
+		slog.Any("name", item.Name),
+		slog.Any("by", by),
+	)
+
+	return nil
+}
+
+func (r *Report) ResumeItem(ctx context.Context, item models2.ControlItem, by string) error {
+	err := r.controls.Resume(ctx, item.Control.Target, item.Name)
+	if err != nil {
+		return err
+	}
+
+	r.audit.Record(ctx, by, models2.AuditControlResume, controlTarget(item), item.Control, nil)
+
+	r.log.InfoContext(
+		ctx,
+		"runs resumed",
+		slog.Any("target", item.Control.Target),
+		slog.Any("name", item.Name),
+		slog.Any("by", by),
+	)
+
+	return nil
+}
+
+// RunItemNow запускает расписание или отчет вне очереди, даже если он на паузе.
+func (r *Report) RunItemNow(ctx context.Context, item models2.ControlItem, by string) error {
+	var err error
+
+	switch item.Control.Target {
+	case models2.ControlCron:
+		err = r.SheduleAPI.RunNow(ctx, item.Name, item.EventType, triggerSourceBot)
+	case models2.ControlReport:
+		err = r.Trigger(ctx, item.Name, models2.RunInfo{
+			ScheduledAt: time.Now(),
+			Trigger:     triggerSourceBot,
+			Force:       true,
+		})
+	default:
+		err = fmt.Errorf("unknown control target %q", item.Control.Target)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	r.audit.Record(ctx, by, models2.AuditControlRun, controlTarget(item), nil, nil)
+
+	r.log.InfoContext(
+		ctx,
+		"run started manually",
+		slog.Any("target", item.Control.Target),
+		slog.Any("name", item.Name),
+		slog.Any("by", by),
+	)
 
 	return nil
 }
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	models2 "support_bot/internal/models"
+	"support_bot/internal/tg_bot/repository"
+)
+
+// ExprValidator проверяет CEL-условие отправки.
+type ExprValidator interface {
+	Validate(expr string) error
+}
+
+// ReportEditor создает и редактирует отчеты из админ-меню.
+type ReportEditor struct {
+	repo  *repository.ReportEditorRepository
+	expr  ExprValidator
+	audit *Audit
+
+	log *slog.Logger
+}
+
+func NewReportEditor(
+	repo *repository.ReportEditorRepository,
+	expr ExprValidator,
+	audit *Audit,
+	log *slog.Logger,
+) *ReportEditor {
+	l := log.With(slog.Any("module", "tg_bot.service.report_editor"))
+
+	return &ReportEditor{
+		repo:  repo,
+		expr:  expr,
+		audit: audit,
+		log:   l,
+	}
+}
+
+// CheckName проверяет имя нового отчета.
+func (e *ReportEditor) CheckName(ctx context.Context, name string) error {
+	if err := models2.ValidateReportName(name); err != nil {
+		return err
+	}
+
+	exists, err := e.repo.Exists(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return fmt.Errorf("report %q: %w", name, models2.ErrAlreadyExist)
+	}
+
+	return nil
+}
+
+func (e *ReportEditor) ValidateExpr(expr string) error {
+	return e.expr.Validate(strings.TrimSpace(expr))
+}
+
+func (e *ReportEditor) Formats(ctx context.Context) ([]string, error) {
+	return e.repo.ExportFormats(ctx)
+}
+
+func (e *ReportEditor) Chats(ctx context.Context) ([]models2.TgChatDTO, error) {
+	return e.repo.ActiveChats(ctx)
+}
+
+func (e *ReportEditor) Crons(ctx context.Context) ([]models2.Cron, error) {
+	return e.repo.Crons(ctx)
+}
+
+func (e *ReportEditor) Cron(ctx context.Context, id int) (models2.Cron, error) {
+	return e.repo.CronByID(ctx, id)
+}
+
+func (e *ReportEditor) LoadReportsPage(ctx context.Context, page int) (models2.LoadReportRPL, error) {
+	count, err := e.repo.GetReportsCount(ctx)
+	if err != nil {
+		return models2.LoadReportRPL{}, err
+	}
+
+	if count <= 0 {
+		return models2.LoadReportRPL{}, fmt.Errorf("reports not found")
+	}
+
+	pageCount := (count + reportsPageSize - 1) / reportsPageSize
+	page = max(min(page, pageCount), 1)
+
+	reports, err := e.repo.LoadReports(ctx, page, reportsPageSize)
+	if err != nil {
+		return models2.LoadReportRPL{}, err
+	}
+
+	return models2.LoadReportRPL{
+		ReportsTotal: count,
+		PageCount:    pageCount,
+		CurrentPage:  page,
+		Reports:      reports,
+	}, nil
+}
+
+func (e *ReportEditor) Load(ctx context.Context, id int) (models2.ReportDraft, error) {
+	return e.repo.LoadDraft(ctx, id)
+}
+
+// Save проверяет черновик целиком и сохраняет его. Возвращает id отчета.
+func (e *ReportEditor) Save(ctx context.Context, d models2.ReportDraft, by string) (int, error) {
+	if err := d.Validate(); err != nil {
+		return 0, err
+	}
+
+	if err := e.ValidateExpr(d.Evaluation); err != nil {
+		return 0, err
+	}
+
+	var before any
+
+	if d.ID == 0 {
+		if err := e.CheckName(ctx, d.Name); err != nil {
+			return 0, err
+		}
+	} else if prev, err := e.repo.LoadDraft(ctx, d.ID); err == nil {
+		before = prev
+	}
+
+	id, err := e.repo.SaveDraft(ctx, d)
+	if err != nil {
+		e.log.ErrorContext(ctx, "unable to save report", slog.Any("report", d.Name), slog.Any("error", err))
+
+		return 0, err
+	}
+
+	action := models2.AuditReportUpdate
+	if d.ID == 0 {
+		action = models2.AuditReportCreate
+	}
+
+	e.audit.Record(ctx, by, action, d.Name, before, d)
+
+	e.log.InfoContext(
+		ctx,
+		"report saved",
+		slog.Any("report", d.Name),
+		slog.Any("id", id),
+		slog.Any("created", d.ID == 0),
+		slog.Any("by", by),
+	)
+
+	return id, nil
+}
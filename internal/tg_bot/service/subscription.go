@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	models2 "support_bot/internal/models"
+	"support_bot/internal/tg_bot/repository"
+)
+
+const subscriptionsPageSize = 8
+
+// Subscription подписывает чаты на отчеты, помеченные как доступные для подписки.
+type Subscription struct {
+	repo  *repository.SubscriptionRepository
+	audit *Audit
+
+	log *slog.Logger
+}
+
+func NewSubscription(
+	repo *repository.SubscriptionRepository,
+	audit *Audit,
+	log *slog.Logger,
+) *Subscription {
+	l := log.With(slog.Any("module", "tg_bot.service.subscription"))
+
+	return &Subscription{
+		repo:  repo,
+		audit: audit,
+		log:   l,
+	}
+}
+
+func subscriptionTarget(chatID int64, threadID, reportID int) string {
+	return fmt.Sprintf("chat %d thread %d report %d", chatID, threadID, reportID)
+}
+
+// LoadPage возвращает страницу отчетов, на которые пользователь userID может подписать чат,
+// с отметками для чата и топика.
+func (s *Subscription) LoadPage(
+	ctx context.Context,
+	userID int64,
+	chatID int64,
+	threadID int,
+	page int,
+) (models2.LoadSubscriptionRPL, error) {
+	count, err := s.repo.GetSubscribableCount(ctx, userID)
+	if err != nil {
+		return models2.LoadSubscriptionRPL{}, err
+	}
+
+	if count <= 0 {
+		return models2.LoadSubscriptionRPL{}, fmt.Errorf("subscribable reports not found")
+	}
+
+	pageCount := (count + subscriptionsPageSize - 1) / subscriptionsPageSize
+	page = max(min(page, pageCount), 1)
+
+	reports, err := s.repo.LoadSubscribable(ctx, userID, chatID, threadID, page, subscriptionsPageSize)
+	if err != nil {
+		return models2.LoadSubscriptionRPL{}, err
+	}
+
+	return models2.LoadSubscriptionRPL{
+		PageCount:   pageCount,
+		CurrentPage: page,
+		Reports:     reports,
+	}, nil
+}
+
+// Toggle подписывает чат и топик на отчет или отписывает, если подписка уже есть.
+// Подписать можно только на отчет, выданный пользователю userID.
+// Возвращает true, если после вызова чат подписан.
+func (s *Subscription) Toggle(
+	ctx context.Context,
+	userID int64,
+	chat models2.TgChatDTO,
+	threadID int,
+	reportID int,
+	by string,
+) (bool, error) {
+	subs, err := s.repo.Subscriptions(ctx, chat.ChatID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, sub := range subs {
+		if sub.ReportID == reportID && sub.ThreadID == threadID {
+			return false, s.Unsubscribe(ctx, chat.ChatID, threadID, reportID, by)
+		}
+	}
+
+	if err := s.repo.Subscribe(ctx, userID, chat, threadID, reportID); err != nil {
+		s.log.ErrorContext(
+			ctx,
+			"unable to subscribe",
+			slog.Any("chat", chat.ChatID),
+			slog.Any("report", reportID),
+			slog.Any("error", err),
+		)
+
+		return false, err
+	}
+
+	s.audit.Record(ctx, by, models2.AuditSubscribe, subscriptionTarget(chat.ChatID, threadID, reportID), nil, nil)
+
+	s.log.InfoContext(
+		ctx,
+		"chat subscribed",
+		slog.Any("chat", chat.ChatID),
+		slog.Any("thread", threadID),
+		slog.Any("report", reportID),
+		slog.Any("by", by),
+	)
+
+	return true, nil
+}
+
+func (s *Subscription) Unsubscribe(
+	ctx context.Context,
+	chatID int64,
+	threadID int,
+	reportID int,
+	by string,
+) error {
+	if err := s.repo.Unsubscribe(ctx, chatID, threadID, reportID); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, by, models2.AuditUnsubscribe, subscriptionTarget(chatID, threadID, reportID), nil, nil)
+
+	s.log.InfoContext(
+		ctx,
+		"chat unsubscribed",
+		slog.Any("chat", chatID),
+		slog.Any("thread", threadID),
+		slog.Any("report", reportID),
+		slog.Any("by", by),
+	)
+
+	return nil
+}
+
+// List возвращает все отчеты, которые приходят в чат, включая назначенные администратором.
+func (s *Subscription) List(ctx context.Context, chatID int64) ([]models2.Subscription, error) {
+	return s.repo.Subscriptions(ctx, chatID)
+}
+
+// Unsubscribable возвращает подписки чата и топика, от которых можно отписаться из бота.
+func (s *Subscription) Unsubscribable(
+	ctx context.Context,
+	chatID int64,
+	threadID int,
+) ([]models2.Subscription, error) {
+	subs, err := s.repo.Subscriptions(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]models2.Subscription, 0, len(subs))
+
+	for _, sub := range subs {
+		if sub.Subscribable && sub.ThreadID == threadID {
+			res = append(res, sub)
+		}
+	}
+
+	return res, nil
+}
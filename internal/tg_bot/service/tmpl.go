@@ -2,6 +2,7 @@ package service
 
 import (
 	"fmt"
+	"html"
 
 	"support_bot/internal/models"
 )
@@ -21,3 +22,16 @@ func newAddNewChatSuccessTemplate(chat models.TgChatDTO) string {
 func newAddNewChatErrorTemplate(chat models.TgChatDTO, err error) string {
 	return fmt.Sprintf(addNewChatErrorTemplate, chat.String(), err)
 }
+
+const registrationRequestTemplate = `Новая заявка на регистрацию:
+%s
+Telegram ID: <code>%d</code>`
+
+func newRegistrationRequestTemplate(req models.RegistrationRequest) string {
+	name := req.DisplayName()
+	if req.FirstName != nil && req.Username != nil && *req.Username != "" {
+		name = fmt.Sprintf("%s (%s)", name, *req.FirstName)
+	}
+
+	return fmt.Sprintf(registrationRequestTemplate, html.EscapeString(name), req.TelegramID)
+}
@@ -14,6 +14,7 @@ type UserProvider interface {
 	GetByUsername(ctx context.Context, username string) (*models.User, error)
 	GetAll(ctx context.Context) ([]models.User, error)
 	GetByTgID(ctx context.Context, id int64) (*models.User, error)
+	LinkPlaceholder(ctx context.Context, user models.User) (*models.User, error)
 
 	Update(ctx context.Context, user *models.User) error
 	Delete(ctx context.Context, tgID int64) error
@@ -21,16 +22,18 @@ type UserProvider interface {
 
 // User репозиторий для работы с данными пользователя.
 type User struct {
-	repo UserProvider
-	log  *slog.Logger
+	repo  UserProvider
+	audit *Audit
+	log   *slog.Logger
 }
 
-func NewUser(repo UserProvider, log *slog.Logger) *User {
+func NewUser(repo UserProvider, audit *Audit, log *slog.Logger) *User {
 	l := log.With(slog.Any("module", "tg_bot.service.user"))
 
 	return &User{
-		repo: repo,
-		log:  l,
+		repo:  repo,
+		audit: audit,
+		log:   l,
 	}
 }
 
@@ -56,6 +59,24 @@ func (u *User) IsAllowed(ctx context.Context, id int64) (string, error) {
 	return user.Role, nil
 }
 
+// Link привязывает пользователя, добавленного администратором по username,
+// к Telegram ID при первом обращении к боту. Возвращает роль пользователя.
+func (u *User) Link(ctx context.Context, user models.User) (string, error) {
+	linked, err := u.repo.LinkPlaceholder(ctx, user)
+	if err != nil {
+		return models.Denied, err
+	}
+
+	u.log.InfoContext(
+		ctx,
+		"placeholder user linked",
+		slog.Any("username", linked.Username),
+		slog.Any("telegram_id", linked.TelegramID),
+	)
+
+	return linked.Role, nil
+}
+
 func (u *User) getAllUserIds(ctx context.Context) ([]int64, []int64, error) {
 	users, err := u.repo.GetAll(ctx)
 	if err != nil {
@@ -88,7 +109,7 @@ func (u *User) create(ctx context.Context, user *models.User) error {
 	return nil
 }
 
-func (u *User) CreateEmpty(ctx context.Context, username string, isAdmin bool) error {
+func (u *User) CreateEmpty(ctx context.Context, username string, isAdmin bool, by string) error {
 	user := models.NewEmptyUser(username, isAdmin)
 
 	err := u.repo.Create(ctx, &user)
@@ -98,6 +119,8 @@ func (u *User) CreateEmpty(ctx context.Context, username string, isAdmin bool) e
 		return err
 	}
 
+	u.audit.Record(ctx, by, models.AuditUserAdd, "@"+username, nil, user)
+
 	return nil
 }
 
@@ -116,7 +139,7 @@ func (u *User) AddUserComplete(ctx context.Context, user *models.User) error {
 	return u.update(ctx, user)
 }
 
-func (u *User) Delete(ctx context.Context, username string, primeReq bool) error {
+func (u *User) Delete(ctx context.Context, username string, primeReq bool, by string) error {
 	user, err := u.repo.GetByUsername(ctx, username)
 	if errors.Is(err, models.ErrNotFound) {
 		return err
@@ -139,6 +162,8 @@ func (u *User) Delete(ctx context.Context, username string, primeReq bool) error
 		return err
 	}
 
+	u.audit.Record(ctx, by, models.AuditUserDelete, "@"+username, user, nil)
+
 	return nil
 }
 
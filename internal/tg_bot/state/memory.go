@@ -0,0 +1,100 @@
+package state
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval — как часто Memory удаляет истекшие записи.
+const sweepInterval = time.Minute
+
+type memoryEntry[T any] struct {
+	v         T
+	expiresAt time.Time
+}
+
+// Memory хранит состояние в памяти процесса. Состояние теряется при перезапуске
+// и не видно другим экземплярам бота.
+type Memory[T any] struct {
+	mu        sync.Mutex
+	entries   map[int64]memoryEntry[T]
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemory[T any]() *Memory[T] {
+	return &Memory[T]{
+		entries: make(map[int64]memoryEntry[T]),
+		now:     time.Now,
+	}
+}
+
+func (m *Memory[T]) Get(_ context.Context, key int64) (T, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.entries[key]
+	if !ok || !m.now().Before(e.expiresAt) {
+		delete(m.entries, key)
+
+		var zero T
+
+		return zero, false, nil
+	}
+
+	return e.v, true, nil
+}
+
+func (m *Memory[T]) Set(_ context.Context, key int64, v T, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.set(key, v, ttl)
+
+	return nil
+}
+
+func (m *Memory[T]) Update(_ context.Context, key int64, fn func(*T), ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var v T
+
+	if e, ok := m.entries[key]; ok && m.now().Before(e.expiresAt) {
+		v = e.v
+	}
+
+	fn(&v)
+	m.set(key, v, ttl)
+
+	return nil
+}
+
+func (m *Memory[T]) set(key int64, v T, ttl time.Duration) {
+	now := m.now()
+	m.entries[key] = memoryEntry[T]{v: v, expiresAt: now.Add(ttl)}
+
+	if now.Sub(m.lastSweep) >= sweepInterval {
+		m.sweep(now)
+	}
+}
+
+func (m *Memory[T]) Delete(_ context.Context, key int64) error {
+	m.mu.Lock()
+	delete(m.entries, key)
+	m.mu.Unlock()
+
+	return nil
+}
+
+// sweep удаляет истекшие записи пользователей, которые больше не писали боту.
+func (m *Memory[T]) sweep(now time.Time) {
+	for key, e := range m.entries {
+		if !now.Before(e.expiresAt) {
+			delete(m.entries, key)
+		}
+	}
+
+	m.lastSweep = now
+}
@@ -0,0 +1,72 @@
+package state
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemory_TTL(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	m := NewMemory[string]()
+	m.now = func() time.Time { return now }
+
+	require.NoError(t, m.Set(ctx, 1, "menu", time.Minute))
+	require.NoError(t, m.Set(ctx, 2, "report", 10*time.Minute))
+
+	v, ok, err := m.Get(ctx, 1)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "menu", v)
+
+	now = now.Add(2 * time.Minute)
+
+	_, ok, err = m.Get(ctx, 1)
+	require.NoError(t, err)
+	assert.False(t, ok, "entry must expire after ttl")
+
+	v, ok, _ = m.Get(ctx, 2)
+	assert.True(t, ok)
+	assert.Equal(t, "report", v)
+
+	require.NoError(t, m.Delete(ctx, 2))
+
+	_, ok, _ = m.Get(ctx, 2)
+	assert.False(t, ok)
+}
+
+func TestMemory_Update(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	m := NewMemory[[]string]()
+	m.now = func() time.Time { return now }
+
+	add := func(s string) func(*[]string) {
+		return func(v *[]string) { *v = append(*v, s) }
+	}
+
+	require.NoError(t, m.Update(ctx, 1, add("name"), time.Minute))
+	require.NoError(t, m.Update(ctx, 1, add("title"), time.Minute))
+
+	v, ok, err := m.Get(ctx, 1)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"name", "title"}, v)
+
+	now = now.Add(2 * time.Minute)
+
+	require.NoError(t, m.Update(ctx, 1, add("cards"), time.Minute))
+
+	v, _, _ = m.Get(ctx, 1)
+	assert.Equal(t, []string{"cards"}, v, "expired entry must not be updated")
+}
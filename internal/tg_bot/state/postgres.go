@@ -0,0 +1,138 @@
+package state
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Postgres хранит состояние в таблице bot_sessions в виде JSON. Состояние
+// переживает перезапуск и общее для всех экземпляров бота.
+type Postgres[T any] struct {
+	db *sqlx.DB
+}
+
+func NewPostgres[T any](db *sqlx.DB) *Postgres[T] {
+	return &Postgres[T]{db: db}
+}
+
+func (p *Postgres[T]) Get(ctx context.Context, key int64) (T, bool, error) {
+	const query = `select data::text from bot_sessions where key = $1 and expires_at > now()`
+
+	var (
+		zero T
+		data string
+	)
+
+	err := p.db.GetContext(ctx, &data, query, key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return zero, false, nil
+	}
+
+	if err != nil {
+		return zero, false, fmt.Errorf("get bot session: %w", err)
+	}
+
+	var v T
+
+	if err := json.Unmarshal([]byte(data), &v); err != nil {
+		return zero, false, fmt.Errorf("unmarshal bot session: %w", err)
+	}
+
+	return v, true, nil
+}
+
+// Set сохраняет состояние и заодно удаляет истекшие записи.
+func (p *Postgres[T]) Set(ctx context.Context, key int64, v T, ttl time.Duration) error {
+	const query = `with expired as (
+    delete from bot_sessions where expires_at <= now() and key <> $1
+)
+insert into bot_sessions(key, data, expires_at)
+values ($1, $2::jsonb, now() + $3::bigint * interval '1 millisecond')
+on conflict (key) do update
+    set data       = excluded.data,
+        expires_at = excluded.expires_at`
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshal bot session: %w", err)
+	}
+
+	if _, err := p.db.ExecContext(ctx, query, key, string(data), ttl.Milliseconds()); err != nil {
+		return fmt.Errorf("set bot session: %w", err)
+	}
+
+	return nil
+}
+
+// Update блокирует запись key до конца транзакции, поэтому одновременные изменения
+// состояния одного пользователя с разных экземпляров не теряются.
+func (p *Postgres[T]) Update(ctx context.Context, key int64, fn func(*T), ttl time.Duration) error {
+	const (
+		// Пустая запись нужна, чтобы было что блокировать; она сразу считается истекшей.
+		ensureQuery = `insert into bot_sessions(key, data, expires_at) values ($1, 'null'::jsonb, now())
+on conflict (key) do nothing`
+		lockQuery = `select case when expires_at > now() then data::text else 'null' end
+from bot_sessions where key = $1 for update`
+		saveQuery = `update bot_sessions
+set data       = $2::jsonb,
+    expires_at = now() + $3::bigint * interval '1 millisecond'
+where key = $1`
+	)
+
+	tx, err := p.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin bot session update: %w", err)
+	}
+
+	//nolint:errcheck // rollback after commit is a no-op
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, ensureQuery, key); err != nil {
+		return fmt.Errorf("ensure bot session: %w", err)
+	}
+
+	var data string
+
+	if err := tx.GetContext(ctx, &data, lockQuery, key); err != nil {
+		return fmt.Errorf("lock bot session: %w", err)
+	}
+
+	var v T
+
+	if err := json.Unmarshal([]byte(data), &v); err != nil {
+		return fmt.Errorf("unmarshal bot session: %w", err)
+	}
+
+	fn(&v)
+
+	out, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshal bot session: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, saveQuery, key, string(out), ttl.Milliseconds()); err != nil {
+		return fmt.Errorf("update bot session: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit bot session update: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Postgres[T]) Delete(ctx context.Context, key int64) error {
+	const query = `delete from bot_sessions where key = $1`
+
+	if _, err := p.db.ExecContext(ctx, query, key); err != nil {
+		return fmt.Errorf("delete bot session: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,19 @@
+// Package state хранит состояние диалогов Telegram-бота: текущий шаг и данные,
+// которые пользователь ввел на предыдущих шагах.
+package state
+
+import (
+	"context"
+	"time"
+)
+
+// Store хранит состояние диалога пользователя key. Запись живет ttl с последнего Set
+// или Update, после чего Get ее не возвращает.
+type Store[T any] interface {
+	Get(ctx context.Context, key int64) (T, bool, error)
+	Set(ctx context.Context, key int64, v T, ttl time.Duration) error
+	// Update атомарно читает состояние, изменяет его fn и сохраняет. Если состояние
+	// прочитать не удалось, ничего не сохраняется.
+	Update(ctx context.Context, key int64, fn func(*T), ttl time.Duration) error
+	Delete(ctx context.Context, key int64) error
+}
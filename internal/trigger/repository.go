@@ -0,0 +1,77 @@
+package trigger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/jmoiron/sqlx"
+	"support_bot/internal/models"
+)
+
+type Repository struct {
+	db *sqlx.DB
+
+	log *slog.Logger
+}
+
+func NewRepository(db *sqlx.DB, log *slog.Logger) *Repository {
+	l := log.With(slog.Any("module", "trigger_repository"))
+
+	return &Repository{
+		db:  db,
+		log: l,
+	}
+}
+
+func (r *Repository) Exists(ctx context.Context, name string) (bool, error) {
+	const query = `select exists(select 1 from reports where name = $1 and active = true);`
+
+	var ok bool
+
+	if err := r.db.GetContext(ctx, &ok, query, name); err != nil {
+		return false, fmt.Errorf("check report exists: %w", err)
+	}
+
+	return ok, nil
+}
+
+// Params возвращает объявленные параметры отчета name.
+func (r *Repository) Params(ctx context.Context, name string) ([]models.ReportParam, error) {
+	const query = `select p.name, p.title, p.type, coalesce(array_to_json(p.options), '[]')::text as options
+from report_params p
+join reports r on r.id = p.report_id
+where r.name = $1
+order by p.position, p.id`
+
+	var rows []struct {
+		Name    string `db:"name"`
+		Title   string `db:"title"`
+		Type    string `db:"type"`
+		Options string `db:"options"`
+	}
+
+	if err := r.db.SelectContext(ctx, &rows, query, name); err != nil {
+		return nil, fmt.Errorf("load report params: %w", err)
+	}
+
+	params := make([]models.ReportParam, 0, len(rows))
+
+	for _, row := range rows {
+		var options []string
+
+		if err := json.Unmarshal([]byte(row.Options), &options); err != nil {
+			return nil, fmt.Errorf("param %s options: %w", row.Name, err)
+		}
+
+		params = append(params, models.ReportParam{
+			Name:    row.Name,
+			Title:   row.Title,
+			Type:    models.ParamType(row.Type),
+			Options: options,
+		})
+	}
+
+	return params, nil
+}
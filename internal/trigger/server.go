@@ -0,0 +1,122 @@
+package trigger
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const maxBodySize = 64 << 10
+
+// Server — HTTP API для запуска отчетов.
+//
+//	POST /api/v1/reports/{name}/trigger
+//	Authorization: Bearer <token>
+//	{"params": {"date": "2026-01-01"}}
+type Server struct {
+	srv   *http.Server
+	t     *Trigger
+	token string
+
+	log *slog.Logger
+}
+
+func NewServer(addr, token string, t *Trigger, log *slog.Logger) *Server {
+	l := log.With(slog.Any("module", "trigger_server"))
+
+	s := &Server{
+		t:     t,
+		token: token,
+		log:   l,
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("POST /api/v1/reports/{name}/trigger", s.auth(http.HandlerFunc(s.handleTrigger)))
+
+	s.srv = &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadTimeout:       15 * time.Second,
+		ReadHeaderTimeout: 5 * time.Second,
+		WriteTimeout:      15 * time.Second,
+	}
+
+	return s
+}
+
+func (s *Server) Start() {
+	go func() {
+		s.log.Info("api server started", slog.Any("addr", s.srv.Addr))
+
+		if err := s.srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.log.Error("api server error", slog.Any("error", err))
+		}
+	}()
+}
+
+func (s *Server) Stop(ctx context.Context) error {
+	return s.srv.Shutdown(ctx)
+}
+
+func (s *Server) auth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			writeJSON(w, http.StatusUnauthorized, response{Error: "unauthorized"})
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+type response struct {
+	Status string `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+func (s *Server) handleTrigger(w http.ResponseWriter, r *http.Request) {
+	req := Request{Report: r.PathValue("name")}
+
+	var body struct {
+		Params map[string]string `json:"params"`
+	}
+
+	err := json.NewDecoder(io.LimitReader(r.Body, maxBodySize)).Decode(&body)
+	if err != nil && !errors.Is(err, io.EOF) {
+		writeJSON(w, http.StatusBadRequest, response{Error: "invalid body: " + err.Error()})
+
+		return
+	}
+
+	req.Params = body.Params
+
+	err = s.t.Fire(r.Context(), req, SourceHTTP)
+
+	switch {
+	case err == nil:
+		writeJSON(w, http.StatusAccepted, response{Status: "accepted"})
+	case errors.Is(err, ErrUnknownReport):
+		writeJSON(w, http.StatusNotFound, response{Error: err.Error()})
+	case errors.Is(err, ErrEmptyReport), errors.Is(err, ErrInvalidParams):
+		writeJSON(w, http.StatusBadRequest, response{Error: err.Error()})
+	default:
+		s.log.ErrorContext(r.Context(), "unable to trigger report", slog.Any("error", err))
+		writeJSON(w, http.StatusInternalServerError, response{Error: "internal error"})
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, resp response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	//nolint:errcheck // client may be gone
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,127 @@
+package trigger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"support_bot/internal/models"
+)
+
+type fakeProducer struct {
+	name string
+	run  models.RunInfo
+}
+
+func (f *fakeProducer) Trigger(_ context.Context, name string, run models.RunInfo) error {
+	f.name = name
+	f.run = run
+
+	return nil
+}
+
+type fakeReports map[string]bool
+
+func (f fakeReports) Exists(_ context.Context, name string) (bool, error) {
+	return f[name], nil
+}
+
+func (f fakeReports) Params(context.Context, string) ([]models.ReportParam, error) {
+	return []models.ReportParam{{Name: "date", Type: models.ParamDate}}, nil
+}
+
+func TestServerTrigger(t *testing.T) {
+	t.Parallel()
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name   string
+		path   string
+		token  string
+		body   string
+		status int
+	}{
+		{
+			name:   "accepted with params",
+			path:   "/api/v1/reports/etl_done/trigger",
+			token:  "Bearer secret",
+			body:   `{"params":{"date":"2026-01-01"}}`,
+			status: http.StatusAccepted,
+		},
+		{
+			name:   "accepted without body",
+			path:   "/api/v1/reports/etl_done/trigger",
+			token:  "Bearer secret",
+			status: http.StatusAccepted,
+		},
+		{
+			name:   "wrong token",
+			path:   "/api/v1/reports/etl_done/trigger",
+			token:  "Bearer wrong",
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "unknown report",
+			path:   "/api/v1/reports/missing/trigger",
+			token:  "Bearer secret",
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "unknown param",
+			path:   "/api/v1/reports/etl_done/trigger",
+			token:  "Bearer secret",
+			body:   `{"params":{"date":"2026-01-01","region":"msk"}}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "invalid param",
+			path:   "/api/v1/reports/etl_done/trigger",
+			token:  "Bearer secret",
+			body:   `{"params":{"date":"01.01.2026"}}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "invalid body",
+			path:   "/api/v1/reports/etl_done/trigger",
+			token:  "Bearer secret",
+			body:   `{"params":`,
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := &fakeProducer{}
+			srv := NewServer("", "secret", New(p, fakeReports{"etl_done": true}, log), log)
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Authorization", tt.token)
+
+			rec := httptest.NewRecorder()
+			srv.srv.Handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+
+			if tt.status != http.StatusAccepted {
+				assert.Empty(t, p.name)
+
+				return
+			}
+
+			assert.Equal(t, "etl_done", p.name)
+			assert.Equal(t, SourceHTTP, p.run.Trigger)
+
+			if tt.body != "" {
+				assert.Equal(t, map[string]string{"date": "2026-01-01"}, p.run.Params)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+// Package trigger запускает отчеты по запросу внешних систем:
+// через HTTP API и через канал PostgreSQL NOTIFY report_trigger.
+package trigger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"support_bot/internal/models"
+)
+
+// Источники внешних запусков, попадают в RunInfo.Trigger.
+const (
+	SourceHTTP   = "http"
+	SourceNotify = "notify"
+)
+
+var (
+	ErrUnknownReport = errors.New("report not found or inactive")
+	ErrEmptyReport   = errors.New("report name is empty")
+	ErrInvalidParams = errors.New("invalid report params")
+)
+
+// Producer передает событие запуска отчета в пайплайн.
+type Producer interface {
+	Trigger(ctx context.Context, name string, run models.RunInfo) error
+}
+
+// ReportChecker проверяет, что отчет существует и активен, и возвращает его параметры.
+type ReportChecker interface {
+	Exists(ctx context.Context, name string) (bool, error)
+	Params(ctx context.Context, name string) ([]models.ReportParam, error)
+}
+
+// Request — запрос на запуск отчета.
+type Request struct {
+	Report string            `json:"report"`
+	Params map[string]string `json:"params"`
+}
+
+type Trigger struct {
+	producer Producer
+	reports  ReportChecker
+
+	log *slog.Logger
+}
+
+func New(producer Producer, reports ReportChecker, log *slog.Logger) *Trigger {
+	l := log.With(slog.Any("module", "trigger"))
+
+	return &Trigger{
+		producer: producer,
+		reports:  reports,
+		log:      l,
+	}
+}
+
+// Fire проверяет отчет и отправляет событие его запуска.
+func (t *Trigger) Fire(ctx context.Context, req Request, source string) error {
+	if req.Report == "" {
+		return ErrEmptyReport
+	}
+
+	ok, err := t.reports.Exists(ctx, req.Report)
+	if err != nil {
+		return fmt.Errorf("check report: %w", err)
+	}
+
+	if !ok {
+		return ErrUnknownReport
+	}
+
+	if err := t.validateParams(ctx, req); err != nil {
+		return err
+	}
+
+	run := models.RunInfo{
+		ScheduledAt: time.Now(),
+		Trigger:     source,
+		Params:      req.Params,
+	}
+
+	if err := t.producer.Trigger(ctx, req.Report, run); err != nil {
+		return fmt.Errorf("produce event: %w", err)
+	}
+
+	t.log.InfoContext(
+		ctx,
+		"report triggered",
+		slog.Any("report", req.Report),
+		slog.Any("source", source),
+		slog.Any("params", req.Params),
+	)
+
+	return nil
+}
+
+// validateParams проверяет значения параметров так же, как при запуске из бота.
+// Запрос без параметров выполняется как запуск по расписанию.
+func (t *Trigger) validateParams(ctx context.Context, req Request) error {
+	if len(req.Params) == 0 {
+		return nil
+	}
+
+	params, err := t.reports.Params(ctx, req.Report)
+	if err != nil {
+		return fmt.Errorf("load report params: %w", err)
+	}
+
+	for name := range req.Params {
+		if !slices.ContainsFunc(params, func(p models.ReportParam) bool { return p.Name == name }) {
+			return fmt.Errorf("%w: unknown param %s", ErrInvalidParams, name)
+		}
+	}
+
+	if err := models.ValidateParams(params, req.Params); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidParams, err)
+	}
+
+	return nil
+}
+
+// Listen запускает отчеты по уведомлениям из payloads до отмены ctx.
+// Уведомление приходит всем экземплярам, поэтому вызывается только на лидере.
+func (t *Trigger) Listen(ctx context.Context, payloads <-chan string) {
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case payload := <-payloads:
+				// Пустой payload шлет Listener после переподключения.
+				if payload == "" {
+					continue
+				}
+
+				var req Request
+
+				if err := json.Unmarshal([]byte(payload), &req); err != nil {
+					t.log.WarnContext(
+						ctx,
+						"unable to parse trigger",
+						slog.Any("payload", payload),
+						slog.Any("error", err),
+					)
+
+					continue
+				}
+
+				if err := t.Fire(ctx, req, SourceNotify); err != nil {
+					t.log.WarnContext(
+						ctx,
+						"unable to trigger report",
+						slog.Any("report", req.Report),
+						slog.Any("error", err),
+					)
+				}
+			}
+		}
+	}()
+}
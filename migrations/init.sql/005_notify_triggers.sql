@@ -0,0 +1,37 @@
+-- Уведомления об изменении расписаний.
+-- Sheduler подписывается на канал crons_changed и перечитывает только изменившиеся задачи.
+create or replace function notify_change() returns trigger as
+$$
+declare
+    rec json;
+begin
+    if TG_OP = 'DELETE' then
+        rec := row_to_json(OLD);
+    else
+        rec := row_to_json(NEW);
+    end if;
+
+    perform pg_notify(
+            TG_ARGV[0],
+            json_build_object(
+                    'table', TG_TABLE_NAME,
+                    'action', lower(TG_OP),
+                    'name', rec ->> 'name'
+            )::text
+            );
+
+    return null;
+end;
+$$ language plpgsql;
+
+create trigger crons_notify_change
+    after insert or update or delete
+    on crons
+    for each row
+execute procedure notify_change('crons_changed');
+
+create trigger report_crons_notify_change
+    after insert or update or delete
+    on report_crons
+    for each row
+execute procedure notify_change('crons_changed');
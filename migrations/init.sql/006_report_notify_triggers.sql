@@ -0,0 +1,124 @@
+-- Уведомления об изменении отчетов для сброса кэшей EventCreator и Orchestrator.
+-- name — имя затронутого отчета, cron — имя затронутого расписания.
+-- Если ни то ни другое определить нельзя (общие таблицы), кэши сбрасываются целиком.
+create or replace function notify_report_change() returns trigger as
+$$
+declare
+    rec    json;
+    report text;
+    cron   text;
+begin
+    if TG_OP = 'DELETE' then
+        rec := row_to_json(OLD);
+    else
+        rec := row_to_json(NEW);
+    end if;
+
+    if TG_TABLE_NAME = 'reports' then
+        report := rec ->> 'name';
+
+        if TG_OP = 'UPDATE' and OLD.name <> NEW.name then
+            report := null;
+        end if;
+    elsif rec ->> 'report_id' is not null then
+        select r.name into report from reports r where r.id = (rec ->> 'report_id')::int;
+    end if;
+
+    if TG_TABLE_NAME = 'crons' then
+        cron := rec ->> 'name';
+    elsif rec ->> 'cron_id' is not null then
+        select c.name into cron from crons c where c.id = (rec ->> 'cron_id')::int;
+    end if;
+
+    perform pg_notify(
+            'reports_changed',
+            json_build_object(
+                    'table', TG_TABLE_NAME,
+                    'action', lower(TG_OP),
+                    'name', report,
+                    'cron', cron
+            )::text
+            );
+
+    return null;
+end;
+$$ language plpgsql;
+
+create trigger reports_notify_report_change
+    after insert or update or delete
+    on reports
+    for each row
+execute procedure notify_report_change();
+
+create trigger crons_notify_report_change
+    after insert or update or delete
+    on crons
+    for each row
+execute procedure notify_report_change();
+
+create trigger report_crons_notify_report_change
+    after insert or update or delete
+    on report_crons
+    for each row
+execute procedure notify_report_change();
+
+create trigger reports_recipients_notify_report_change
+    after insert or update or delete
+    on reports_recipients
+    for each row
+execute procedure notify_report_change();
+
+create trigger reports_export_notify_report_change
+    after insert or update or delete
+    on reports_export
+    for each row
+execute procedure notify_report_change();
+
+create trigger report_templates_notify_report_change
+    after insert or update or delete
+    on report_templates
+    for each row
+execute procedure notify_report_change();
+
+create trigger report_queries_notify_report_change
+    after insert or update or delete
+    on report_queries
+    for each row
+execute procedure notify_report_change();
+
+-- Общие таблицы: затронутый отчет неизвестен, кэш сбрасывается целиком.
+create trigger recipients_notify_report_change
+    after insert or update or delete
+    on recipients
+    for each row
+execute procedure notify_report_change();
+
+create trigger chats_notify_report_change
+    after insert or update or delete
+    on chats
+    for each row
+execute procedure notify_report_change();
+
+create trigger email_templates_notify_report_change
+    after insert or update or delete
+    on email_templates
+    for each row
+execute procedure notify_report_change();
+
+create trigger templates_notify_report_change
+    after insert or update or delete
+    on templates
+    for each row
+execute procedure notify_report_change();
+
+create trigger queries_notify_report_change
+    after insert or update or delete
+    on queries
+    for each row
+execute procedure notify_report_change();
+
+create trigger evaluate_notify_report_change
+    after insert or update or delete
+    on evaluate
+    for each row
+execute procedure notify_report_change();
@@ -0,0 +1,7 @@
+-- Время последнего срабатывания расписаний.
+-- По нему после простоя определяются пропущенные запуски.
+create table cron_runs
+(
+    cron_name    text primary key,
+    last_fire_at timestamptz not null
+);
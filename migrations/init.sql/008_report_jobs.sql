@@ -0,0 +1,21 @@
+-- Очередь задач на генерацию отчетов.
+-- Orchestrator добавляет задачи, Generator забирает их через for update skip locked.
+-- Задача в состоянии running, у которой истек visible_at, снова становится доступной:
+-- так обеспечивается обработка at-least-once при падении экземпляра.
+create table report_jobs
+(
+    id           bigserial primary key,
+    report_name  text        not null,
+    payload      jsonb       not null,
+    state        text        not null default 'queued', -- 'queued', 'running', 'done', 'failed'
+    attempts     int         not null default 0,
+    max_attempts int         not null default 3,
+    visible_at   timestamptz not null default now(),
+    locked_by    text,
+    error        text,
+    created_at   timestamptz not null default now(),
+    started_at   timestamptz,
+    finished_at  timestamptz
+);
+
+create index report_jobs_pending_idx on report_jobs (visible_at, id) where state in ('queued', 'running');
@@ -0,0 +1,47 @@
+-- Зависимости между отчетами: после успешной отправки report_id запускается dependent_id.
+-- pass_data — передать дочернему отчету данные, собранные родителем (доступны в шаблонах как .run.ParentData).
+create table report_dependencies
+(
+    report_id    int     not null,
+    dependent_id int     not null,
+    pass_data    boolean not null default false,
+    primary key (report_id, dependent_id),
+    constraint fk_report_dependencies_report foreign key (report_id) references reports (id) on delete cascade,
+    constraint fk_report_dependencies_dependent foreign key (dependent_id) references reports (id) on delete cascade,
+    constraint report_dependencies_no_self check (report_id <> dependent_id)
+);
+
+-- Запрещает зависимости, образующие цикл.
+-- Блокировка сериализует изменения графа, чтобы две параллельные вставки не замкнули цикл.
+create or replace function check_report_dependency_cycle() returns trigger as
+$$
+begin
+    perform pg_advisory_xact_lock(hashtext('report_dependencies'));
+
+    if exists (with recursive chain(id) as (select NEW.dependent_id
+                                            union
+                                            select d.dependent_id
+                                            from report_dependencies d
+                                                     join chain c on d.report_id = c.id)
+               select 1
+               from chain
+               where id = NEW.report_id) then
+        raise exception 'report dependency cycle: report % already depends on report %', NEW.report_id, NEW.dependent_id;
+    end if;
+
+    return NEW;
+end;
+$$ language plpgsql;
+
+create trigger report_dependencies_check_cycle
+    before insert or update
+    on report_dependencies
+    for each row
+execute procedure check_report_dependency_cycle();
+
+-- Зависимые отчеты хранятся в кэше Orchestrator вместе с родителем (report_id).
+create trigger report_dependencies_notify_report_change
+    after insert or update or delete
+    on report_dependencies
+    for each row
+execute procedure notify_report_change();
@@ -0,0 +1,12 @@
+-- Политика одновременных запусков отчета: skip, queue или cancel.
+-- null — политика по умолчанию из конфигурации (concurrency.policy).
+alter table reports
+    add column concurrency_policy text check (concurrency_policy in ('skip', 'queue', 'cancel'));
+
+-- lock_key — ключ блокировки запуска (отчет и набор получателей).
+-- Задачи, не выполненные из-за политики, остаются в истории в состояниях
+-- 'skipped' (пропущена) и 'cancelled' (отменена более новым запуском).
+alter table report_jobs
+    add column lock_key text;
+
+create index report_jobs_lock_key_idx on report_jobs (lock_key) where state = 'running';
@@ -0,0 +1,11 @@
+-- Время на генерацию отчета в секундах. null — pipeline.report_timeout из конфигурации.
+alter table reports
+    add column timeout_sec int check (timeout_sec > 0);
+
+-- heavy — отчет с pdf/png экспортом, обрабатывается отдельным пулом обработчиков.
+alter table report_jobs
+    add column heavy boolean not null default false;
+
+drop index report_jobs_pending_idx;
+
+create index report_jobs_pending_idx on report_jobs (heavy, visible_at, id) where state in ('queued', 'running');
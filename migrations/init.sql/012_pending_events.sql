@@ -0,0 +1,10 @@
+-- События, не обработанные до остановки экземпляра.
+-- При старте лидер забирает их и отправляет обратно в пайплайн.
+-- stage — канал, в который вернуть событие: schedule, report, special, delete.
+create table pending_events
+(
+    id         bigserial primary key,
+    stage      text        not null,
+    payload    jsonb       not null,
+    created_at timestamptz not null default now()
+);
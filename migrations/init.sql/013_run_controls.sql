@@ -0,0 +1,14 @@
+-- Ручное управление запусками расписаний и отчетов из админ-меню бота.
+-- target — 'cron' или 'report', name — имя расписания или отчета.
+-- Запуски до paused_until пропускаются. skip_next пропускает ближайший
+-- запуск после паузы и сбрасывается им.
+create table run_controls
+(
+    target       text        not null check (target in ('cron', 'report')),
+    name         text        not null,
+    paused_until timestamptz,
+    skip_next    bool        not null default false,
+    updated_by   text,
+    updated_at   timestamptz not null default now(),
+    primary key (target, name)
+);
@@ -0,0 +1,4 @@
+-- Отчеты, на которые пользователи и администраторы групп подписывают свои чаты из бота.
+-- Подписка — обычный Telegram-получатель в recipients, привязанный через reports_recipients.
+alter table reports
+    add column subscribable bool not null default false;
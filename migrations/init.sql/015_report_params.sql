@@ -0,0 +1,23 @@
+-- Параметры отчета, которые бот запрашивает перед ручным запуском.
+-- name — имя переменной (template tag) в запросах карточек Metabase и ключ в .run.Params шаблонов.
+-- type: date — одна дата, date_range — период (в Metabase передается как фильтр по полю), enum — значение из options.
+create table report_params
+(
+    id        serial primary key,
+    report_id int  not null,
+    name      text not null,
+    title     text not null,
+    type      text not null,
+    options   text[],
+    position  int  not null default 0,
+    unique (report_id, name),
+    constraint fk_report_params_report foreign key (report_id) references reports (id) on delete cascade,
+    constraint report_params_type check (type in ('date', 'date_range', 'enum')),
+    constraint report_params_enum_options check (type <> 'enum' or coalesce(array_length(options, 1), 0) > 0)
+);
+
+create trigger report_params_notify_report_change
+    after insert or update or delete
+    on report_params
+    for each row
+execute procedure notify_report_change();
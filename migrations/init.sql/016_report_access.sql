@@ -0,0 +1,27 @@
+-- Доступ пользователей к ручному запуску отчетов из бота.
+-- Отчет без выдач в report_group_grants доступен всем пользователям (access_from_lk = true),
+-- отчет с выдачами — только участникам групп, которым он выдан. Администраторы видят все отчеты.
+create table user_groups
+(
+    id    serial primary key,
+    name  text unique not null,
+    title text
+);
+
+create table user_group_members
+(
+    group_id int not null,
+    user_id  int not null,
+    primary key (group_id, user_id),
+    constraint fk_user_group_members_group foreign key (group_id) references user_groups (id) on delete cascade,
+    constraint fk_user_group_members_user foreign key (user_id) references users (id) on delete cascade
+);
+
+create table report_group_grants
+(
+    report_id int not null,
+    group_id  int not null,
+    primary key (report_id, group_id),
+    constraint fk_report_group_grants_report foreign key (report_id) references reports (id) on delete cascade,
+    constraint fk_report_group_grants_group foreign key (group_id) references user_groups (id) on delete cascade
+);
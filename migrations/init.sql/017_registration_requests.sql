@@ -0,0 +1,19 @@
+-- Пользователь, добавленный администратором по username, пока не написал боту.
+-- Telegram ID такой записи случайный и заменяется настоящим при первом обращении к боту.
+alter table users
+    add column is_placeholder bool not null default false;
+
+-- Заявки на регистрацию: /register создает заявку, администратор одобряет или отклоняет ее из бота.
+create table registration_requests
+(
+    id          serial primary key,
+    telegram_id bigint unique not null,
+    username    varchar(255),
+    first_name  varchar(255),
+    last_name   varchar(255),
+    status      text          not null default 'pending',
+    decided_by  text,
+    created_at  timestamptz   not null default now(),
+    decided_at  timestamptz,
+    constraint registration_requests_status check (status in ('pending', 'approved', 'denied'))
+);
@@ -0,0 +1,27 @@
+-- Приглашения: администратор создает одноразовую ссылку t.me/<бот>?start=<token>,
+-- по которой пользователь регистрируется с заданной ролью и группами без заявки.
+-- Записи не удаляются, поэтому таблица хранит историю выдачи, использования и отзыва.
+create table invites
+(
+    id            serial primary key,
+    token         text unique not null,
+    role          text        not null,
+    created_by    text        not null,
+    created_at    timestamptz not null default now(),
+    expires_at    timestamptz not null,
+    used_by       bigint,
+    used_username varchar(255),
+    used_at       timestamptz,
+    revoked_by    text,
+    revoked_at    timestamptz,
+    constraint invites_role check (role in ('admin', 'user'))
+);
+
+create table invite_groups
+(
+    invite_id int not null,
+    group_id  int not null,
+    primary key (invite_id, group_id),
+    constraint fk_invite_groups_invite foreign key (invite_id) references invites (id) on delete cascade,
+    constraint fk_invite_groups_group foreign key (group_id) references user_groups (id) on delete cascade
+);
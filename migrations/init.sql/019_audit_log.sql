@@ -0,0 +1,14 @@
+-- Журнал действий, выполненных из Telegram-бота: кто, что и над чем сделал,
+-- состояние объекта до и после изменения.
+create table audit_log
+(
+    id         bigserial primary key,
+    actor      text        not null,
+    action     text        not null,
+    target     text        not null,
+    before     jsonb,
+    after      jsonb,
+    created_at timestamptz not null default now()
+);
+
+create index audit_log_created_at on audit_log (created_at desc);
@@ -0,0 +1,11 @@
+-- Состояние диалогов Telegram-бота: шаг и введенные данные пользователя key.
+-- Общая для всех экземпляров бота и переживает перезапуск. Истекшие записи
+-- не читаются и удаляются при следующей записи.
+create table bot_sessions
+(
+    key        bigint primary key,
+    data       jsonb       not null,
+    expires_at timestamptz not null
+);
+
+create index bot_sessions_expires_at on bot_sessions (expires_at);
@@ -0,0 +1,7 @@
+-- Оформление отчета в Telegram.
+-- tg_text_as_caption — текст отчета уходит подписью к первому изображению или документу,
+-- а не отдельным сообщением. Если текст длиннее подписи (1024 символа), он отправляется отдельно.
+-- tg_group_documents — документы отчета отправляются одним альбомом, а не по одному.
+alter table reports
+    add column tg_text_as_caption boolean not null default false,
+    add column tg_group_documents boolean not null default false;
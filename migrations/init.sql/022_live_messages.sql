@@ -0,0 +1,11 @@
+-- edit_in_place — отчет обновляет ранее отправленное получателю сообщение вместо отправки нового.
+-- pin — закрепить это сообщение в чате.
+alter table recipients
+    add column edit_in_place bool not null default false,
+    add column pin           bool not null default false;
+
+-- live — сообщение, которое отчет обновляет на месте. Такие сообщения не удаляются в конце дня.
+alter table sent_messages
+    add column live bool not null default false;
+
+create index sent_messages_live_idx on sent_messages (report_name, chat_id, thread_id) where live and not deleted;
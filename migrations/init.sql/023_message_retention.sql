@@ -0,0 +1,39 @@
+-- Политика хранения сообщений, отправленных получателю в Telegram:
+-- never — не удалять, after_hours — удалять через retention_hours часов после отправки,
+-- keep_last — оставлять retention_keep последних отправок отчета в чате, остальные удалять.
+alter table recipients
+    add column retention_policy text not null default 'never',
+    add column retention_hours  int,
+    add column retention_keep   int,
+    add constraint recipients_retention_check check (
+        retention_policy = 'never'
+        or (retention_policy = 'after_hours' and retention_hours > 0)
+        or (retention_policy = 'keep_last' and retention_keep > 0)
+    );
+
+-- Удаление в конце дня оставляло последнее сообщение отчета в чате.
+update recipients
+set retention_policy = 'keep_last',
+    retention_keep   = 1
+where need_delete_after_end_of_day;
+
+alter table recipients
+    drop column need_delete_after_end_of_day;
+
+-- delete_at — когда удалить сообщение (after_hours), keep_last — сколько последних отправок
+-- оставить (keep_last). batch — номер отправки: сообщения одной отправки считаются вместе.
+create sequence sent_messages_batch_seq;
+
+alter table sent_messages
+    add column delete_at timestamptz,
+    add column keep_last int,
+    add column batch     bigint;
+
+update sent_messages
+set batch     = id,
+    keep_last = 1
+where not deleted and not live;
+
+select setval('sent_messages_batch_seq', coalesce((select max(id) from sent_messages), 0) + 1, false);
+
+create index sent_messages_delete_at_idx on sent_messages (delete_at) where not deleted and delete_at is not null;